@@ -0,0 +1,16 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !windows
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!windows
+
+package main
+
+import "os"
+
+// lockFile doesn't lock the file, because the platform doesn't support file locks
+func lockFile(_ *os.File) error {
+	return nil
+}
+
+// unlockFile doesn't unlock the file, because the platform doesn't support file locks
+func unlockFile(_ *os.File) error {
+	return nil
+}
@@ -0,0 +1,89 @@
+// Command otp generates and validates one time passwords, otpauth URIs and recovery codes
+//
+// Without a subcommand, the flags and the output mirror oathtool:
+//
+//	otp [--hotp | --totp[=MODE]] [-b] [-c COUNTER] [-d DIGITS] [-w WINDOW] [-s STEP] [-N NOW] [-v] KEY [OTP]
+//
+// The subcommands are:
+//
+//	otp uri      generates an otpauth URI and its QR code
+//	otp parse    parses an otpauth URI
+//	otp recovery generates recovery codes
+//	otp vault    manages keys in a local vault encrypted with a passphrase
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+const name = "otp"
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run runs the command with the arguments and returns the exit status
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	var err error
+	if len(args) > 0 {
+		switch args[0] {
+		case "uri":
+			err = runURI(args[1:], stdout, stderr)
+		case "parse":
+			err = runParse(args[1:], stdout, stderr)
+		case "recovery":
+			err = runRecovery(args[1:], stdout, stderr)
+		case "vault":
+			err = runVault(args[1:], stdin, stdout, stderr)
+		default:
+			err = runOath(args, stdout, stderr)
+		}
+	} else {
+		err = runOath(args, stdout, stderr)
+	}
+
+	if err == nil || err == flag.ErrHelp {
+		return 0
+	}
+
+	fmt.Fprintf(stderr, "%s: %s\n", name, err)
+	return 1
+}
+
+// parse parses the flags and the positional arguments in any order like getopt
+// The arguments after `--` are positional
+func parse(fs *flag.FlagSet, args []string) ([]string, error) {
+	var rest []string
+	for i, a := range args {
+		if a == "--" {
+			args, rest = args[:i], args[i+1:]
+			break
+		}
+	}
+
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return append(positional, rest...), nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+func newFlagSet(name string, stderr io.Writer, usage string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: %s\n\nOptions:\n", usage)
+		fs.PrintDefaults()
+	}
+	return fs
+}
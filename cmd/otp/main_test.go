@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// rfcKey is the hex encoded secret of the test vectors of RFC 4226 and RFC 6238
+const rfcKey = "3132333435363738393031323334353637383930"
+
+func runCommand(t *testing.T, args ...string) (string, string, int) {
+	t.Helper()
+
+	return runCommandWithInput(t, "", args...)
+}
+
+func runCommandWithInput(t *testing.T, stdin string, args ...string) (string, string, int) {
+	t.Helper()
+
+	var stdout, stderr bytes.Buffer
+	code := run(args, strings.NewReader(stdin), &stdout, &stderr)
+	return stdout.String(), stderr.String(), code
+}
+
+func TestRun_Oath(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{name: "hotp", args: []string{rfcKey}, want: "755224\n"},
+		{name: "hotp with counter", args: []string{"-c", "7", rfcKey}, want: "162583\n"},
+		{name: "hotp with window", args: []string{"--hotp", "-w", "2", rfcKey}, want: "755224\n287082\n359152\n"},
+		{name: "hotp with base32", args: []string{"--base32", "gezd gnbv gy3t qojq gezd gnbv gy3t qojq", "--counter=1"}, want: "287082\n"},
+		{name: "totp", args: []string{"--totp", "-d", "8", "--now", "1970-01-01 00:00:59 UTC", rfcKey}, want: "94287082\n"},
+		{name: "totp at unix time", args: []string{"--totp", "-d", "8", "-N", "@1111111109", rfcKey}, want: "07081804\n"},
+		{name: "totp with sha256", args: []string{"--totp=sha256", "-d", "8", "-N", "@59", "3132333435363738393031323334353637383930313233343536373839303132"}, want: "46119246\n"},
+		{name: "totp with step", args: []string{"--totp", "-s", "1m", "-d", "8", "-N", "@118", rfcKey}, want: "94287082\n"},
+	}
+
+	for _, tt := range tests {
+		got, stderr, code := runCommand(t, tt.args...)
+		if code != 0 {
+			t.Errorf("%s: run(%v)=%d; want 0, stderr %s", tt.name, tt.args, code, stderr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: run(%v) prints %q; want %q", tt.name, tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestRun_OathValidate(t *testing.T) {
+	got, _, code := runCommand(t, "-w", "10", rfcKey, "162583")
+	if code != 0 || got != "7\n" {
+		t.Errorf("run() prints %q, %d; want 7, 0", got, code)
+	}
+
+	got, _, code = runCommand(t, "--totp", "-w", "1", "-N", "@89", rfcKey, "287082")
+	if code != 0 || got != "-1\n" {
+		t.Errorf("run() prints %q, %d; want -1, 0", got, code)
+	}
+
+	_, stderr, code := runCommand(t, "-w", "5", rfcKey, "162583")
+	if code != 1 || stderr != "otp: password \"162583\" not found in range 0 .. 5\n" {
+		t.Errorf("run() prints %q, %d; want not found, 1", stderr, code)
+	}
+}
+
+func TestRun_OathInvalid(t *testing.T) {
+	tests := [][]string{
+		{},
+		{"zz"},
+		{"-d", "7", rfcKey},
+		{"--totp=md4", rfcKey},
+		{"--hotp", "--totp", rfcKey},
+		{"--totp", "-N", "yesterday", rfcKey},
+	}
+
+	for _, args := range tests {
+		if _, _, code := runCommand(t, args...); code != 1 {
+			t.Errorf("run(%v)=%d; want 1", args, code)
+		}
+	}
+}
+
+func TestRun_URI(t *testing.T) {
+	qr := filepath.Join(t.TempDir(), "qr.png")
+	got, stderr, code := runCommand(t, "uri", "--issuer", "butter", "--account", "butter@example.com", "--secret", "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ", "--qr", qr)
+	if code != 0 {
+		t.Fatalf("run() = %d; want 0, stderr %s", code, stderr)
+	}
+	want := "otpauth://totp/butter:butter@example.com?algorithm=SHA1&digits=6&issuer=butter&period=30&secret=GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ\n"
+	if got != want {
+		t.Errorf("run() prints %q; want %q", got, want)
+	}
+
+	b, err := os.ReadFile(qr)
+	if err != nil || !bytes.HasPrefix(b, []byte("\x89PNG")) {
+		t.Errorf("QR code isn't written as PNG, %#v", err)
+	}
+
+	got, _, code = runCommand(t, "uri", "--issuer", "butter", "--account", "butter@example.com", "--hotp", "--counter", "3")
+	if code != 0 || !strings.HasPrefix(got, "otpauth://hotp/butter:butter@example.com?algorithm=SHA1&counter=3&") {
+		t.Errorf("run() prints %q, %d; want hotp URI", got, code)
+	}
+}
+
+func TestRun_Parse(t *testing.T) {
+	got, _, code := runCommand(t, "parse", "otpauth://hotp/butter:butter@example.com?secret=GEZDGNBVGY3TQOJQ&counter=3&digits=8")
+	want := `type:      hotp
+issuer:    butter
+account:   butter@example.com
+secret:    GEZDGNBVGY3TQOJQ
+algorithm: SHA1
+digits:    8
+counter:   3
+`
+	if code != 0 || got != want {
+		t.Errorf("run() prints %q, %d; want %q, 0", got, code, want)
+	}
+
+	if _, _, code := runCommand(t, "parse", "https://example.com"); code != 1 {
+		t.Errorf("run()=%d; want 1", code)
+	}
+}
+
+func TestRun_Recovery(t *testing.T) {
+	got, stderr, code := runCommand(t, "recovery", "--count", "3", "--alphabet", "crockford", "--length", "8", "--group", "4", "--prefix", "ACME")
+	if code != 0 {
+		t.Fatalf("run()=%d; want 0, stderr %s", code, stderr)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(got, "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("run() prints %d codes; want 3", len(lines))
+	}
+	for _, l := range lines {
+		if len(l) != len("ACME-XXXX-XXXX") || !strings.HasPrefix(l, "ACME-") {
+			t.Errorf("code %s isn't formatted as ACME-XXXX-XXXX", l)
+		}
+	}
+
+	if _, _, code := runCommand(t, "recovery", "--alphabet", "emoji"); code != 1 {
+		t.Errorf("run()=%d; want 1", code)
+	}
+}
@@ -0,0 +1,250 @@
+package main
+
+import (
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/butterv/one-time-password/hotp"
+	"github.com/butterv/one-time-password/otpauth"
+)
+
+const oathUsage = "otp [--hotp | --totp[=MODE]] [-b] [-c COUNTER] [-d DIGITS] [-w WINDOW] [-s STEP] [-N NOW] [-v] KEY [OTP]"
+
+// timeLayouts is the layouts of --now
+var timeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05 MST",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// totpFlag is --totp that takes an optional hash algorithm like `--totp=sha256`
+type totpFlag struct {
+	enabled   bool
+	algorithm otpauth.Algorithm
+}
+
+func (f *totpFlag) String() string {
+	if f == nil || !f.enabled {
+		return ""
+	}
+
+	return strings.ToLower(f.algorithm.String())
+}
+
+func (f *totpFlag) Set(s string) error {
+	switch strings.ToLower(s) {
+	case "true", "sha1":
+		f.algorithm = otpauth.AlgorithmSHA1
+	case "sha256":
+		f.algorithm = otpauth.AlgorithmSHA256
+	case "sha512":
+		f.algorithm = otpauth.AlgorithmSHA512
+	case "false":
+		f.enabled = false
+		return nil
+	default:
+		return fmt.Errorf("invalid mode %q. please pass sha1, sha256 or sha512", s)
+	}
+
+	f.enabled = true
+	return nil
+}
+
+func (f *totpFlag) IsBoolFlag() bool {
+	return true
+}
+
+// runOath generates or validates a one time password like oathtool
+func runOath(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet(name, stderr, oathUsage)
+
+	var (
+		useHOTP, useBase32, verbose bool
+		useTOTP                     totpFlag
+		counter                     uint64
+		digits                      int
+		window                      uint
+		step, now                   string
+	)
+	fs.BoolVar(&useHOTP, "hotp", false, "use event-based HOTP mode (default)")
+	fs.Var(&useTOTP, "totp", "use time-variant TOTP mode, MODE is sha1, sha256 or sha512")
+	fs.BoolVar(&useBase32, "b", false, "use base32 encoding of KEY instead of hex")
+	fs.BoolVar(&useBase32, "base32", false, "use base32 encoding of KEY instead of hex")
+	fs.Uint64Var(&counter, "c", 0, "HOTP counter value")
+	fs.Uint64Var(&counter, "counter", 0, "HOTP counter value")
+	fs.IntVar(&digits, "d", int(otpauth.DigitsSix), "number of digits in one-time password, 6 or 8")
+	fs.IntVar(&digits, "digits", int(otpauth.DigitsSix), "number of digits in one-time password, 6 or 8")
+	fs.UintVar(&window, "w", 0, "window of counter values to test when validating OTPs")
+	fs.UintVar(&window, "window", 0, "window of counter values to test when validating OTPs")
+	fs.StringVar(&step, "s", "30s", "time-step duration of TOTP")
+	fs.StringVar(&step, "time-step-size", "30s", "time-step duration of TOTP")
+	fs.StringVar(&now, "N", "", "use this time as current time for TOTP")
+	fs.StringVar(&now, "now", "", "use this time as current time for TOTP")
+	fs.BoolVar(&verbose, "v", false, "explain what is being done")
+	fs.BoolVar(&verbose, "verbose", false, "explain what is being done")
+
+	positional, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) < 1 || len(positional) > 2 {
+		fs.Usage()
+		return errors.New("please pass KEY and optional OTP")
+	}
+	if useHOTP && useTOTP.enabled {
+		return errors.New("--hotp and --totp are mutually exclusive")
+	}
+
+	key, err := decodeKey(positional[0], useBase32)
+	if err != nil {
+		return err
+	}
+	opt := hotp.NewOption()
+	if err := opt.SetDigits(otpauth.Digits(digits)); err != nil {
+		return err
+	}
+
+	t := time.Now()
+	if now != "" {
+		t, err = parseTime(now)
+		if err != nil {
+			return err
+		}
+	}
+	stepSize, err := parseStep(step)
+	if err != nil {
+		return err
+	}
+
+	if useTOTP.enabled {
+		if err := opt.SetAlgorithm(useTOTP.algorithm); err != nil {
+			return err
+		}
+		counter = uint64(t.Unix() / int64(stepSize/time.Second))
+	}
+	secret := base32.StdEncoding.EncodeToString(key)
+
+	if verbose {
+		fmt.Fprintf(stdout, "Hex secret: %s\n", hex.EncodeToString(key))
+		fmt.Fprintf(stdout, "Base32 secret: %s\n", strings.TrimRight(secret, "="))
+		fmt.Fprintf(stdout, "Digits: %d\n", digits)
+		fmt.Fprintf(stdout, "Window size: %d\n", window)
+		if useTOTP.enabled {
+			fmt.Fprintf(stdout, "TOTP mode: %s\n", useTOTP.algorithm)
+			fmt.Fprintf(stdout, "Step size (seconds): %d\n", stepSize/time.Second)
+			fmt.Fprintf(stdout, "Current time: %s (%d)\n", t.UTC().Format("2006-01-02 15:04:05 MST"), t.Unix())
+		}
+		fmt.Fprintf(stdout, "Counter: 0x%X (%d)\n\n", counter, counter)
+	}
+
+	if len(positional) == 2 {
+		return validate(stdout, positional[1], secret, counter, window, useTOTP.enabled, opt)
+	}
+
+	for i := uint64(0); i <= uint64(window); i++ {
+		p, err := hotp.GeneratePasscodeWithOption(secret, counter+i, opt)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(stdout, p)
+	}
+
+	return nil
+}
+
+// validate prints the offset of the counter that the passcode matches
+// The counters after the counter are searched for HOTP, and the counters around the counter are searched for TOTP
+func validate(stdout io.Writer, passcode, secret string, counter uint64, window uint, totp bool, opt *hotp.Option) error {
+	offsets := []int64{0}
+	for i := int64(1); i <= int64(window); i++ {
+		if totp {
+			offsets = append(offsets, -i)
+		}
+		offsets = append(offsets, i)
+	}
+
+	for _, o := range offsets {
+		c := int64(counter) + o
+		if c < 0 {
+			continue
+		}
+		ok, err := hotp.ValidateWithOption(passcode, secret, uint64(c), opt)
+		if err == otpauth.ErrInvalidDigitsLength {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if ok {
+			fmt.Fprintln(stdout, o)
+			return nil
+		}
+	}
+
+	first := int64(counter)
+	if totp {
+		first -= int64(window)
+	}
+	return fmt.Errorf("password \"%s\" not found in range %d .. %d", passcode, first, int64(counter)+int64(window))
+}
+
+// decodeKey decodes the hex or base32 encoded key
+// The base32 encoded key is accepted in lower case, with spaces or without padding
+func decodeKey(key string, useBase32 bool) ([]byte, error) {
+	if !useBase32 {
+		b, err := hex.DecodeString(key)
+		if err != nil {
+			return nil, errors.New("hex decoding of secret key failed")
+		}
+		return b, nil
+	}
+
+	s := strings.TrimRight(strings.ToUpper(strings.Join(strings.Fields(key), "")), "=")
+	b, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(s)
+	if err != nil {
+		return nil, errors.New("base32 decoding of secret key failed")
+	}
+	return b, nil
+}
+
+// parseStep parses the time-step like `30`, `30s` or `1m`
+func parseStep(s string) (time.Duration, error) {
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		sec, err := strconv.ParseUint(s, 10, 32)
+		if err != nil {
+			return 0, fmt.Errorf("invalid time-step %q", s)
+		}
+		d = time.Duration(sec) * time.Second
+	}
+	if d < time.Second || d%time.Second != 0 {
+		return 0, fmt.Errorf("invalid time-step %q. please pass whole seconds", s)
+	}
+
+	return d, nil
+}
+
+// parseTime parses the time like `2008-04-23 17:42:17 UTC` or `@1208972537`
+func parseTime(s string) (time.Time, error) {
+	if strings.HasPrefix(s, "@") {
+		sec, err := strconv.ParseInt(s[1:], 10, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid time %q", s)
+		}
+		return time.Unix(sec, 0), nil
+	}
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid time %q", s)
+}
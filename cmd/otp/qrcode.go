@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	// register the decoders of the image formats of QR codes
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"os"
+
+	"github.com/makiuchi-d/gozxing"
+	zxingqrcode "github.com/makiuchi-d/gozxing/qrcode"
+)
+
+// decodeQRCode decodes the text of the QR code in the PNG, JPEG or GIF image file
+func decodeQRCode(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return "", fmt.Errorf("invalid image %s: %w", path, err)
+	}
+	bmp, err := gozxing.NewBinaryBitmapFromImage(img)
+	if err != nil {
+		return "", fmt.Errorf("invalid image %s: %w", path, err)
+	}
+	result, err := zxingqrcode.NewQRCodeReader().Decode(bmp, nil)
+	if err != nil {
+		return "", fmt.Errorf("QR code isn't found in %s", path)
+	}
+
+	return result.GetText(), nil
+}
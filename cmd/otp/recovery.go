@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/butterv/one-time-password/recovery"
+)
+
+const recoveryUsage = "otp recovery [--count COUNT] [--length LENGTH | --entropy BITS] [--alphabet ALPHABET] [--group SIZE] [--separator SEPARATOR] [--prefix PREFIX] [--checksum] [--passphrase [--words WORDS]]"
+
+var alphabets = map[string]recovery.Alphabet{
+	"alphanumeric": recovery.AlphabetAlphanumeric,
+	"crockford":    recovery.AlphabetCrockfordBase32,
+	"nolookalike":  recovery.AlphabetNoLookalike,
+	"digits":       recovery.AlphabetDigits,
+	"hex":          recovery.AlphabetHex,
+}
+
+// runRecovery prints recovery codes one per line
+func runRecovery(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet(name+" recovery", stderr, recoveryUsage)
+
+	var (
+		count, length, group, words uint
+		entropy                     float64
+		alphabet, separator, prefix string
+		checksum, passphrase        bool
+	)
+	fs.UintVar(&count, "count", 10, "the count of recovery codes")
+	fs.UintVar(&length, "length", 0, "the length of a recovery code")
+	fs.Float64Var(&entropy, "entropy", 0, "the entropy of a recovery code in bits, which derives the length")
+	fs.StringVar(&alphabet, "alphabet", "alphanumeric", "the alphabet, any of "+alphabetNames())
+	fs.UintVar(&group, "group", 0, "the count of characters in a group")
+	fs.StringVar(&separator, "separator", "-", "the separator between groups")
+	fs.StringVar(&prefix, "prefix", "", "the prefix identifying the issuing system")
+	fs.BoolVar(&checksum, "checksum", false, "append a check character")
+	fs.BoolVar(&passphrase, "passphrase", false, "generate passphrases of words")
+	fs.UintVar(&words, "words", 0, "the count of words in a passphrase")
+
+	positional, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 0 {
+		fs.Usage()
+		return fmt.Errorf("unexpected argument %q", positional[0])
+	}
+
+	a, ok := alphabets[alphabet]
+	if !ok {
+		return fmt.Errorf("invalid alphabet %q. please pass any of %s", alphabet, alphabetNames())
+	}
+
+	o := recovery.NewOption()
+	steps := []func() error{
+		func() error { return o.SetAlphabet(a) },
+		func() error { return o.SetCount(count) },
+		func() error { return o.SetChecksum(checksum) },
+		func() error { return o.SetPassphrase(passphrase) },
+	}
+	if length > 0 {
+		steps = append(steps, func() error { return o.SetLength(length) })
+	}
+	if entropy > 0 {
+		steps = append(steps, func() error { return o.SetEntropy(entropy) })
+	}
+	if group > 0 {
+		steps = append(steps, func() error { return o.SetGrouping(group, separator) })
+	}
+	if prefix != "" {
+		steps = append(steps, func() error { return o.SetPrefix(prefix) })
+	}
+	if words > 0 {
+		steps = append(steps, func() error { return o.SetWordCount(words) })
+	}
+	for _, step := range steps {
+		if err := step(); err != nil {
+			return err
+		}
+	}
+
+	codes, err := recovery.GenerateRecoveryCodesWithOption(o)
+	if err != nil {
+		return err
+	}
+	for _, code := range codes {
+		fmt.Fprintln(stdout, code)
+	}
+
+	return nil
+}
+
+func alphabetNames() string {
+	names := make([]string, 0, len(alphabets))
+	for n := range alphabets {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/skip2/go-qrcode"
+
+	"github.com/butterv/one-time-password/otpauth"
+)
+
+const (
+	uriUsage   = "otp uri --issuer ISSUER --account ACCOUNT [--hotp] [--algorithm ALGORITHM] [--digits DIGITS] [--period PERIOD] [--counter COUNTER] [--secret SECRET] [--qr FILE]"
+	parseUsage = "otp parse URI"
+	qrCodeSize = 256
+)
+
+// runURI generates an otpauth URI and optionally writes its QR code into a PNG file
+func runURI(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet(name+" uri", stderr, uriUsage)
+
+	var (
+		issuer, account, algorithm, secret, qr string
+		useHOTP                                bool
+		digits                                 int
+		period, secretSize                     uint
+		counter                                uint64
+	)
+	fs.StringVar(&issuer, "issuer", "", "the issuing organization or company")
+	fs.StringVar(&account, "account", "", "the user's account name or email address")
+	fs.BoolVar(&useHOTP, "hotp", false, "generate an URI of HOTP instead of TOTP")
+	fs.StringVar(&algorithm, "algorithm", "SHA1", "the hash algorithm, SHA1, SHA256 or SHA512")
+	fs.IntVar(&digits, "digits", int(otpauth.DigitsSix), "the number of digits, 6 or 8")
+	fs.UintVar(&period, "period", otpauth.DefaultPeriod, "the seconds that a TOTP is valid")
+	fs.Uint64Var(&counter, "counter", 0, "the initial counter of HOTP")
+	fs.StringVar(&secret, "secret", "", "the base32 encoded secret, which is generated when empty")
+	fs.UintVar(&secretSize, "secret-size", 20, "the size of the generated secret in bytes")
+	fs.StringVar(&qr, "qr", "", "write the QR code of the URI into the PNG file")
+
+	positional, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 0 {
+		fs.Usage()
+		return fmt.Errorf("unexpected argument %q", positional[0])
+	}
+
+	k := &otpauth.Key{
+		Host:        otpauth.HostTOTP,
+		Issuer:      issuer,
+		AccountName: account,
+		Secret:      secret,
+		Digits:      otpauth.Digits(digits),
+		Period:      period,
+		Counter:     counter,
+	}
+	if useHOTP {
+		k.Host = otpauth.HostHOTP
+	}
+	k.Algorithm, err = parseAlgorithm(algorithm)
+	if err != nil {
+		return err
+	}
+
+	opt, err := optionOf(k, secretSize)
+	if err != nil {
+		return err
+	}
+	oa, err := otpauth.GenerateOtpAuthWithOption(k.Issuer, k.AccountName, k.Host, opt)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(stdout, oa.URL())
+	for _, w := range oa.Warnings() {
+		fmt.Fprintf(stderr, "%s: warning: %s\n", name, w)
+	}
+	if qr != "" {
+		return qrcode.WriteFile(oa.URL(), qrcode.Medium, qrCodeSize, qr)
+	}
+
+	return nil
+}
+
+// optionOf returns an option of the key that generates the secret when the key doesn't have it
+func optionOf(k *otpauth.Key, secretSize uint) (*otpauth.Option, error) {
+	if k.Secret != "" {
+		return k.Option()
+	}
+
+	opt, err := otpauth.NewOption()
+	if err != nil {
+		return nil, err
+	}
+	if err := opt.SetAlgorithm(k.Algorithm); err != nil {
+		return nil, err
+	}
+	if err := opt.SetDigits(k.Digits); err != nil {
+		return nil, err
+	}
+	if err := opt.SetPeriod(k.Period); err != nil {
+		return nil, err
+	}
+	if err := opt.SetCounter(k.Counter); err != nil {
+		return nil, err
+	}
+	if err := opt.SetSecretSize(secretSize); err != nil {
+		return nil, err
+	}
+
+	return opt, nil
+}
+
+func parseAlgorithm(s string) (otpauth.Algorithm, error) {
+	for a := otpauth.AlgorithmSHA1; a.Enabled(); a++ {
+		if strings.EqualFold(a.String(), s) {
+			return a, nil
+		}
+	}
+
+	return 0, fmt.Errorf("invalid algorithm %q. please pass SHA1, SHA256 or SHA512", s)
+}
+
+// runParse prints the parameters of an otpauth URI
+func runParse(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet(name+" parse", stderr, parseUsage)
+	positional, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		fs.Usage()
+		return errors.New("please pass URI")
+	}
+
+	k, err := otpauth.ParseURL(positional[0])
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(stdout, "type:      %s\n", k.Host)
+	fmt.Fprintf(stdout, "issuer:    %s\n", k.Issuer)
+	fmt.Fprintf(stdout, "account:   %s\n", k.AccountName)
+	fmt.Fprintf(stdout, "secret:    %s\n", k.Secret)
+	fmt.Fprintf(stdout, "algorithm: %s\n", k.Algorithm)
+	fmt.Fprintf(stdout, "digits:    %d\n", k.Digits)
+	switch k.Host {
+	case otpauth.HostTOTP:
+		fmt.Fprintf(stdout, "period:    %d\n", k.Period)
+	case otpauth.HostHOTP:
+		fmt.Fprintf(stdout, "counter:   %d\n", k.Counter)
+	}
+
+	return nil
+}
@@ -0,0 +1,389 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"golang.org/x/term"
+
+	"github.com/butterv/one-time-password/otpauth"
+	"github.com/butterv/one-time-password/vault"
+)
+
+const (
+	vaultUsage       = "otp vault init|add|list|code|remove [--vault FILE]"
+	vaultInitUsage   = "otp vault init [--vault FILE] [--kdf argon2id|scrypt]"
+	vaultAddUsage    = "otp vault add [--vault FILE] [--name NAME] (URI | --qr FILE)"
+	vaultListUsage   = "otp vault list [--vault FILE]"
+	vaultCodeUsage   = "otp vault code [--vault FILE] [-N NOW] [NAME]"
+	vaultRemoveUsage = "otp vault remove [--vault FILE] NAME"
+
+	// vaultEnv is the environment variable of the path of the vault file
+	vaultEnv = "OTP_VAULT"
+	// passphraseEnv is the environment variable of the passphrase, which is intended for scripts
+	passphraseEnv = "OTP_VAULT_PASSPHRASE"
+)
+
+// runVault manages keys in a local vault file
+func runVault(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	if len(args) == 0 {
+		fmt.Fprintf(stderr, "Usage: %s\n", vaultUsage)
+		return errors.New("please pass a subcommand")
+	}
+
+	switch args[0] {
+	case "init":
+		return runVaultInit(args[1:], stdin, stdout, stderr)
+	case "add":
+		return runVaultAdd(args[1:], stdin, stdout, stderr)
+	case "list":
+		return runVaultList(args[1:], stdin, stdout, stderr)
+	case "code":
+		return runVaultCode(args[1:], stdin, stdout, stderr)
+	case "remove":
+		return runVaultRemove(args[1:], stdin, stdout, stderr)
+	}
+
+	fmt.Fprintf(stderr, "Usage: %s\n", vaultUsage)
+	return fmt.Errorf("unknown subcommand %q", args[0])
+}
+
+func runVaultInit(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs := newFlagSet(name+" vault init", stderr, vaultInitUsage)
+	path := vaultFlag(fs)
+	kdf := fs.String("kdf", vault.KDFArgon2id.String(), "the key derivation function of the passphrase, argon2id or scrypt")
+
+	positional, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 0 {
+		fs.Usage()
+		return fmt.Errorf("unexpected argument %q", positional[0])
+	}
+
+	opt := vault.NewOption()
+	switch *kdf {
+	case vault.KDFArgon2id.String():
+		err = opt.SetKDF(vault.KDFArgon2id)
+	case vault.KDFScrypt.String():
+		err = opt.SetKDF(vault.KDFScrypt)
+	default:
+		err = fmt.Errorf("invalid kdf %q. please pass argon2id or scrypt", *kdf)
+	}
+	if err != nil {
+		return err
+	}
+
+	passphrase, err := readPassphrase(stdin, stderr, true)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(*path), 0700); err != nil {
+		return err
+	}
+	if _, err := vault.Create(*path, passphrase, opt); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(stdout, "created %s\n", *path)
+	return nil
+}
+
+func runVaultAdd(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs := newFlagSet(name+" vault add", stderr, vaultAddUsage)
+	path := vaultFlag(fs)
+	entryName := fs.String("name", "", "the name of the entry, which is issuer:account when empty")
+	qr := fs.String("qr", "", "read the URI from the QR code in the PNG, JPEG or GIF file")
+
+	positional, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+
+	var uri string
+	switch {
+	case *qr != "" && len(positional) == 0:
+		uri, err = decodeQRCode(*qr)
+		if err != nil {
+			return err
+		}
+	case *qr == "" && len(positional) == 1:
+		uri = positional[0]
+	default:
+		fs.Usage()
+		return errors.New("please pass either URI or --qr")
+	}
+
+	k, err := otpauth.ParseURL(uri)
+	if err != nil {
+		return err
+	}
+
+	v, unlock, err := openVaultLocked(*path, stdin, stderr)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	e, err := v.Add(*entryName, k, time.Now())
+	if err == vault.ErrEntryExists {
+		return fmt.Errorf("%w. please pass another --name", err)
+	}
+	if err != nil {
+		return err
+	}
+	if err := v.Save(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(stdout, "added %s\n", e.Name)
+	return nil
+}
+
+func runVaultList(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs := newFlagSet(name+" vault list", stderr, vaultListUsage)
+	path := vaultFlag(fs)
+
+	positional, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 0 {
+		fs.Usage()
+		return fmt.Errorf("unexpected argument %q", positional[0])
+	}
+
+	v, err := openVault(*path, stdin, stderr)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tTYPE\tISSUER\tACCOUNT")
+	for _, e := range v.Entries() {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", e.Name, e.Key.Host, e.Key.Issuer, e.Key.AccountName)
+	}
+	return w.Flush()
+}
+
+// runVaultCode prints the current code of the entry, or the codes of all TOTP entries
+// HOTP entries are printed only by the name, because generating a code advances the counter
+func runVaultCode(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs := newFlagSet(name+" vault code", stderr, vaultCodeUsage)
+	path := vaultFlag(fs)
+	var now string
+	fs.StringVar(&now, "N", "", "use this time as current time")
+	fs.StringVar(&now, "now", "", "use this time as current time")
+
+	positional, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 1 {
+		fs.Usage()
+		return fmt.Errorf("unexpected argument %q", positional[1])
+	}
+
+	t := time.Now()
+	if now != "" {
+		t, err = parseTime(now)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(positional) == 1 {
+		// generating the code of HOTP advances the counter, so the vault is locked until it's saved
+		v, unlock, err := openVaultLocked(*path, stdin, stderr)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+
+		e, err := v.Entry(positional[0])
+		if err != nil {
+			return fmt.Errorf("%s: %w", positional[0], err)
+		}
+		code, err := v.Code(e.Name, t)
+		if err != nil {
+			return err
+		}
+		if e.Key.Host == otpauth.HostHOTP {
+			if err := v.Save(); err != nil {
+				return err
+			}
+		}
+		fmt.Fprintln(stdout, code)
+		return nil
+	}
+
+	v, err := openVault(*path, stdin, stderr)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tCODE\tEXPIRES")
+	for _, e := range v.Entries() {
+		if e.Key.Host != otpauth.HostTOTP {
+			fmt.Fprintf(w, "%s\t-\t-\n", e.Name)
+			continue
+		}
+		code, err := v.Code(e.Name, t)
+		if err != nil {
+			return err
+		}
+		period := int64(e.Key.Period)
+		fmt.Fprintf(w, "%s\t%s\t%ds\n", e.Name, code, period-t.Unix()%period)
+	}
+	return w.Flush()
+}
+
+func runVaultRemove(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs := newFlagSet(name+" vault remove", stderr, vaultRemoveUsage)
+	path := vaultFlag(fs)
+
+	positional, err := parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		fs.Usage()
+		return errors.New("please pass NAME")
+	}
+
+	v, unlock, err := openVaultLocked(*path, stdin, stderr)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	if err := v.Remove(positional[0]); err != nil {
+		return fmt.Errorf("%s: %w", positional[0], err)
+	}
+	if err := v.Save(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(stdout, "removed %s\n", positional[0])
+	return nil
+}
+
+// vaultFlag defines --vault whose default value is $OTP_VAULT or `otp/vault.json` in the user's config directory
+func vaultFlag(fs *flag.FlagSet) *string {
+	path := os.Getenv(vaultEnv)
+	if path == "" {
+		dir, err := os.UserConfigDir()
+		if err != nil {
+			dir = "."
+		}
+		path = filepath.Join(dir, name, "vault.json")
+	}
+
+	fs.StringVar(&path, "vault", path, "the path of the vault file, or $"+vaultEnv)
+	return &path
+}
+
+func openVault(path string, stdin io.Reader, stderr io.Writer) (*vault.Vault, error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil, fmt.Errorf("vault %s doesn't exist. please run `%s vault init`", path, name)
+	}
+
+	passphrase, err := readPassphrase(stdin, stderr, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return vault.Open(path, passphrase)
+}
+
+// openVaultLocked opens the vault holding the exclusive lock of the vault
+// The returned function releases the lock, which is called after the vault is saved,
+// so concurrent processes never read the vault that another process is changing
+func openVaultLocked(path string, stdin io.Reader, stderr io.Writer) (*vault.Vault, func(), error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil, nil, fmt.Errorf("vault %s doesn't exist. please run `%s vault init`", path, name)
+	}
+
+	unlock, err := lockVault(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	v, err := openVault(path, stdin, stderr)
+	if err != nil {
+		unlock()
+		return nil, nil, err
+	}
+
+	return v, unlock, nil
+}
+
+// lockVault takes the exclusive lock of the vault, which waits until another process releases the lock
+// The lock is taken on `FILE.lock` beside the vault, because saving the vault replaces the vault file
+// The lock file is left after unlocking, since removing it lets another process lock a new file at the same time
+func lockVault(path string) (func(), error) {
+	f, err := os.OpenFile(path+".lock", os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	if err := lockFile(f); err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("lock vault %s: %w", path, err)
+	}
+
+	return func() {
+		_ = unlockFile(f)
+		_ = f.Close()
+	}, nil
+}
+
+// readPassphrase reads the passphrase from $OTP_VAULT_PASSPHRASE, the terminal without echo or a line of the standard input
+// When confirm is true, the passphrase is asked twice on the terminal
+func readPassphrase(stdin io.Reader, stderr io.Writer, confirm bool) ([]byte, error) {
+	if p := os.Getenv(passphraseEnv); p != "" {
+		return []byte(p), nil
+	}
+
+	if f, ok := stdin.(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+		fmt.Fprint(stderr, "Passphrase: ")
+		p, err := term.ReadPassword(int(f.Fd()))
+		fmt.Fprintln(stderr)
+		if err != nil {
+			return nil, err
+		}
+		if confirm {
+			fmt.Fprint(stderr, "Confirm passphrase: ")
+			again, err := term.ReadPassword(int(f.Fd()))
+			fmt.Fprintln(stderr)
+			if err != nil {
+				return nil, err
+			}
+			if !bytes.Equal(p, again) {
+				return nil, errors.New("passphrases don't match")
+			}
+		}
+		if len(p) == 0 {
+			return nil, errors.New("passphrase is empty")
+		}
+		return p, nil
+	}
+
+	line, err := bufio.NewReader(stdin).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	p := strings.TrimRight(line, "\r\n")
+	if p == "" {
+		return nil, errors.New("passphrase is empty")
+	}
+
+	return []byte(p), nil
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/skip2/go-qrcode"
+)
+
+const vaultPassphrase = "correct horse battery staple\n"
+
+func TestRun_Vault(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "otp", "vault.json")
+	vault := func(stdin string, args ...string) (string, string, int) {
+		t.Helper()
+		return runCommandWithInput(t, stdin, append(append([]string{"vault"}, args...), "--vault", path)...)
+	}
+
+	if got, stderr, code := vault(vaultPassphrase, "init", "--kdf", "scrypt"); code != 0 || got != "created "+path+"\n" {
+		t.Fatalf("vault init prints %q, %d; want created, 0, stderr %s", got, code, stderr)
+	}
+	if _, _, code := vault(vaultPassphrase, "init"); code != 1 {
+		t.Errorf("vault init of the existing vault=%d; want 1", code)
+	}
+
+	totpURI := "otpauth://totp/butter:butter@example.com?secret=GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ&digits=8"
+	if got, stderr, code := vault(vaultPassphrase, "add", totpURI); code != 0 || got != "added butter:butter@example.com\n" {
+		t.Fatalf("vault add prints %q, %d; want added, 0, stderr %s", got, code, stderr)
+	}
+	if _, stderr, code := vault(vaultPassphrase, "add", totpURI); code != 1 || !strings.Contains(stderr, "already exists") {
+		t.Errorf("vault add of the same entry prints %q, %d; want already exists, 1", stderr, code)
+	}
+
+	qr := filepath.Join(t.TempDir(), "qr.png")
+	_ = qrcode.WriteFile("otpauth://hotp/deploy?secret=GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ&counter=0", qrcode.Medium, 256, qr)
+	if got, stderr, code := vault(vaultPassphrase, "add", "--name", "deploy", "--qr", qr); code != 0 || got != "added deploy\n" {
+		t.Fatalf("vault add --qr prints %q, %d; want added, 0, stderr %s", got, code, stderr)
+	}
+
+	want := `NAME                       TYPE  ISSUER  ACCOUNT
+butter:butter@example.com  totp  butter  butter@example.com
+deploy                     hotp          deploy
+`
+	if got, _, code := vault(vaultPassphrase, "list"); code != 0 || got != want {
+		t.Errorf("vault list prints %q, %d; want %q, 0", got, code, want)
+	}
+
+	want = `NAME                       CODE      EXPIRES
+butter:butter@example.com  07081804  1s
+deploy                     -         -
+`
+	if got, _, code := vault(vaultPassphrase, "code", "-N", "@1111111109"); code != 0 || got != want {
+		t.Errorf("vault code prints %q, %d; want %q, 0", got, code, want)
+	}
+
+	// the counter of HOTP advances every time
+	for _, want := range []string{"755224\n", "287082\n"} {
+		if got, _, code := vault(vaultPassphrase, "code", "deploy"); code != 0 || got != want {
+			t.Errorf("vault code deploy prints %q, %d; want %q, 0", got, code, want)
+		}
+	}
+
+	if got, _, code := vault(vaultPassphrase, "remove", "deploy"); code != 0 || got != "removed deploy\n" {
+		t.Errorf("vault remove prints %q, %d; want removed, 0", got, code)
+	}
+	if _, _, code := vault(vaultPassphrase, "code", "deploy"); code != 1 {
+		t.Errorf("vault code of the removed entry=%d; want 1", code)
+	}
+
+	if _, stderr, code := vault("wrong\n", "list"); code != 1 || stderr != "otp: invalid passphrase\n" {
+		t.Errorf("vault list with wrong passphrase prints %q, %d; want invalid passphrase, 1", stderr, code)
+	}
+}
+
+func TestRun_VaultConcurrentHOTP(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vault.json")
+	vault := func(args ...string) (string, string, int) {
+		return runCommandWithInput(t, vaultPassphrase, append(append([]string{"vault"}, args...), "--vault", path)...)
+	}
+
+	if _, stderr, code := vault("init", "--kdf", "scrypt"); code != 0 {
+		t.Fatalf("vault init=%d; want 0, stderr %s", code, stderr)
+	}
+	if _, stderr, code := vault("add", "otpauth://hotp/deploy?secret=GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ&counter=0"); code != 0 {
+		t.Fatalf("vault add=%d; want 0, stderr %s", code, stderr)
+	}
+
+	// every process generates the code of a distinct counter
+	want := []string{"287082\n", "338314\n", "359152\n", "755224\n", "969429\n"}
+	got := make([]string, len(want))
+	var wg sync.WaitGroup
+	for i := range got {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			got[i], _, _ = vault("code", "deploy")
+		}(i)
+	}
+	wg.Wait()
+
+	sort.Strings(got)
+	if strings.Join(got, "") != strings.Join(want, "") {
+		t.Errorf("vault code deploy concurrently prints %q; want %q", got, want)
+	}
+}
+
+func TestRun_VaultInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vault.json")
+	tests := [][]string{
+		{"vault"},
+		{"vault", "unknown"},
+		{"vault", "list", "--vault", path},
+		{"vault", "init", "--kdf", "pbkdf2", "--vault", path},
+		{"vault", "add", "--vault", path},
+	}
+
+	for _, args := range tests {
+		if _, _, code := runCommandWithInput(t, vaultPassphrase, args...); code != 1 {
+			t.Errorf("run(%v)=%d; want 1", args, code)
+		}
+	}
+
+	if _, _, code := runCommandWithInput(t, "", "vault", "init", "--vault", path); code != 1 {
+		t.Errorf("vault init with empty passphrase=%d; want 1", code)
+	}
+}
@@ -0,0 +1,84 @@
+package enrollment
+
+import (
+	"errors"
+	"time"
+
+	"github.com/butterv/one-time-password/otpauth"
+)
+
+// State is the state of an enrollment
+type State int
+
+const (
+	// StatePending is the state that the key waits for the confirmation of the user
+	StatePending State = iota
+	// StateActive is the state that the user proves possession of the key
+	StateActive
+	// StateExpired is the state that the enrollment is abandoned until it expires
+	StateExpired
+)
+
+var stateNames = map[State]string{
+	StatePending: "pending",
+	StateActive:  "active",
+	StateExpired: "expired",
+}
+
+// String returns the name of the state
+func (s State) String() string {
+	if name, ok := stateNames[s]; ok {
+		return name
+	}
+
+	return "unknown"
+}
+
+var (
+	// ErrEnrollmentNotFound is an error when the enrollment doesn't exist in the store
+	ErrEnrollmentNotFound = errors.New("enrollment is not found")
+	// ErrEnrollmentExpired is an error when the enrollment has expired before it is confirmed
+	ErrEnrollmentExpired = errors.New("enrollment has expired")
+	// ErrEnrollmentNotPending is an error when the enrollment has already been activated or expired
+	ErrEnrollmentNotPending = errors.New("enrollment is not pending")
+	// ErrInvalidPasscode is an error when the passcode doesn't prove possession of the key
+	ErrInvalidPasscode = errors.New("invalid passcode")
+	// ErrConflict is an error when the enrollment has been updated by another request
+	ErrConflict = errors.New("enrollment has been updated by another request")
+)
+
+// Enrollment is a key that is enrolled for a user
+// The fields are exported so that a Store can persist them
+type Enrollment struct {
+	// ID is the identifier of the enrollment
+	ID string
+	// UserID is the identifier of the user
+	UserID string
+	// Host is the type of the one time password
+	Host otpauth.Host
+	// Secret is the base32 encoded secret of the key
+	Secret string
+	// URL is the otpauth URI of the key
+	URL string
+	// State is the state of the enrollment
+	State State
+	// Confirmations is the count of consecutive valid passcodes so far
+	Confirmations uint
+	// Step is the time step of the last valid TOTP passcode
+	Step uint64
+	// Counter is the next counter of HOTP
+	Counter uint64
+	// CreatedAt is the time when the enrollment is started
+	CreatedAt time.Time
+	// ExpiresAt is the time when the pending enrollment expires
+	ExpiresAt time.Time
+	// ActivatedAt is the time when the key is activated
+	ActivatedAt time.Time
+	// Revision is incremented by the store every time the enrollment is updated
+	Revision uint64
+}
+
+// expired reports whether the pending enrollment has expired at the time
+func (e *Enrollment) expired(t time.Time) bool {
+	return e.State == StatePending && !t.Before(e.ExpiresAt)
+}
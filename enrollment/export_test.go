@@ -0,0 +1,35 @@
+package enrollment
+
+import "time"
+
+func (opt *Option) TTL() time.Duration {
+	if opt == nil {
+		return 0
+	}
+
+	return opt.ttl
+}
+
+func (opt *Option) Confirmations() uint {
+	if opt == nil {
+		return 0
+	}
+
+	return opt.confirmations
+}
+
+func (opt *Option) LookAhead() uint {
+	if opt == nil {
+		return 0
+	}
+
+	return opt.lookAhead
+}
+
+func (opt *Option) Skew() uint {
+	if opt == nil {
+		return 0
+	}
+
+	return opt.skew
+}
@@ -0,0 +1,249 @@
+package enrollment
+
+import (
+	"context"
+	crand "crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/butterv/one-time-password/hotp"
+	"github.com/butterv/one-time-password/otpauth"
+	"github.com/butterv/one-time-password/totp"
+)
+
+const idSize = 16
+
+// ErrManagerIsNil is an error when the enrollment manager is nil
+var ErrManagerIsNil = errors.New("enrollment manager is nil")
+
+// Manager enrolls keys that are activated only after the user proves possession of them
+type Manager struct {
+	// store persists enrollments
+	store Store
+	// opt is used when enrolls a key
+	opt *Option
+}
+
+// NewManager generates an enrollment manager by passing store and option
+func NewManager(store Store, opt *Option) (*Manager, error) {
+	if store == nil {
+		return nil, errors.New("store is nil")
+	}
+	if opt == nil {
+		return nil, ErrEnrollmentOptionIsNil
+	}
+
+	return &Manager{
+		store: store,
+		opt:   opt,
+	}, nil
+}
+
+// Start generates a pending key of the user and its otpauth URI
+// The returned otpAuth is shown to the user, and the key is activated by Confirm
+func (m *Manager) Start(ctx context.Context, issuer, accountName, userID string, host otpauth.Host, t time.Time) (*Enrollment, *otpauth.OtpAuth, error) {
+	if m == nil {
+		return nil, nil, ErrManagerIsNil
+	}
+	if userID == "" {
+		return nil, nil, errors.New("userID is empty")
+	}
+
+	oa, err := otpauth.GenerateOtpAuthWithOption(issuer, accountName, host, m.opt.otpAuthOpt)
+	if err != nil {
+		return nil, nil, err
+	}
+	id, err := newID()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	e := &Enrollment{
+		ID:        id,
+		UserID:    userID,
+		Host:      host,
+		Secret:    oa.Secret(),
+		URL:       oa.URL(),
+		State:     StatePending,
+		Counter:   m.opt.otpAuthOpt.Counter(),
+		CreatedAt: t,
+		ExpiresAt: t.Add(m.opt.ttl),
+	}
+	err = m.store.Create(ctx, e)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return e, oa, nil
+}
+
+// Get returns the enrollment of the identifier
+func (m *Manager) Get(ctx context.Context, id string) (*Enrollment, error) {
+	if m == nil {
+		return nil, ErrManagerIsNil
+	}
+
+	return m.store.Get(ctx, id)
+}
+
+// Confirm validates the passcode of the pending key and activates the key when enough consecutive passcodes are valid
+// This returns the enrollment with the progress even when the passcode is invalid
+func (m *Manager) Confirm(ctx context.Context, id, passcode string, t time.Time) (*Enrollment, error) {
+	if m == nil {
+		return nil, ErrManagerIsNil
+	}
+
+	e, err := m.store.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if e.expired(t) {
+		e.State = StateExpired
+		if err := m.store.Update(ctx, e); err != nil {
+			return nil, err
+		}
+		return e, ErrEnrollmentExpired
+	}
+	if e.State != StatePending {
+		return e, ErrEnrollmentNotPending
+	}
+
+	ok, err := m.verify(e, passcode, t)
+	if err == otpauth.ErrInvalidDigitsLength {
+		ok, err = false, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		e.Confirmations = 0
+		if err := m.store.Update(ctx, e); err != nil {
+			return nil, err
+		}
+		return e, ErrInvalidPasscode
+	}
+
+	if e.Confirmations >= m.opt.confirmations {
+		e.State = StateActive
+		e.ActivatedAt = t
+	}
+	if err := m.store.Update(ctx, e); err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+// Expire marks the abandoned enrollments expired, and returns the count of them
+func (m *Manager) Expire(ctx context.Context, t time.Time) (int, error) {
+	if m == nil {
+		return 0, ErrManagerIsNil
+	}
+
+	return m.store.Expire(ctx, t)
+}
+
+// verify validates the passcode and counts consecutive valid passcodes
+// A passcode of the time step or the counter that has already been used is invalid
+func (m *Manager) verify(e *Enrollment, passcode string, t time.Time) (bool, error) {
+	k, err := otpauth.ParseURL(e.URL)
+	if err != nil {
+		return false, err
+	}
+
+	switch e.Host {
+	case otpauth.HostTOTP:
+		opt, err := m.totpOption(k)
+		if err != nil {
+			return false, err
+		}
+		step, ok, err := totp.ValidateStepWithOption(passcode, e.Secret, t, opt)
+		if err != nil || !ok {
+			return false, err
+		}
+		if e.Confirmations > 0 && step <= e.Step {
+			return false, nil
+		}
+		if e.Confirmations > 0 && step == e.Step+1 {
+			e.Confirmations++
+		} else {
+			e.Confirmations = 1
+		}
+		e.Step = step
+		return true, nil
+	case otpauth.HostHOTP:
+		opt, err := hotpOption(k)
+		if err != nil {
+			return false, err
+		}
+		for i := uint64(0); i <= uint64(m.opt.lookAhead); i++ {
+			ok, err := hotp.ValidateWithOption(passcode, e.Secret, e.Counter+i, opt)
+			if err != nil {
+				return false, err
+			}
+			if !ok {
+				continue
+			}
+			if e.Confirmations > 0 && i == 0 {
+				e.Confirmations++
+			} else {
+				e.Confirmations = 1
+			}
+			e.Counter += i + 1
+			return true, nil
+		}
+		return false, nil
+	}
+
+	return false, fmt.Errorf("invalid host. please pass %d or %d", otpauth.HostHOTP, otpauth.HostTOTP)
+}
+
+// totpOption returns the option of TOTP with the period, the digits and the algorithm of the key shown to the user
+// The key has been validated by the policy of the otpauth option, so the option doesn't validate it again
+func (m *Manager) totpOption(k *otpauth.Key) (*totp.Option, error) {
+	opt := totp.NewOption()
+	if err := opt.SetPolicy(nil); err != nil {
+		return nil, err
+	}
+	if err := opt.SetPeriod(k.Period); err != nil {
+		return nil, err
+	}
+	if err := opt.SetDigits(k.Digits); err != nil {
+		return nil, err
+	}
+	if err := opt.SetAlgorithm(k.Algorithm); err != nil {
+		return nil, err
+	}
+	if err := opt.SetSkew(m.opt.skew); err != nil {
+		return nil, err
+	}
+
+	return opt, nil
+}
+
+// hotpOption returns the option of HOTP with the digits and the algorithm of the key shown to the user
+func hotpOption(k *otpauth.Key) (*hotp.Option, error) {
+	opt := hotp.NewOption()
+	if err := opt.SetPolicy(nil); err != nil {
+		return nil, err
+	}
+	if err := opt.SetDigits(k.Digits); err != nil {
+		return nil, err
+	}
+	if err := opt.SetAlgorithm(k.Algorithm); err != nil {
+		return nil, err
+	}
+
+	return opt, nil
+}
+
+func newID() (string, error) {
+	b := make([]byte, idSize)
+	if _, err := crand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
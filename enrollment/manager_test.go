@@ -0,0 +1,236 @@
+package enrollment_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/butterv/one-time-password/enrollment"
+	"github.com/butterv/one-time-password/hotp"
+	"github.com/butterv/one-time-password/otpauth"
+	"github.com/butterv/one-time-password/totp"
+)
+
+const secret = "3EOJMVMDTXHMHFQ3CK45R6NWIG4VWAQA"
+
+var now = time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC)
+
+func newManager(t *testing.T, confirmations uint) (*enrollment.Manager, *enrollment.MemoryStore) {
+	t.Helper()
+
+	otpAuthOpt, _ := otpauth.NewOption()
+	_ = otpAuthOpt.SetSecret(secret)
+	opt, _ := enrollment.NewOption()
+	_ = opt.SetOtpAuthOption(otpAuthOpt)
+	if err := opt.SetConfirmations(confirmations); err != nil {
+		t.Fatalf("SetConfirmations(%d)=%#v; want nil", confirmations, err)
+	}
+
+	store := enrollment.NewMemoryStore()
+	m, err := enrollment.NewManager(store, opt)
+	if err != nil {
+		t.Fatalf("NewManager()=_, %#v; want nil", err)
+	}
+
+	return m, store
+}
+
+// passcode returns the TOTP passcode of the default period at the time
+func passcode(t *testing.T, ti time.Time) string {
+	t.Helper()
+
+	p, err := hotp.GeneratePasscode(secret, uint64(ti.Unix()/30))
+	if err != nil {
+		t.Fatalf("GeneratePasscode()=_, %#v; want nil", err)
+	}
+
+	return p
+}
+
+func TestManager_Start(t *testing.T) {
+	ctx := context.Background()
+	m, store := newManager(t, 1)
+
+	e, oa, err := m.Start(ctx, "butter", "butter@example.com", "user", otpauth.HostTOTP, now)
+	if err != nil {
+		t.Fatalf("Start()=_, _, %#v; want nil", err)
+	}
+	if e.State != enrollment.StatePending || e.Secret != secret || e.URL != oa.URL() {
+		t.Errorf("Start()=%#v; want pending enrollment of %s", e, oa.URL())
+	}
+	if want := now.Add(10 * time.Minute); !e.ExpiresAt.Equal(want) {
+		t.Errorf("ExpiresAt: got %v, want %v", e.ExpiresAt, want)
+	}
+
+	got, err := store.Get(ctx, e.ID)
+	if err != nil {
+		t.Fatalf("Get(%s)=_, %#v; want nil", e.ID, err)
+	}
+	if got.UserID != "user" {
+		t.Errorf("Get(%s)=%#v; want enrollment of user", e.ID, got)
+	}
+}
+
+func TestManager_Confirm_TOTP(t *testing.T) {
+	ctx := context.Background()
+	m, _ := newManager(t, 1)
+	e, _, _ := m.Start(ctx, "butter", "butter@example.com", "user", otpauth.HostTOTP, now)
+
+	if _, err := m.Confirm(ctx, e.ID, "000000", now); err != enrollment.ErrInvalidPasscode {
+		t.Errorf("Confirm(%s, 000000)=_, %#v; want %v", e.ID, err, enrollment.ErrInvalidPasscode)
+	}
+
+	got, err := m.Confirm(ctx, e.ID, passcode(t, now), now)
+	if err != nil {
+		t.Fatalf("Confirm()=_, %#v; want nil", err)
+	}
+	if got.State != enrollment.StateActive || !got.ActivatedAt.Equal(now) {
+		t.Errorf("Confirm()=%#v; want active enrollment", got)
+	}
+
+	if _, err := m.Confirm(ctx, e.ID, passcode(t, now), now); err != enrollment.ErrEnrollmentNotPending {
+		t.Errorf("Confirm()=_, %#v; want %v", err, enrollment.ErrEnrollmentNotPending)
+	}
+}
+
+func TestManager_Confirm_OtpAuthOption(t *testing.T) {
+	ctx := context.Background()
+	otpAuthOpt, _ := otpauth.NewOption()
+	_ = otpAuthOpt.SetSecret(secret)
+	_ = otpAuthOpt.SetDigits(otpauth.DigitsEight)
+	_ = otpAuthOpt.SetAlgorithm(otpauth.AlgorithmSHA256)
+	_ = otpAuthOpt.SetPeriod(60)
+	opt, _ := enrollment.NewOption()
+	_ = opt.SetOtpAuthOption(otpAuthOpt)
+	m, _ := enrollment.NewManager(enrollment.NewMemoryStore(), opt)
+
+	e, _, err := m.Start(ctx, "butter", "butter@example.com", "user", otpauth.HostTOTP, now)
+	if err != nil {
+		t.Fatalf("Start()=_, _, %#v; want nil", err)
+	}
+
+	// the passcode is validated with the digits, the algorithm and the period of the otpauth URI
+	totpOpt := totp.NewOption()
+	_ = totpOpt.SetDigits(otpauth.DigitsEight)
+	_ = totpOpt.SetAlgorithm(otpauth.AlgorithmSHA256)
+	_ = totpOpt.SetPeriod(60)
+	p, _ := totp.GeneratePasscodeWithOption(secret, now, totpOpt)
+
+	got, err := m.Confirm(ctx, e.ID, p, now)
+	if err != nil {
+		t.Fatalf("Confirm(%s, %s)=_, %#v; want nil", e.ID, p, err)
+	}
+	if got.State != enrollment.StateActive {
+		t.Errorf("Confirm()=%#v; want active enrollment", got)
+	}
+}
+
+func TestManager_Confirm_TwoConsecutiveTOTP(t *testing.T) {
+	ctx := context.Background()
+	m, _ := newManager(t, 2)
+	e, _, _ := m.Start(ctx, "butter", "butter@example.com", "user", otpauth.HostTOTP, now)
+
+	got, err := m.Confirm(ctx, e.ID, passcode(t, now), now)
+	if err != nil {
+		t.Fatalf("Confirm()=_, %#v; want nil", err)
+	}
+	if got.State != enrollment.StatePending || got.Confirmations != 1 {
+		t.Errorf("Confirm()=%#v; want pending enrollment with 1 confirmation", got)
+	}
+
+	// the same passcode is rejected
+	if _, err := m.Confirm(ctx, e.ID, passcode(t, now), now); err != enrollment.ErrInvalidPasscode {
+		t.Errorf("Confirm()=_, %#v; want %v", err, enrollment.ErrInvalidPasscode)
+	}
+
+	next := now.Add(30 * time.Second)
+	_, _ = m.Confirm(ctx, e.ID, passcode(t, now), now)
+	got, err = m.Confirm(ctx, e.ID, passcode(t, next), next)
+	if err != nil {
+		t.Fatalf("Confirm()=_, %#v; want nil", err)
+	}
+	if got.State != enrollment.StateActive {
+		t.Errorf("Confirm()=%#v; want active enrollment", got)
+	}
+}
+
+func TestManager_Confirm_TwoConsecutiveHOTP(t *testing.T) {
+	ctx := context.Background()
+	m, _ := newManager(t, 2)
+	e, _, _ := m.Start(ctx, "butter", "butter@example.com", "user", otpauth.HostHOTP, now)
+
+	// the authenticator app skips counters 0 and 1
+	p2, _ := hotp.GeneratePasscode(secret, 2)
+	p4, _ := hotp.GeneratePasscode(secret, 4)
+	p5, _ := hotp.GeneratePasscode(secret, 5)
+
+	got, err := m.Confirm(ctx, e.ID, p2, now)
+	if err != nil || got.Confirmations != 1 || got.Counter != 3 {
+		t.Fatalf("Confirm(%s)=%#v, %#v; want 1 confirmation and counter 3", p2, got, err)
+	}
+	// the passcode that isn't consecutive restarts the confirmations
+	got, err = m.Confirm(ctx, e.ID, p4, now)
+	if err != nil || got.Confirmations != 1 || got.State != enrollment.StatePending {
+		t.Fatalf("Confirm(%s)=%#v, %#v; want 1 confirmation", p4, got, err)
+	}
+	got, err = m.Confirm(ctx, e.ID, p5, now)
+	if err != nil || got.State != enrollment.StateActive || got.Counter != 6 {
+		t.Errorf("Confirm(%s)=%#v, %#v; want active enrollment with counter 6", p5, got, err)
+	}
+}
+
+func TestManager_Confirm_Expired(t *testing.T) {
+	ctx := context.Background()
+	m, _ := newManager(t, 1)
+	e, _, _ := m.Start(ctx, "butter", "butter@example.com", "user", otpauth.HostTOTP, now)
+
+	later := now.Add(10 * time.Minute)
+	got, err := m.Confirm(ctx, e.ID, passcode(t, later), later)
+	if err != enrollment.ErrEnrollmentExpired {
+		t.Fatalf("Confirm()=_, %#v; want %v", err, enrollment.ErrEnrollmentExpired)
+	}
+	if got.State != enrollment.StateExpired {
+		t.Errorf("Confirm()=%#v; want expired enrollment", got)
+	}
+}
+
+func TestManager_Expire(t *testing.T) {
+	ctx := context.Background()
+	m, store := newManager(t, 1)
+	abandoned, _, _ := m.Start(ctx, "butter", "butter@example.com", "user1", otpauth.HostTOTP, now)
+	confirmed, _, _ := m.Start(ctx, "butter", "butter@example.com", "user2", otpauth.HostTOTP, now)
+	_, _ = m.Confirm(ctx, confirmed.ID, passcode(t, now), now)
+
+	n, err := m.Expire(ctx, now.Add(time.Hour))
+	if err != nil || n != 1 {
+		t.Fatalf("Expire()=%d, %#v; want 1, nil", n, err)
+	}
+	if got, _ := store.Get(ctx, abandoned.ID); got.State != enrollment.StateExpired {
+		t.Errorf("abandoned enrollment is %s; want expired", got.State)
+	}
+	if got, _ := store.Get(ctx, confirmed.ID); got.State != enrollment.StateActive {
+		t.Errorf("confirmed enrollment is %s; want active", got.State)
+	}
+}
+
+func TestManager_Confirm_NotFound(t *testing.T) {
+	m, _ := newManager(t, 1)
+	if _, err := m.Confirm(context.Background(), "unknown", "000000", now); err != enrollment.ErrEnrollmentNotFound {
+		t.Errorf("Confirm(unknown)=_, %#v; want %v", err, enrollment.ErrEnrollmentNotFound)
+	}
+}
+
+func TestMemoryStore_Update_Conflict(t *testing.T) {
+	ctx := context.Background()
+	store := enrollment.NewMemoryStore()
+	_ = store.Create(ctx, &enrollment.Enrollment{ID: "id"})
+
+	first, _ := store.Get(ctx, "id")
+	second, _ := store.Get(ctx, "id")
+	if err := store.Update(ctx, first); err != nil {
+		t.Fatalf("Update()=%#v; want nil", err)
+	}
+	if err := store.Update(ctx, second); err != enrollment.ErrConflict {
+		t.Errorf("Update()=%#v; want %v", err, enrollment.ErrConflict)
+	}
+}
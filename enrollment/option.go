@@ -0,0 +1,117 @@
+package enrollment
+
+import (
+	"errors"
+	"time"
+
+	"github.com/butterv/one-time-password/otpauth"
+)
+
+const (
+	defaultTTL           = 10 * time.Minute
+	defaultConfirmations = uint(1)
+	defaultLookAhead     = uint(10)
+	defaultSkew          = uint(1)
+	maxConfirmations     = uint(2)
+)
+
+// ErrEnrollmentOptionIsNil is an error when the enrollment option is nil
+var ErrEnrollmentOptionIsNil = errors.New("enrollment option is nil")
+
+// Option is used when enrolls a key
+type Option struct {
+	// ttl is the duration until an abandoned enrollment expires
+	// The default value is 10 minutes
+	ttl time.Duration
+	// confirmations is the count of consecutive valid passcodes required to activate the key
+	// The default value is 1
+	confirmations uint
+	// lookAhead is the count of counters ahead of the current one that a HOTP passcode is accepted for
+	// The default value is 10
+	lookAhead uint
+	// skew is the count of time steps back and forth that a TOTP passcode is accepted for
+	// The default value is 1
+	skew uint
+	// otpAuthOpt is used when generates the key and its otpauth URI
+	// The period, the digits and the algorithm of passcodes are validated as the otpauth URI of each enrollment has
+	otpAuthOpt *otpauth.Option
+}
+
+// SetTTL sets a duration until an abandoned enrollment expires
+func (opt *Option) SetTTL(ttl time.Duration) error {
+	if opt == nil {
+		return ErrEnrollmentOptionIsNil
+	}
+	if ttl <= 0 {
+		return errors.New("invalid ttl. please pass greater than 0")
+	}
+
+	opt.ttl = ttl
+	return nil
+}
+
+// SetConfirmations sets a count of consecutive valid passcodes required to activate the key
+// Requiring 2 passcodes proves that the clock or the counter of the authenticator app is in sync
+func (opt *Option) SetConfirmations(confirmations uint) error {
+	if opt == nil {
+		return ErrEnrollmentOptionIsNil
+	}
+	if confirmations == 0 || confirmations > maxConfirmations {
+		return errors.New("invalid confirmations. please pass 1 or 2")
+	}
+
+	opt.confirmations = confirmations
+	return nil
+}
+
+// SetLookAhead sets a count of counters ahead of the current one that a HOTP passcode is accepted for
+func (opt *Option) SetLookAhead(lookAhead uint) error {
+	if opt == nil {
+		return ErrEnrollmentOptionIsNil
+	}
+
+	opt.lookAhead = lookAhead
+	return nil
+}
+
+// SetSkew sets a count of time steps back and forth that a TOTP passcode is accepted for
+func (opt *Option) SetSkew(skew uint) error {
+	if opt == nil {
+		return ErrEnrollmentOptionIsNil
+	}
+	if skew == 0 {
+		return errors.New("invalid skew. please pass greater than 0")
+	}
+
+	opt.skew = skew
+	return nil
+}
+
+// SetOtpAuthOption sets an option used when generates the key and its otpauth URI
+func (opt *Option) SetOtpAuthOption(o *otpauth.Option) error {
+	if opt == nil {
+		return ErrEnrollmentOptionIsNil
+	}
+	if o == nil {
+		return otpauth.ErrOtpAuthOptionIsNil
+	}
+
+	opt.otpAuthOpt = o
+	return nil
+}
+
+// NewOption generates an option with default values
+func NewOption() (*Option, error) {
+	otpAuthOpt, err := otpauth.NewOption()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Option{
+		ttl:           defaultTTL,
+		confirmations: defaultConfirmations,
+		lookAhead:     defaultLookAhead,
+		skew:          defaultSkew,
+		otpAuthOpt:    otpAuthOpt,
+	}, nil
+}
@@ -0,0 +1,74 @@
+package enrollment_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/butterv/one-time-password/enrollment"
+)
+
+func TestNewOption(t *testing.T) {
+	got, err := enrollment.NewOption()
+	if err != nil {
+		t.Fatalf("NewOption()=_, %#v; want nil", err)
+	}
+	if got.TTL() != 10*time.Minute || got.Confirmations() != 1 || got.LookAhead() != 10 || got.Skew() != 1 {
+		t.Errorf("NewOption()=%#v; want default values", got)
+	}
+}
+
+func TestOption_SetTTL(t *testing.T) {
+	o, _ := enrollment.NewOption()
+	if err := o.SetTTL(time.Minute); err != nil || o.TTL() != time.Minute {
+		t.Errorf("SetTTL(1m)=%#v; want nil, ttl %v", err, o.TTL())
+	}
+	if err := o.SetTTL(0); err == nil {
+		t.Errorf("SetTTL(0)=nil; want error")
+	}
+}
+
+func TestOption_SetConfirmations(t *testing.T) {
+	tests := []struct {
+		in      uint
+		wantErr bool
+	}{
+		{in: 1, wantErr: false},
+		{in: 2, wantErr: false},
+		{in: 0, wantErr: true},
+		{in: 3, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		o, _ := enrollment.NewOption()
+		err := o.SetConfirmations(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("SetConfirmations(%d)=%#v; want error %t", tt.in, err, tt.wantErr)
+		}
+	}
+}
+
+func TestOption_SetSkew(t *testing.T) {
+	o, _ := enrollment.NewOption()
+	if err := o.SetSkew(2); err != nil || o.Skew() != 2 {
+		t.Errorf("SetSkew(2)=%#v; want nil, skew %d", err, o.Skew())
+	}
+	if err := o.SetSkew(0); err == nil {
+		t.Errorf("SetSkew(0)=nil; want error")
+	}
+}
+
+func TestOption_ErrOptionIsNil(t *testing.T) {
+	var o *enrollment.Option
+	if err := o.SetTTL(time.Minute); err != enrollment.ErrEnrollmentOptionIsNil {
+		t.Errorf("SetTTL()=%#v; want %v", err, enrollment.ErrEnrollmentOptionIsNil)
+	}
+	if err := o.SetConfirmations(1); err != enrollment.ErrEnrollmentOptionIsNil {
+		t.Errorf("SetConfirmations()=%#v; want %v", err, enrollment.ErrEnrollmentOptionIsNil)
+	}
+	if err := o.SetSkew(1); err != enrollment.ErrEnrollmentOptionIsNil {
+		t.Errorf("SetSkew()=%#v; want %v", err, enrollment.ErrEnrollmentOptionIsNil)
+	}
+	if err := o.SetOtpAuthOption(nil); err != enrollment.ErrEnrollmentOptionIsNil {
+		t.Errorf("SetOtpAuthOption()=%#v; want %v", err, enrollment.ErrEnrollmentOptionIsNil)
+	}
+}
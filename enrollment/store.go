@@ -0,0 +1,103 @@
+package enrollment
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Store persists enrollments
+// The state machine of an enrollment is driven through the store, so a store must update an enrollment atomically
+type Store interface {
+	// Create saves the new enrollment
+	Create(ctx context.Context, e *Enrollment) error
+	// Get returns the enrollment of the identifier
+	// This returns ErrEnrollmentNotFound when the enrollment doesn't exist
+	Get(ctx context.Context, id string) (*Enrollment, error)
+	// Update replaces the enrollment only when the stored revision equals the revision of e, and increments the revision
+	// This returns ErrConflict when the enrollment has been updated by another request
+	Update(ctx context.Context, e *Enrollment) error
+	// Expire marks the pending enrollments that expire at or before the time expired, and returns the count of them
+	Expire(ctx context.Context, t time.Time) (int, error)
+}
+
+// MemoryStore is a Store that holds enrollments in memory
+type MemoryStore struct {
+	mu          sync.Mutex
+	enrollments map[string]Enrollment
+}
+
+// NewMemoryStore generates an empty store in memory
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		enrollments: map[string]Enrollment{},
+	}
+}
+
+// Create saves the new enrollment
+func (s *MemoryStore) Create(_ context.Context, e *Enrollment) error {
+	if e == nil {
+		return errors.New("enrollment is nil")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.enrollments[e.ID]; ok {
+		return errors.New("enrollment already exists")
+	}
+	s.enrollments[e.ID] = *e
+	return nil
+}
+
+// Get returns the enrollment of the identifier
+func (s *MemoryStore) Get(_ context.Context, id string) (*Enrollment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.enrollments[id]
+	if !ok {
+		return nil, ErrEnrollmentNotFound
+	}
+	return &e, nil
+}
+
+// Update replaces the enrollment only when the stored revision equals the revision of e
+func (s *MemoryStore) Update(_ context.Context, e *Enrollment) error {
+	if e == nil {
+		return errors.New("enrollment is nil")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.enrollments[e.ID]
+	if !ok {
+		return ErrEnrollmentNotFound
+	}
+	if stored.Revision != e.Revision {
+		return ErrConflict
+	}
+	e.Revision++
+	s.enrollments[e.ID] = *e
+	return nil
+}
+
+// Expire marks the pending enrollments that expire at or before the time expired
+func (s *MemoryStore) Expire(_ context.Context, t time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := 0
+	for id, e := range s.enrollments {
+		if !e.expired(t) {
+			continue
+		}
+		e.State = StateExpired
+		e.Revision++
+		s.enrollments[id] = e
+		n++
+	}
+	return n, nil
+}
@@ -0,0 +1,208 @@
+package exporter
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/scrypt"
+
+	"github.com/butterv/one-time-password/otpauth"
+)
+
+const (
+	aegisVaultVersion = 1
+	aegisDBVersion    = 2
+	// aegisSlotPassword is the type of the slot whose key is derived from the password
+	aegisSlotPassword = 1
+	aegisKeySize      = 32
+	aegisNonceSize    = 12
+	aegisTagSize      = 16
+)
+
+type aegisVault struct {
+	Version int         `json:"version"`
+	Header  aegisHeader `json:"header"`
+	DB      interface{} `json:"db"`
+}
+
+type aegisHeader struct {
+	Slots  []aegisSlot  `json:"slots"`
+	Params *aegisParams `json:"params"`
+}
+
+type aegisSlot struct {
+	Type      int         `json:"type"`
+	UUID      string      `json:"uuid"`
+	Key       string      `json:"key"`
+	KeyParams aegisParams `json:"key_params"`
+	N         int         `json:"n"`
+	R         int         `json:"r"`
+	P         int         `json:"p"`
+	Salt      string      `json:"salt"`
+	Repaired  bool        `json:"repaired"`
+	IsBackup  bool        `json:"is_backup"`
+}
+
+type aegisParams struct {
+	Nonce string `json:"nonce"`
+	Tag   string `json:"tag"`
+}
+
+type aegisDB struct {
+	Version int          `json:"version"`
+	Entries []aegisEntry `json:"entries"`
+}
+
+type aegisEntry struct {
+	Type   string      `json:"type"`
+	UUID   string      `json:"uuid"`
+	Name   string      `json:"name"`
+	Issuer string      `json:"issuer"`
+	Note   string      `json:"note"`
+	Icon   interface{} `json:"icon"`
+	Info   aegisInfo   `json:"info"`
+}
+
+type aegisInfo struct {
+	Secret  string  `json:"secret"`
+	Algo    string  `json:"algo"`
+	Digits  int     `json:"digits"`
+	Period  *uint   `json:"period,omitempty"`
+	Counter *uint64 `json:"counter,omitempty"`
+}
+
+// ExportAegis writes the keys in the JSON of Aegis Authenticator
+// When the passphrase is set, the entries are encrypted with a master key in a password slot as Aegis does
+func ExportAegis(w io.Writer, keys []*otpauth.Key, opt *Option) error {
+	keys, err := normalize(keys, opt, true)
+	if err != nil {
+		return err
+	}
+
+	db := aegisDB{Version: aegisDBVersion, Entries: make([]aegisEntry, 0, len(keys))}
+	for _, k := range keys {
+		id, err := newUUID(opt.rand)
+		if err != nil {
+			return err
+		}
+		e := aegisEntry{
+			Type:   k.Host.String(),
+			UUID:   id,
+			Name:   k.AccountName,
+			Issuer: k.Issuer,
+			Info: aegisInfo{
+				Secret: k.Secret,
+				Algo:   k.Algorithm.String(),
+				Digits: int(k.Digits),
+			},
+		}
+		switch k.Host {
+		case otpauth.HostTOTP:
+			period := k.Period
+			e.Info.Period = &period
+		case otpauth.HostHOTP:
+			counter := k.Counter
+			e.Info.Counter = &counter
+		}
+		db.Entries = append(db.Entries, e)
+	}
+
+	v := aegisVault{Version: aegisVaultVersion, DB: db}
+	if opt.encrypted() {
+		if err := v.encrypt(db, opt); err != nil {
+			return err
+		}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "    ")
+	return enc.Encode(v)
+}
+
+// encrypt encrypts the database with a new master key and seals the master key in a password slot
+func (v *aegisVault) encrypt(db aegisDB, opt *Option) error {
+	plaintext, err := json.Marshal(db)
+	if err != nil {
+		return err
+	}
+
+	masterKey := make([]byte, aegisKeySize)
+	salt := make([]byte, aegisKeySize)
+	for _, b := range [][]byte{masterKey, salt} {
+		if _, err := io.ReadFull(opt.rand, b); err != nil {
+			return err
+		}
+	}
+	key, err := scrypt.Key(opt.passphrase, salt, opt.scryptN, opt.scryptR, opt.scryptP, aegisKeySize)
+	if err != nil {
+		return err
+	}
+
+	encryptedKey, keyParams, err := sealAESGCM(key, masterKey, opt.rand)
+	if err != nil {
+		return err
+	}
+	ciphertext, params, err := sealAESGCM(masterKey, plaintext, opt.rand)
+	if err != nil {
+		return err
+	}
+	id, err := newUUID(opt.rand)
+	if err != nil {
+		return err
+	}
+
+	v.Header = aegisHeader{
+		Slots: []aegisSlot{{
+			Type:      aegisSlotPassword,
+			UUID:      id,
+			Key:       hex.EncodeToString(encryptedKey),
+			KeyParams: keyParams,
+			N:         opt.scryptN,
+			R:         opt.scryptR,
+			P:         opt.scryptP,
+			Salt:      hex.EncodeToString(salt),
+			Repaired:  true,
+		}},
+		Params: &params,
+	}
+	v.DB = base64.StdEncoding.EncodeToString(ciphertext)
+	return nil
+}
+
+// sealAESGCM encrypts the plaintext and returns the ciphertext with the separated nonce and authentication tag
+func sealAESGCM(key, plaintext []byte, rand io.Reader) ([]byte, aegisParams, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, aegisParams{}, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, aegisParams{}, err
+	}
+	nonce := make([]byte, aegisNonceSize)
+	if _, err := io.ReadFull(rand, nonce); err != nil {
+		return nil, aegisParams{}, err
+	}
+
+	sealed := aead.Seal(nil, nonce, plaintext, nil)
+	ciphertext, tag := sealed[:len(sealed)-aegisTagSize], sealed[len(sealed)-aegisTagSize:]
+	return ciphertext, aegisParams{Nonce: hex.EncodeToString(nonce), Tag: hex.EncodeToString(tag)}, nil
+}
+
+// newUUID returns a random UUID of version 4
+// See: https://tools.ietf.org/html/rfc4122#section-4.4
+func newUUID(rand io.Reader) (string, error) {
+	b := make([]byte, 16)
+	if _, err := io.ReadFull(rand, b); err != nil {
+		return "", err
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
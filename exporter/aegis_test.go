@@ -0,0 +1,71 @@
+package exporter_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/butterv/one-time-password/exporter"
+	"github.com/butterv/one-time-password/importer"
+)
+
+func TestExportAegis(t *testing.T) {
+	var b bytes.Buffer
+	if err := exporter.ExportAegis(&b, testKeys(), exporter.NewOption()); err != nil {
+		t.Fatalf("ExportAegis()=%#v; want nil", err)
+	}
+
+	var v struct {
+		DB struct {
+			Entries []struct {
+				UUID string                 `json:"uuid"`
+				Info map[string]interface{} `json:"info"`
+			} `json:"entries"`
+		} `json:"db"`
+	}
+	_ = json.Unmarshal(b.Bytes(), &v)
+	uuid := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	for _, e := range v.DB.Entries {
+		if !uuid.MatchString(e.UUID) {
+			t.Errorf("entry has uuid %s; want UUID of version 4", e.UUID)
+		}
+	}
+	if _, ok := v.DB.Entries[1].Info["period"]; ok {
+		t.Errorf("hotp entry has period %v", v.DB.Entries[1].Info)
+	}
+
+	res, err := importer.ImportAegis(&b, nil)
+	if err != nil {
+		t.Fatalf("ImportAegis()=_, %#v; want nil", err)
+	}
+	if want := normalizedKeys(); !reflect.DeepEqual(res.Keys, want) {
+		t.Errorf("ImportAegis() imports %v; want %v", res.Keys, want)
+	}
+}
+
+func TestExportAegis_Encrypted(t *testing.T) {
+	o := exporter.NewOption()
+	_ = o.SetPassphrase([]byte("test"))
+	_ = o.SetScryptParams(1<<10, 8, 1)
+
+	var b bytes.Buffer
+	if err := exporter.ExportAegis(&b, testKeys(), o); err != nil {
+		t.Fatalf("ExportAegis()=%#v; want nil", err)
+	}
+	if bytes.Contains(b.Bytes(), []byte(rfcSecret)) {
+		t.Errorf("ExportAegis() writes the secret in plaintext")
+	}
+
+	if _, err := importer.ImportAegis(bytes.NewReader(b.Bytes()), []byte("wrong")); err != importer.ErrInvalidPassphrase {
+		t.Errorf("ImportAegis(_, wrong)=_, %#v; want %v", err, importer.ErrInvalidPassphrase)
+	}
+	res, err := importer.ImportAegis(&b, []byte("test"))
+	if err != nil {
+		t.Fatalf("ImportAegis(_, test)=_, %#v; want nil", err)
+	}
+	if want := normalizedKeys(); !reflect.DeepEqual(res.Keys, want) {
+		t.Errorf("ImportAegis(_, test) imports %v; want %v", res.Keys, want)
+	}
+}
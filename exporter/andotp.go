@@ -0,0 +1,98 @@
+package exporter
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+
+	"github.com/butterv/one-time-password/otpauth"
+)
+
+const (
+	andOTPSaltSize  = 12
+	andOTPNonceSize = 12
+	andOTPKeySize   = 32
+)
+
+type andOTPEntry struct {
+	Secret        string   `json:"secret"`
+	Issuer        string   `json:"issuer"`
+	Label         string   `json:"label"`
+	Digits        int      `json:"digits"`
+	Type          string   `json:"type"`
+	Algorithm     string   `json:"algorithm"`
+	Thumbnail     string   `json:"thumbnail"`
+	LastUsed      int64    `json:"last_used"`
+	UsedFrequency int      `json:"used_frequency"`
+	Period        *uint    `json:"period,omitempty"`
+	Counter       *uint64  `json:"counter,omitempty"`
+	Tags          []string `json:"tags"`
+}
+
+// ExportAndOTP writes the keys in the JSON of andOTP
+// When the passphrase is set, the JSON is encrypted as the `.json.aes` backup of andOTP
+// The encrypted backup is the iteration count in 4 bytes, the salt, the nonce and the ciphertext of AES-256-GCM under the key of PBKDF2 with HMAC-SHA1
+func ExportAndOTP(w io.Writer, keys []*otpauth.Key, opt *Option) error {
+	keys, err := normalize(keys, opt, true)
+	if err != nil {
+		return err
+	}
+
+	entries := make([]andOTPEntry, 0, len(keys))
+	for _, k := range keys {
+		e := andOTPEntry{
+			Secret:    k.Secret,
+			Issuer:    k.Issuer,
+			Label:     k.AccountName,
+			Digits:    int(k.Digits),
+			Type:      strings.ToUpper(k.Host.String()),
+			Algorithm: k.Algorithm.String(),
+			Thumbnail: "Default",
+			Tags:      []string{},
+		}
+		switch k.Host {
+		case otpauth.HostTOTP:
+			period := k.Period
+			e.Period = &period
+		case otpauth.HostHOTP:
+			counter := k.Counter
+			e.Counter = &counter
+		}
+		entries = append(entries, e)
+	}
+
+	b, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+	if !opt.encrypted() {
+		_, err = w.Write(b)
+		return err
+	}
+
+	header := make([]byte, 4+andOTPSaltSize+andOTPNonceSize)
+	binary.BigEndian.PutUint32(header, uint32(opt.pbkdf2Iterations))
+	salt, nonce := header[4:4+andOTPSaltSize], header[4+andOTPSaltSize:]
+	if _, err := io.ReadFull(opt.rand, header[4:]); err != nil {
+		return err
+	}
+	key := pbkdf2.Key(opt.passphrase, salt, opt.pbkdf2Iterations, andOTPKeySize, sha1.New)
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(aead.Seal(header, nonce, b, nil))
+	return err
+}
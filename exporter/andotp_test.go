@@ -0,0 +1,81 @@
+package exporter_test
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha1"
+	"encoding/binary"
+	"reflect"
+	"testing"
+
+	"golang.org/x/crypto/pbkdf2"
+
+	"github.com/butterv/one-time-password/exporter"
+	"github.com/butterv/one-time-password/importer"
+	"github.com/butterv/one-time-password/otpauth"
+)
+
+// normalizedKeys returns testKeys with the normalized secret
+func normalizedKeys() []*otpauth.Key {
+	keys := testKeys()
+	keys[1].Secret = rfcSecret
+	return keys
+}
+
+func TestExportAndOTP(t *testing.T) {
+	var b bytes.Buffer
+	if err := exporter.ExportAndOTP(&b, testKeys(), exporter.NewOption()); err != nil {
+		t.Fatalf("ExportAndOTP()=%#v; want nil", err)
+	}
+
+	res, err := importer.ImportAndOTP(&b, nil)
+	if err != nil {
+		t.Fatalf("ImportAndOTP()=_, %#v; want nil", err)
+	}
+	if want := normalizedKeys(); !reflect.DeepEqual(res.Keys, want) {
+		t.Errorf("ImportAndOTP() imports %v; want %v", res.Keys, want)
+	}
+}
+
+func TestExportAndOTP_Encrypted(t *testing.T) {
+	o := exporter.NewOption()
+	_ = o.SetPassphrase([]byte("test"))
+	_ = o.SetPBKDF2Iterations(1000)
+
+	var b bytes.Buffer
+	if err := exporter.ExportAndOTP(&b, testKeys(), o); err != nil {
+		t.Fatalf("ExportAndOTP()=%#v; want nil", err)
+	}
+
+	// decrypt as andOTP does
+	data := b.Bytes()
+	iterations := int(binary.BigEndian.Uint32(data[:4]))
+	if iterations != 1000 {
+		t.Errorf("ExportAndOTP() writes iterations %d; want 1000", iterations)
+	}
+	salt, nonce, ciphertext := data[4:16], data[16:28], data[28:]
+	block, _ := aes.NewCipher(pbkdf2.Key([]byte("test"), salt, iterations, 32, sha1.New))
+	aead, _ := cipher.NewGCM(block)
+	plaintext, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		t.Fatalf("Open()=_, %#v; want nil", err)
+	}
+
+	res, err := importer.ImportAndOTP(bytes.NewReader(plaintext), nil)
+	if err != nil {
+		t.Fatalf("ImportAndOTP()=_, %#v; want nil", err)
+	}
+	if want := normalizedKeys(); !reflect.DeepEqual(res.Keys, want) {
+		t.Errorf("ImportAndOTP() imports %v; want %v", res.Keys, want)
+	}
+
+	// the backup is read back by the importer
+	res, err = importer.ImportAndOTP(bytes.NewReader(data), []byte("test"))
+	if err != nil {
+		t.Fatalf("ImportAndOTP(_, test)=_, %#v; want nil", err)
+	}
+	if want := normalizedKeys(); !reflect.DeepEqual(res.Keys, want) {
+		t.Errorf("ImportAndOTP(_, test) imports %v; want %v", res.Keys, want)
+	}
+}
@@ -0,0 +1,13 @@
+package exporter
+
+func (opt *Option) Passphrase() []byte {
+	return opt.passphrase
+}
+
+func (opt *Option) ScryptParams() (int, int, int) {
+	return opt.scryptN, opt.scryptR, opt.scryptP
+}
+
+func (opt *Option) PBKDF2Iterations() int {
+	return opt.pbkdf2Iterations
+}
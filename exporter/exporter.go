@@ -0,0 +1,139 @@
+package exporter
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/butterv/one-time-password/otpauth"
+)
+
+// ErrEncryptionNotSupported is an error when the passphrase is set for a format without encryption
+var ErrEncryptionNotSupported = errors.New("the format doesn't support encryption")
+
+// Format is the format of an export
+type Format int
+
+const (
+	// FormatAegis is the JSON of Aegis Authenticator, which is encrypted with the passphrase
+	// See: https://github.com/beemdevelopment/Aegis/blob/master/docs/vault.md
+	FormatAegis Format = iota
+	// FormatAndOTP is the JSON of andOTP, which is encrypted with the passphrase
+	FormatAndOTP
+	// FormatURIList is the otpauth URIs separated by new lines
+	FormatURIList
+	// FormatCSV is the CSV with a header of the parameters of the keys
+	FormatCSV
+)
+
+// String returns the name of the format
+func (f Format) String() string {
+	switch f {
+	case FormatAegis:
+		return "aegis"
+	case FormatAndOTP:
+		return "andotp"
+	case FormatURIList:
+		return "uri"
+	case FormatCSV:
+		return "csv"
+	}
+
+	return "unknown"
+}
+
+// csvHeader is the header of the CSV
+var csvHeader = []string{"type", "issuer", "account", "secret", "algorithm", "digits", "period", "counter"}
+
+// Export writes the keys in the format
+func Export(w io.Writer, f Format, keys []*otpauth.Key, opt *Option) error {
+	switch f {
+	case FormatAegis:
+		return ExportAegis(w, keys, opt)
+	case FormatAndOTP:
+		return ExportAndOTP(w, keys, opt)
+	case FormatURIList:
+		return ExportURIList(w, keys, opt)
+	case FormatCSV:
+		return ExportCSV(w, keys, opt)
+	}
+
+	return fmt.Errorf("invalid format. please pass any of %d to %d", FormatAegis, FormatCSV)
+}
+
+// ExportURIList writes the otpauth URIs of the keys separated by new lines
+func ExportURIList(w io.Writer, keys []*otpauth.Key, opt *Option) error {
+	keys, err := normalize(keys, opt, false)
+	if err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+	for _, k := range keys {
+		uri, _ := k.URL()
+		if _, err := fmt.Fprintln(bw, uri); err != nil {
+			return err
+		}
+	}
+
+	return bw.Flush()
+}
+
+// ExportCSV writes the parameters of the keys in CSV with a header
+// The period is empty for HOTP and the counter is empty for TOTP
+func ExportCSV(w io.Writer, keys []*otpauth.Key, opt *Option) error {
+	keys, err := normalize(keys, opt, false)
+	if err != nil {
+		return err
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, k := range keys {
+		var period, counter string
+		switch k.Host {
+		case otpauth.HostTOTP:
+			period = strconv.FormatUint(uint64(k.Period), 10)
+		case otpauth.HostHOTP:
+			counter = strconv.FormatUint(k.Counter, 10)
+		}
+		record := []string{k.Host.String(), k.Issuer, k.AccountName, k.Secret, k.Algorithm.String(), strconv.Itoa(int(k.Digits)), period, counter}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+
+	return cw.Error()
+}
+
+// normalize validates the option and returns the keys normalized through their otpauth URIs
+// The keys are validated before anything is written, so an invalid key never leaves a partial export
+func normalize(keys []*otpauth.Key, opt *Option, encryption bool) ([]*otpauth.Key, error) {
+	if opt == nil {
+		return nil, ErrExportOptionIsNil
+	}
+	if opt.encrypted() && !encryption {
+		return nil, ErrEncryptionNotSupported
+	}
+
+	normalized := make([]*otpauth.Key, 0, len(keys))
+	for i, k := range keys {
+		uri, err := k.URL()
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %d: %w", i, err)
+		}
+		n, err := otpauth.ParseURL(uri)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %d: %w", i, err)
+		}
+		normalized = append(normalized, n)
+	}
+
+	return normalized, nil
+}
@@ -0,0 +1,117 @@
+package exporter_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/butterv/one-time-password/exporter"
+	"github.com/butterv/one-time-password/otpauth"
+)
+
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func testKeys() []*otpauth.Key {
+	return []*otpauth.Key{
+		{
+			Host:        otpauth.HostTOTP,
+			Issuer:      "butter",
+			AccountName: "butter@example.com",
+			Secret:      rfcSecret,
+			Algorithm:   otpauth.AlgorithmSHA1,
+			Digits:      otpauth.DigitsSix,
+			Period:      30,
+		},
+		{
+			Host:        otpauth.HostHOTP,
+			Issuer:      "ci",
+			AccountName: "deploy",
+			// the secret is normalized
+			Secret:    "gezd gnbv gy3t qojq gezd gnbv gy3t qojq",
+			Algorithm: otpauth.AlgorithmSHA256,
+			Digits:    otpauth.DigitsEight,
+			Period:    30,
+			Counter:   5,
+		},
+	}
+}
+
+func TestExportURIList(t *testing.T) {
+	want := "otpauth://totp/butter:butter@example.com?algorithm=SHA1&digits=6&issuer=butter&period=30&secret=" + rfcSecret + "\n" +
+		"otpauth://hotp/ci:deploy?algorithm=SHA256&counter=5&digits=8&issuer=ci&secret=" + rfcSecret + "\n"
+
+	var b bytes.Buffer
+	if err := exporter.ExportURIList(&b, testKeys(), exporter.NewOption()); err != nil {
+		t.Fatalf("ExportURIList()=%#v; want nil", err)
+	}
+	if got := b.String(); got != want {
+		t.Errorf("ExportURIList() writes %q; want %q", got, want)
+	}
+
+	for _, line := range bytes.Split(bytes.TrimSpace(b.Bytes()), []byte("\n")) {
+		if _, err := otpauth.ParseURL(string(line)); err != nil {
+			t.Errorf("ParseURL(%s)=_, %#v; want nil", line, err)
+		}
+	}
+}
+
+func TestExportCSV(t *testing.T) {
+	want := "type,issuer,account,secret,algorithm,digits,period,counter\n" +
+		"totp,butter,butter@example.com," + rfcSecret + ",SHA1,6,30,\n" +
+		"hotp,ci,deploy," + rfcSecret + ",SHA256,8,,5\n"
+
+	var b bytes.Buffer
+	if err := exporter.ExportCSV(&b, testKeys(), exporter.NewOption()); err != nil {
+		t.Fatalf("ExportCSV()=%#v; want nil", err)
+	}
+	if got := b.String(); got != want {
+		t.Errorf("ExportCSV() writes %q; want %q", got, want)
+	}
+}
+
+func TestExport_EncryptionNotSupported(t *testing.T) {
+	o := exporter.NewOption()
+	_ = o.SetPassphrase([]byte("test"))
+
+	for _, f := range []exporter.Format{exporter.FormatURIList, exporter.FormatCSV} {
+		var b bytes.Buffer
+		if err := exporter.Export(&b, f, testKeys(), o); err != exporter.ErrEncryptionNotSupported {
+			t.Errorf("Export(_, %v, _, _)=%#v; want %v", f, err, exporter.ErrEncryptionNotSupported)
+		}
+		if b.Len() != 0 {
+			t.Errorf("Export(_, %v, _, _) writes %q; want nothing", f, b.String())
+		}
+	}
+}
+
+func TestExport_InvalidKey(t *testing.T) {
+	keys := append(testKeys(), &otpauth.Key{Host: otpauth.HostTOTP, AccountName: "broken", Secret: "not base32!", Algorithm: otpauth.AlgorithmSHA1, Digits: otpauth.DigitsSix, Period: 30})
+
+	for f := exporter.FormatAegis; f <= exporter.FormatCSV; f++ {
+		var b bytes.Buffer
+		if err := exporter.Export(&b, f, keys, exporter.NewOption()); err == nil {
+			t.Errorf("Export(_, %v, _, _)=nil; want error", f)
+		}
+		if b.Len() != 0 {
+			t.Errorf("Export(_, %v, _, _) writes %q; want nothing", f, b.String())
+		}
+	}
+}
+
+func TestExport_InvalidArgs(t *testing.T) {
+	var b bytes.Buffer
+	if err := exporter.Export(&b, exporter.Format(4), testKeys(), exporter.NewOption()); err == nil {
+		t.Error("Export(_, 4, _, _)=nil; want error")
+	}
+	if err := exporter.Export(&b, exporter.FormatCSV, testKeys(), nil); err != exporter.ErrExportOptionIsNil {
+		t.Errorf("Export(_, _, _, nil)=%#v; want %v", err, exporter.ErrExportOptionIsNil)
+	}
+}
+
+func TestFormat_String(t *testing.T) {
+	want := []string{"aegis", "andotp", "uri", "csv", "unknown"}
+	for i, w := range want {
+		if got := exporter.Format(i).String(); got != w {
+			t.Errorf("Format(%d).String()=%s; want %s", i, got, w)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package exporter
+
+import (
+	crand "crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const (
+	defaultScryptN          = 1 << 15
+	defaultScryptR          = 8
+	defaultScryptP          = 1
+	defaultPBKDF2Iterations = 150000
+	// The maximum parameters are the ones that the importer accepts, so the backups are read back
+	maxScryptN          = 1 << 20
+	maxScryptR          = 8
+	maxScryptP          = 16
+	maxPBKDF2Iterations = 10000000
+)
+
+// ErrExportOptionIsNil is an error when the export option is nil
+var ErrExportOptionIsNil = errors.New("export option is nil")
+
+// Option is used when exports keys
+type Option struct {
+	// passphrase encrypts the backup when it isn't empty
+	// The default value is empty, which means the backup isn't encrypted
+	passphrase []byte
+	// scryptN, scryptR and scryptP are the parameters of scrypt that derives the key of an Aegis backup
+	scryptN, scryptR, scryptP int
+	// pbkdf2Iterations is the iteration count of PBKDF2 that derives the key of an andOTP backup
+	pbkdf2Iterations int
+	// rand is the reader to use for generating keys, salts, nonces and UUIDs
+	rand io.Reader
+}
+
+// SetPassphrase sets a passphrase that encrypts the backup
+// Only Aegis and andOTP support encryption
+func (opt *Option) SetPassphrase(passphrase []byte) error {
+	if opt == nil {
+		return ErrExportOptionIsNil
+	}
+	if len(passphrase) == 0 {
+		return errors.New("passphrase is empty")
+	}
+
+	opt.passphrase = make([]byte, len(passphrase))
+	copy(opt.passphrase, passphrase)
+	return nil
+}
+
+// SetScryptParams sets the CPU/memory cost N, the block size r and the parallelization p of scrypt for Aegis
+func (opt *Option) SetScryptParams(n, r, p int) error {
+	if opt == nil {
+		return ErrExportOptionIsNil
+	}
+	if n <= 1 || n > maxScryptN || n&(n-1) != 0 {
+		return fmt.Errorf("invalid scrypt N. please pass a power of 2 greater than 1 up to %d", maxScryptN)
+	}
+	if r <= 0 || r > maxScryptR || p <= 0 || p > maxScryptP {
+		return fmt.Errorf("invalid scrypt r or p. please pass r of 1 to %d and p of 1 to %d", maxScryptR, maxScryptP)
+	}
+
+	opt.scryptN, opt.scryptR, opt.scryptP = n, r, p
+	return nil
+}
+
+// SetPBKDF2Iterations sets the iteration count of PBKDF2 for andOTP
+func (opt *Option) SetPBKDF2Iterations(iterations int) error {
+	if opt == nil {
+		return ErrExportOptionIsNil
+	}
+	if iterations <= 0 || iterations > maxPBKDF2Iterations {
+		return fmt.Errorf("invalid pbkdf2 iterations. please pass 1 to %d", maxPBKDF2Iterations)
+	}
+
+	opt.pbkdf2Iterations = iterations
+	return nil
+}
+
+// NewOption generates an option with default values
+func NewOption() *Option {
+	return &Option{
+		scryptN:          defaultScryptN,
+		scryptR:          defaultScryptR,
+		scryptP:          defaultScryptP,
+		pbkdf2Iterations: defaultPBKDF2Iterations,
+		rand:             crand.Reader,
+	}
+}
+
+func (opt *Option) encrypted() bool {
+	return len(opt.passphrase) > 0
+}
@@ -0,0 +1,69 @@
+package exporter_test
+
+import (
+	"testing"
+
+	"github.com/butterv/one-time-password/exporter"
+)
+
+func TestNewOption(t *testing.T) {
+	o := exporter.NewOption()
+	if len(o.Passphrase()) != 0 {
+		t.Errorf("NewOption() has passphrase %q; want empty", o.Passphrase())
+	}
+	if n, r, p := o.ScryptParams(); n != 1<<15 || r != 8 || p != 1 {
+		t.Errorf("NewOption() has scrypt params %d, %d, %d; want 32768, 8, 1", n, r, p)
+	}
+	if got := o.PBKDF2Iterations(); got != 150000 {
+		t.Errorf("NewOption() has pbkdf2 iterations %d; want 150000", got)
+	}
+}
+
+func TestOption_SetPassphrase(t *testing.T) {
+	o := exporter.NewOption()
+	if err := o.SetPassphrase([]byte("test")); err != nil || string(o.Passphrase()) != "test" {
+		t.Errorf("SetPassphrase(test)=%#v; want nil", err)
+	}
+	if err := o.SetPassphrase(nil); err == nil {
+		t.Error("SetPassphrase(nil)=nil; want error")
+	}
+
+	var nilOpt *exporter.Option
+	if err := nilOpt.SetPassphrase([]byte("test")); err != exporter.ErrExportOptionIsNil {
+		t.Errorf("SetPassphrase(test)=%#v; want %v", err, exporter.ErrExportOptionIsNil)
+	}
+}
+
+func TestOption_SetScryptParams(t *testing.T) {
+	tests := []struct {
+		n, r, p int
+		wantErr bool
+	}{
+		{n: 1 << 10, r: 8, p: 1, wantErr: false},
+		{n: 1000, r: 8, p: 1, wantErr: true},
+		{n: 1 << 10, r: 0, p: 1, wantErr: true},
+		{n: 1 << 21, r: 8, p: 1, wantErr: true},
+		{n: 1 << 10, r: 9, p: 1, wantErr: true},
+		{n: 1 << 10, r: 8, p: 17, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		o := exporter.NewOption()
+		if err := o.SetScryptParams(tt.n, tt.r, tt.p); (err != nil) != tt.wantErr {
+			t.Errorf("SetScryptParams(%d, %d, %d)=%#v; want error %t", tt.n, tt.r, tt.p, err, tt.wantErr)
+		}
+	}
+}
+
+func TestOption_SetPBKDF2Iterations(t *testing.T) {
+	o := exporter.NewOption()
+	if err := o.SetPBKDF2Iterations(1000); err != nil || o.PBKDF2Iterations() != 1000 {
+		t.Errorf("SetPBKDF2Iterations(1000)=%#v; want nil", err)
+	}
+	if err := o.SetPBKDF2Iterations(0); err == nil {
+		t.Error("SetPBKDF2Iterations(0)=nil; want error")
+	}
+	if err := o.SetPBKDF2Iterations(10000001); err == nil {
+		t.Error("SetPBKDF2Iterations(10000001)=nil; want error")
+	}
+}
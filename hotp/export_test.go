@@ -22,5 +22,6 @@ func DefaultOption() *Option {
 	return &Option{
 		digits:    6,
 		algorithm: 0,
+		policy:    otpauth.NewPolicy(),
 	}
 }
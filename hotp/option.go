@@ -18,6 +18,9 @@ type Option struct {
 	// algorithm is the hash function to use in the HMAC operation
 	// The default value is SHA1
 	algorithm otpauth.Algorithm
+	// policy validates the strength of the option
+	// The default value flags weak configurations
+	policy *otpauth.Policy
 }
 
 // SetDigits sets the number of digits
@@ -41,15 +44,46 @@ func (opt *Option) SetAlgorithm(a otpauth.Algorithm) error {
 	if !a.Enabled() {
 		return fmt.Errorf("invalid algorithm. please pass any of %d to %d", otpauth.AlgorithmSHA1, otpauth.AlgorithmMD5)
 	}
+	if err := opt.policy.Enforce(opt.policy.CheckAlgorithm(a)); err != nil {
+		return err
+	}
 
 	opt.algorithm = a
 	return nil
 }
 
+// SetPolicy sets a policy that validates the strength of the option
+// Passing nil disables the validation
+func (opt *Option) SetPolicy(p *otpauth.Policy) error {
+	if opt == nil {
+		return ErrHOTPOptionIsNil
+	}
+	if err := p.Enforce(opt.warnings(p)); err != nil {
+		return err
+	}
+
+	opt.policy = p
+	return nil
+}
+
+// Warnings returns weak configurations of the option flagged by the policy
+func (opt *Option) Warnings() []otpauth.Warning {
+	if opt == nil {
+		return nil
+	}
+
+	return opt.warnings(opt.policy)
+}
+
+func (opt *Option) warnings(p *otpauth.Policy) []otpauth.Warning {
+	return p.CheckAlgorithm(opt.algorithm)
+}
+
 // NewOption generates an option with default values
 func NewOption() *Option {
 	return &Option{
 		digits:    otpauth.DigitsSix,
 		algorithm: otpauth.AlgorithmSHA1,
+		policy:    otpauth.NewPolicy(),
 	}
 }
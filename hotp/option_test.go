@@ -101,3 +101,27 @@ func TestNewOption(t *testing.T) {
 		t.Errorf("NewOption()=%#v; want %v", got, want)
 	}
 }
+
+func TestOption_SetAlgorithm_StrictPolicy(t *testing.T) {
+	o := hotp.NewOption()
+	_ = o.SetPolicy(otpauth.NewStrictPolicy())
+
+	err := o.SetAlgorithm(otpauth.AlgorithmMD5)
+	var policyErr *otpauth.PolicyError
+	if !errors.As(err, &policyErr) {
+		t.Errorf("SetAlgorithm(%d)=%#v; want *PolicyError", otpauth.AlgorithmMD5, err)
+	}
+}
+
+func TestOption_Warnings(t *testing.T) {
+	want := []otpauth.Warning{
+		{Parameter: "algorithm", Message: "MD5 isn't permitted by RFC 4226 and RFC 6238", Severity: otpauth.SeverityWeak},
+	}
+
+	o := hotp.NewOption()
+	_ = o.SetAlgorithm(otpauth.AlgorithmMD5)
+
+	if got := o.Warnings(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Warnings()=%v; want %v", got, want)
+	}
+}
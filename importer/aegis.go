@@ -0,0 +1,206 @@
+package importer
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/scrypt"
+
+	"github.com/butterv/one-time-password/otpauth"
+)
+
+// aegisSlotPassword is the type of the slot whose key is derived from the password
+const aegisSlotPassword = 1
+
+// The bounds of the scrypt parameters of a password slot
+// The parameters come from the backup, so they are bounded before the key is derived,
+// which takes 128 * N * r bytes of memory, up to 1 GiB
+// Aegis Authenticator uses N of 2^15, r of 8 and p of 1
+const (
+	maxAegisScryptN = 1 << 20
+	maxAegisScryptR = 8
+	maxAegisScryptP = 16
+)
+
+type aegisVault struct {
+	Version int             `json:"version"`
+	Header  aegisHeader     `json:"header"`
+	DB      json.RawMessage `json:"db"`
+}
+
+type aegisHeader struct {
+	Slots  []aegisSlot  `json:"slots"`
+	Params *aegisParams `json:"params"`
+}
+
+type aegisSlot struct {
+	Type      int         `json:"type"`
+	Key       string      `json:"key"`
+	KeyParams aegisParams `json:"key_params"`
+	N         int         `json:"n"`
+	R         int         `json:"r"`
+	P         int         `json:"p"`
+	Salt      string      `json:"salt"`
+}
+
+type aegisParams struct {
+	Nonce string `json:"nonce"`
+	Tag   string `json:"tag"`
+}
+
+type aegisDB struct {
+	Version int          `json:"version"`
+	Entries []aegisEntry `json:"entries"`
+}
+
+type aegisEntry struct {
+	Type   string `json:"type"`
+	Name   string `json:"name"`
+	Issuer string `json:"issuer"`
+	Info   struct {
+		Secret  string `json:"secret"`
+		Algo    string `json:"algo"`
+		Digits  int    `json:"digits"`
+		Period  uint   `json:"period"`
+		Counter uint64 `json:"counter"`
+	} `json:"info"`
+}
+
+// ImportAegis imports the JSON backup of Aegis Authenticator
+// An encrypted backup is decrypted with the password slot, so the passphrase is required for it
+func ImportAegis(r io.Reader, passphrase []byte) (*Result, error) {
+	var v aegisVault
+	if err := json.NewDecoder(r).Decode(&v); err != nil {
+		return nil, fmt.Errorf("invalid aegis backup: %w", err)
+	}
+
+	plaintext := []byte(v.DB)
+	if v.Header.Params != nil {
+		if len(passphrase) == 0 {
+			return nil, ErrPassphraseRequired
+		}
+		var err error
+		plaintext, err = v.decrypt(passphrase)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var db aegisDB
+	if err := json.Unmarshal(plaintext, &db); err != nil {
+		return nil, fmt.Errorf("invalid aegis backup: %w", err)
+	}
+
+	res := &Result{}
+	for i, e := range db.Entries {
+		k, err := newKey(e.Type)
+		k.Issuer, k.AccountName, k.Secret = e.Issuer, e.Name, e.Info.Secret
+		if err == nil {
+			err = setParams(k, e.Info.Algo, e.Info.Digits, e.Info.Period)
+		}
+		if k.Host == otpauth.HostHOTP {
+			k.Counter = e.Info.Counter
+		}
+		res.add(i, k, err)
+	}
+
+	return res, nil
+}
+
+// decrypt decrypts the master key with the password slots and the database with the master key
+func (v *aegisVault) decrypt(passphrase []byte) ([]byte, error) {
+	var ciphertext string
+	if err := json.Unmarshal(v.DB, &ciphertext); err != nil {
+		return nil, errors.New("invalid aegis backup: db isn't encrypted")
+	}
+	db, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("invalid aegis backup: %w", err)
+	}
+
+	found := false
+	for _, s := range v.Header.Slots {
+		if s.Type != aegisSlotPassword {
+			continue
+		}
+		found = true
+
+		if err := validateScryptParams(s.N, s.R, s.P); err != nil {
+			return nil, fmt.Errorf("invalid aegis backup: %w", err)
+		}
+		salt, err := hex.DecodeString(s.Salt)
+		if err != nil {
+			return nil, fmt.Errorf("invalid aegis backup: %w", err)
+		}
+		key, err := scrypt.Key(passphrase, salt, s.N, s.R, s.P, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid aegis backup: %w", err)
+		}
+		encryptedKey, err := hex.DecodeString(s.Key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid aegis backup: %w", err)
+		}
+		masterKey, err := openAESGCM(key, s.KeyParams, encryptedKey)
+		if err != nil {
+			// the passphrase may be of another password slot
+			continue
+		}
+
+		plaintext, err := openAESGCM(masterKey, *v.Header.Params, db)
+		if err != nil {
+			return nil, fmt.Errorf("invalid aegis backup: %w", err)
+		}
+		return plaintext, nil
+	}
+	if !found {
+		return nil, ErrEncryptionNotSupported
+	}
+
+	return nil, ErrInvalidPassphrase
+}
+
+// validateScryptParams validates that N is a power of 2 and the parameters are in the bounds
+func validateScryptParams(n, r, p int) error {
+	if n <= 1 || n > maxAegisScryptN || n&(n-1) != 0 {
+		return fmt.Errorf("invalid scrypt n %d. please pass a power of 2 up to %d", n, maxAegisScryptN)
+	}
+	if r <= 0 || r > maxAegisScryptR {
+		return fmt.Errorf("invalid scrypt r %d. please pass 1 to %d", r, maxAegisScryptR)
+	}
+	if p <= 0 || p > maxAegisScryptP {
+		return fmt.Errorf("invalid scrypt p %d. please pass 1 to %d", p, maxAegisScryptP)
+	}
+
+	return nil
+}
+
+// openAESGCM decrypts the ciphertext whose authentication tag is separated
+func openAESGCM(key []byte, params aegisParams, ciphertext []byte) ([]byte, error) {
+	nonce, err := hex.DecodeString(params.Nonce)
+	if err != nil {
+		return nil, err
+	}
+	tag, err := hex.DecodeString(params.Tag)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCMWithNonceSize(block, len(nonce))
+	if err != nil {
+		return nil, err
+	}
+
+	sealed := make([]byte, 0, len(ciphertext)+len(tag))
+	sealed = append(append(sealed, ciphertext...), tag...)
+	return aead.Open(nil, nonce, sealed, nil)
+}
@@ -0,0 +1,74 @@
+package importer_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/butterv/one-time-password/importer"
+)
+
+func TestImportAegis_Encrypted(t *testing.T) {
+	tests := []struct {
+		passphrase string
+		wantErr    error
+	}{
+		{passphrase: "test", wantErr: nil},
+		{passphrase: "wrong", wantErr: importer.ErrInvalidPassphrase},
+		{passphrase: "", wantErr: importer.ErrPassphraseRequired},
+	}
+
+	for _, tt := range tests {
+		res, err := importer.ImportAegis(open(t, "aegis_encrypted.json"), []byte(tt.passphrase))
+		if err != tt.wantErr {
+			t.Errorf("ImportAegis(_, %q)=_, %#v; want %v", tt.passphrase, err, tt.wantErr)
+			continue
+		}
+		if err == nil && len(res.Keys) != 2 {
+			t.Errorf("ImportAegis(_, %q) imports %d keys; want 2", tt.passphrase, len(res.Keys))
+		}
+	}
+}
+
+func TestImportAegis_NoPasswordSlot(t *testing.T) {
+	in := `{"version": 1, "header": {"slots": [{"type": 2}], "params": {"nonce": "00", "tag": "00"}}, "db": "AAAA"}`
+	_, err := importer.ImportAegis(strings.NewReader(in), []byte("test"))
+	if err != importer.ErrEncryptionNotSupported {
+		t.Errorf("ImportAegis(%s)=_, %#v; want %v", in, err, importer.ErrEncryptionNotSupported)
+	}
+}
+
+func TestImportAegis_Invalid(t *testing.T) {
+	tests := []string{
+		`{"version": 1, "header": {}, "db": "not an object"}`,
+		`{"version": 1, "header": {"params": {"nonce": "00", "tag": "00"}}, "db": {}}`,
+	}
+
+	for _, in := range tests {
+		if _, err := importer.ImportAegis(strings.NewReader(in), []byte("test")); err == nil {
+			t.Errorf("ImportAegis(%s)=_, nil; want error", in)
+		}
+	}
+}
+
+func TestImportAegis_HostileScryptParams(t *testing.T) {
+	tests := []struct {
+		n, r, p int
+	}{
+		{n: 1 << 30, r: 64, p: 1},
+		{n: 1000, r: 8, p: 1},
+		{n: 1 << 21, r: 8, p: 1},
+		{n: 1 << 15, r: 9, p: 1},
+		{n: 1 << 15, r: 8, p: 1 << 20},
+		{n: 1 << 15, r: 0, p: 1},
+		{n: -2, r: 8, p: 1},
+	}
+
+	for _, tt := range tests {
+		in := fmt.Sprintf(`{"version": 1, "header": {"slots": [{"type": 1, "key": "00", "key_params": {"nonce": "00", "tag": "00"}, "n": %d, "r": %d, "p": %d, "salt": "00"}], "params": {"nonce": "00", "tag": "00"}}, "db": "AAAA"}`, tt.n, tt.r, tt.p)
+		_, err := importer.ImportAegis(strings.NewReader(in), []byte("test"))
+		if err == nil || !strings.Contains(err.Error(), "invalid scrypt") {
+			t.Errorf("ImportAegis(n=%d, r=%d, p=%d)=_, %#v; want error of invalid scrypt parameter", tt.n, tt.r, tt.p, err)
+		}
+	}
+}
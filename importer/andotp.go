@@ -0,0 +1,120 @@
+package importer
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+
+	"github.com/butterv/one-time-password/otpauth"
+)
+
+// The layout of the encrypted backup of andOTP
+// The backup is the iteration count in 4 bytes, the salt, the nonce and the ciphertext of AES-256-GCM under the key of PBKDF2 with HMAC-SHA1
+const (
+	andOTPIterationsSize = 4
+	andOTPSaltSize       = 12
+	andOTPNonceSize      = 12
+	andOTPHeaderSize     = andOTPIterationsSize + andOTPSaltSize + andOTPNonceSize
+	andOTPTagSize        = 16
+	andOTPKeySize        = 32
+	// maxAndOTPIterations bounds the cost of deriving the key, because the iteration count comes from the backup
+	// andOTP uses 140000 to 160000 iterations
+	maxAndOTPIterations = 10000000
+)
+
+type andOTPEntry struct {
+	Secret    string `json:"secret"`
+	Issuer    string `json:"issuer"`
+	Label     string `json:"label"`
+	Digits    int    `json:"digits"`
+	Type      string `json:"type"`
+	Algorithm string `json:"algorithm"`
+	Period    uint   `json:"period"`
+	Counter   uint64 `json:"counter"`
+}
+
+// ImportAndOTP imports the JSON backup of andOTP, plain or encrypted like `.json.aes`
+// An encrypted backup is decrypted with the passphrase, so the passphrase is required for it
+// The label of an old backup has the issuer like `issuer:accountName`, which is split when the issuer is empty
+func ImportAndOTP(r io.Reader, passphrase []byte) (*Result, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if isEncryptedAndOTP(b) {
+		if len(passphrase) == 0 {
+			return nil, ErrPassphraseRequired
+		}
+		b, err = decryptAndOTP(b, passphrase)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var entries []andOTPEntry
+	if err := json.NewDecoder(bytes.NewReader(b)).Decode(&entries); err != nil {
+		return nil, fmt.Errorf("invalid andotp backup: %w", err)
+	}
+
+	res := &Result{}
+	for i, e := range entries {
+		issuer, accountName := e.Issuer, e.Label
+		if i := strings.Index(accountName, ":"); issuer == "" && i >= 0 {
+			issuer, accountName = accountName[:i], accountName[i+1:]
+		}
+
+		k, err := newKey(e.Type)
+		k.Issuer, k.AccountName, k.Secret = issuer, accountName, e.Secret
+		if err == nil {
+			err = setParams(k, e.Algorithm, e.Digits, e.Period)
+		}
+		if k.Host == otpauth.HostHOTP {
+			k.Counter = e.Counter
+		}
+		res.add(i, k, err)
+	}
+
+	return res, nil
+}
+
+// isEncryptedAndOTP reports whether the backup is the encrypted backup of andOTP
+// The encrypted backup isn't JSON and starts with the iteration count
+func isEncryptedAndOTP(b []byte) bool {
+	if json.Valid(b) || len(b) < andOTPHeaderSize+andOTPTagSize {
+		return false
+	}
+	iterations := binary.BigEndian.Uint32(b[:andOTPIterationsSize])
+	return iterations > 0 && iterations <= maxAndOTPIterations
+}
+
+// decryptAndOTP decrypts the encrypted backup of andOTP
+func decryptAndOTP(b, passphrase []byte) ([]byte, error) {
+	iterations := int(binary.BigEndian.Uint32(b[:andOTPIterationsSize]))
+	salt := b[andOTPIterationsSize : andOTPIterationsSize+andOTPSaltSize]
+	nonce := b[andOTPIterationsSize+andOTPSaltSize : andOTPHeaderSize]
+	key := pbkdf2.Key(passphrase, salt, iterations, andOTPKeySize, sha1.New)
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := aead.Open(nil, nonce, b[andOTPHeaderSize:], nil)
+	if err != nil {
+		return nil, ErrInvalidPassphrase
+	}
+
+	return plaintext, nil
+}
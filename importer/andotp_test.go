@@ -0,0 +1,71 @@
+package importer_test
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha1"
+	"encoding/binary"
+	"testing"
+
+	"golang.org/x/crypto/pbkdf2"
+
+	"github.com/butterv/one-time-password/importer"
+)
+
+// encryptAndOTP encrypts the plain backup as andOTP does
+func encryptAndOTP(t *testing.T, plaintext, passphrase []byte, iterations uint32) []byte {
+	t.Helper()
+
+	header := make([]byte, 28)
+	binary.BigEndian.PutUint32(header, iterations)
+	for i := 4; i < len(header); i++ {
+		header[i] = byte(i)
+	}
+	block, err := aes.NewCipher(pbkdf2.Key(passphrase, header[4:16], int(iterations), 32, sha1.New))
+	if err != nil {
+		t.Fatal(err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return aead.Seal(header, header[16:28], plaintext, nil)
+}
+
+func TestImportAndOTP_Encrypted(t *testing.T) {
+	in := encryptAndOTP(t, []byte(`[{"secret": "`+rfcSecret+`", "issuer": "butter", "label": "butter@example.com", "type": "TOTP"}]`), []byte("test"), 1000)
+
+	tests := []struct {
+		passphrase string
+		wantErr    error
+	}{
+		{passphrase: "test", wantErr: nil},
+		{passphrase: "wrong", wantErr: importer.ErrInvalidPassphrase},
+		{passphrase: "", wantErr: importer.ErrPassphraseRequired},
+	}
+
+	for _, tt := range tests {
+		res, f, err := importer.Import(bytes.NewReader(in), []byte(tt.passphrase))
+		if err != tt.wantErr {
+			t.Errorf("Import(_, %q)=_, _, %#v; want %v", tt.passphrase, err, tt.wantErr)
+			continue
+		}
+		if f != importer.FormatAndOTP {
+			t.Errorf("Import(_, %q) detects %v; want %v", tt.passphrase, f, importer.FormatAndOTP)
+		}
+		if err == nil && (len(res.Keys) != 1 || res.Keys[0].AccountName != "butter@example.com") {
+			t.Errorf("Import(_, %q) imports %v; want the key of butter@example.com", tt.passphrase, res.Keys)
+		}
+	}
+}
+
+func TestImportAndOTP_EncryptedTooManyIterations(t *testing.T) {
+	in := encryptAndOTP(t, []byte(`[]`), []byte("test"), 1)
+	binary.BigEndian.PutUint32(in, 1<<31)
+
+	if _, err := importer.ImportAndOTP(bytes.NewReader(in), []byte("test")); err == nil {
+		t.Error("ImportAndOTP() with 2^31 iterations=_, nil; want error")
+	}
+}
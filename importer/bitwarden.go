@@ -0,0 +1,44 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+type bitwardenExport struct {
+	Encrypted bool            `json:"encrypted"`
+	Items     []bitwardenItem `json:"items"`
+}
+
+type bitwardenItem struct {
+	Name  string `json:"name"`
+	Login *struct {
+		Username string `json:"username"`
+		TOTP     string `json:"totp"`
+	} `json:"login"`
+}
+
+// ImportBitwarden imports the unencrypted JSON export of Bitwarden
+// The items without the TOTP field are ignored, and the name of the item is used as the issuer when the URI doesn't have it
+func ImportBitwarden(r io.Reader) (*Result, error) {
+	var e bitwardenExport
+	if err := json.NewDecoder(r).Decode(&e); err != nil {
+		return nil, fmt.Errorf("invalid bitwarden export: %w", err)
+	}
+	if e.Encrypted {
+		return nil, ErrEncryptionNotSupported
+	}
+
+	res := &Result{}
+	for i, item := range e.Items {
+		if item.Login == nil || item.Login.TOTP == "" {
+			continue
+		}
+
+		k, err := keyFromTOTPField(item.Login.TOTP, item.Name, item.Login.Username)
+		res.add(i, k, err)
+	}
+
+	return res, nil
+}
@@ -0,0 +1,60 @@
+package importer
+
+import (
+	"encoding/base32"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/butterv/one-time-password/otpauth"
+)
+
+type freeOTPPlusBackup struct {
+	Tokens []freeOTPPlusToken `json:"tokens"`
+}
+
+type freeOTPPlusToken struct {
+	Type      string `json:"type"`
+	IssuerExt string `json:"issuerExt"`
+	IssuerInt string `json:"issuerInt"`
+	Label     string `json:"label"`
+	Algo      string `json:"algo"`
+	Digits    int    `json:"digits"`
+	Period    uint   `json:"period"`
+	Counter   uint64 `json:"counter"`
+	// Secret is the raw secret in signed bytes of Java
+	Secret []int8 `json:"secret"`
+}
+
+// ImportFreeOTPPlus imports the JSON backup of FreeOTP+
+func ImportFreeOTPPlus(r io.Reader) (*Result, error) {
+	var b freeOTPPlusBackup
+	if err := json.NewDecoder(r).Decode(&b); err != nil {
+		return nil, fmt.Errorf("invalid freeotp+ backup: %w", err)
+	}
+
+	res := &Result{}
+	for i, t := range b.Tokens {
+		issuer := t.IssuerExt
+		if issuer == "" {
+			issuer = t.IssuerInt
+		}
+		secret := make([]byte, len(t.Secret))
+		for j, s := range t.Secret {
+			secret[j] = byte(s)
+		}
+
+		k, err := newKey(t.Type)
+		k.Issuer, k.AccountName = issuer, t.Label
+		k.Secret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(secret)
+		if err == nil {
+			err = setParams(k, t.Algo, t.Digits, t.Period)
+		}
+		if k.Host == otpauth.HostHOTP {
+			k.Counter = t.Counter
+		}
+		res.add(i, k, err)
+	}
+
+	return res, nil
+}
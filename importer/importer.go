@@ -0,0 +1,303 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/butterv/one-time-password/otpauth"
+)
+
+// ErrUnknownFormat is an error when the format of the backup isn't detected
+var ErrUnknownFormat = errors.New("unknown backup format")
+
+// ErrPassphraseRequired is an error when the backup is encrypted and the passphrase isn't passed
+var ErrPassphraseRequired = errors.New("passphrase is required for encrypted backup")
+
+// ErrInvalidPassphrase is an error when the passphrase doesn't decrypt the backup
+var ErrInvalidPassphrase = errors.New("invalid passphrase")
+
+// ErrEncryptionNotSupported is an error when the backup is encrypted in a way that isn't supported
+var ErrEncryptionNotSupported = errors.New("encrypted backup of this format isn't supported. please export it without encryption")
+
+// ErrUnsupportedType is an error when the entry is a one time password other than HOTP and TOTP like Steam
+var ErrUnsupportedType = errors.New("unsupported type of one time password")
+
+// Format is the format of a backup of an authenticator app
+type Format int
+
+const (
+	// FormatAegis is the JSON of Aegis Authenticator, plain or encrypted with a password
+	// See: https://github.com/beemdevelopment/Aegis/blob/master/docs/vault.md
+	FormatAegis Format = iota
+	// FormatAndOTP is the JSON of andOTP, plain or encrypted with a password
+	FormatAndOTP
+	// Format2FAS is the plain JSON of 2FAS Authenticator
+	Format2FAS
+	// FormatFreeOTPPlus is the JSON of FreeOTP+
+	FormatFreeOTPPlus
+	// FormatBitwarden is the unencrypted JSON export of Bitwarden
+	FormatBitwarden
+	// Format1Password is the export.data JSON in the 1PUX export of 1Password
+	Format1Password
+)
+
+// String returns the name of the format
+func (f Format) String() string {
+	switch f {
+	case FormatAegis:
+		return "aegis"
+	case FormatAndOTP:
+		return "andotp"
+	case Format2FAS:
+		return "2fas"
+	case FormatFreeOTPPlus:
+		return "freeotp+"
+	case FormatBitwarden:
+		return "bitwarden"
+	case Format1Password:
+		return "1password"
+	}
+
+	return "unknown"
+}
+
+// Result is the keys imported from a backup with the entries that can't be imported
+type Result struct {
+	// Keys is the imported keys in the order of the backup
+	Keys []*otpauth.Key
+	// Failures is the entries that can't be imported
+	Failures []*Failure
+}
+
+// Failure is an entry of a backup that can't be imported
+type Failure struct {
+	// Index is the position of the entry in the backup
+	Index int
+	// Name is the name of the entry like `issuer:accountName`
+	Name string
+	// Err is the reason why the entry can't be imported
+	Err error
+}
+
+// Error returns the reason with the position and the name of the entry
+func (f *Failure) Error() string {
+	return fmt.Sprintf("entry %d %q: %s", f.Index, f.Name, f.Err)
+}
+
+// Unwrap returns the reason why the entry can't be imported
+func (f *Failure) Unwrap() error {
+	return f.Err
+}
+
+// Import detects the format of the backup and imports it
+// The passphrase is used only for an encrypted backup and may be nil otherwise
+func Import(r io.Reader, passphrase []byte) (*Result, Format, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, 0, err
+	}
+	f, err := Detect(b)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var res *Result
+	rd := bytes.NewReader(b)
+	switch f {
+	case FormatAegis:
+		res, err = ImportAegis(rd, passphrase)
+	case FormatAndOTP:
+		res, err = ImportAndOTP(rd, passphrase)
+	case Format2FAS:
+		res, err = Import2FAS(rd)
+	case FormatFreeOTPPlus:
+		res, err = ImportFreeOTPPlus(rd)
+	case FormatBitwarden:
+		res, err = ImportBitwarden(rd)
+	case Format1Password:
+		res, err = Import1Password(rd)
+	}
+	if err != nil {
+		return nil, f, err
+	}
+
+	return res, f, nil
+}
+
+// Detect detects the format of the backup from its structure
+func Detect(b []byte) (Format, error) {
+	if isEncryptedAndOTP(b) {
+		return FormatAndOTP, nil
+	}
+
+	var list []map[string]json.RawMessage
+	if err := json.Unmarshal(b, &list); err == nil {
+		if len(list) == 0 {
+			return 0, ErrUnknownFormat
+		}
+		if has(list[0], "secret", "type") {
+			return FormatAndOTP, nil
+		}
+		return 0, ErrUnknownFormat
+	}
+
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(b, &obj); err != nil {
+		return 0, ErrUnknownFormat
+	}
+	switch {
+	case has(obj, "header", "db"):
+		return FormatAegis, nil
+	case has(obj, "schemaVersion") && (has(obj, "services") || has(obj, "servicesEncrypted")):
+		return Format2FAS, nil
+	case has(obj, "tokens"):
+		return FormatFreeOTPPlus, nil
+	case has(obj, "encrypted") && (has(obj, "items") || has(obj, "data")):
+		return FormatBitwarden, nil
+	case has(obj, "accounts"):
+		return Format1Password, nil
+	}
+
+	return 0, ErrUnknownFormat
+}
+
+func has(obj map[string]json.RawMessage, keys ...string) bool {
+	for _, k := range keys {
+		if _, ok := obj[k]; !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// add validates the key and adds it to the result, or adds the failure of the entry
+func (res *Result) add(index int, k *otpauth.Key, err error) {
+	var normalized *otpauth.Key
+	if err == nil {
+		normalized, err = normalize(k)
+	}
+	if err != nil {
+		name := ""
+		if k != nil {
+			name = label(k.Issuer, k.AccountName)
+		}
+		res.Failures = append(res.Failures, &Failure{Index: index, Name: name, Err: err})
+		return
+	}
+
+	res.Keys = append(res.Keys, normalized)
+}
+
+// normalize validates the key and normalizes the secret through its otpauth URI
+// The issuer is used as the account name when the backup doesn't have it
+func normalize(k *otpauth.Key) (*otpauth.Key, error) {
+	c := *k
+	c.Issuer = strings.TrimSpace(c.Issuer)
+	c.AccountName = strings.TrimSpace(c.AccountName)
+	if c.AccountName == "" {
+		c.AccountName = c.Issuer
+	}
+
+	uri, err := c.URL()
+	if err != nil {
+		return nil, err
+	}
+
+	return otpauth.ParseURL(uri)
+}
+
+func label(issuer, accountName string) string {
+	if issuer == "" {
+		return accountName
+	}
+	if accountName == "" {
+		return issuer
+	}
+
+	return issuer + ":" + accountName
+}
+
+// newKey returns a key of the type with the default values
+func newKey(typ string) (*otpauth.Key, error) {
+	k := &otpauth.Key{
+		Algorithm: otpauth.AlgorithmSHA1,
+		Digits:    otpauth.DigitsSix,
+		Period:    otpauth.DefaultPeriod,
+	}
+	switch strings.ToLower(typ) {
+	case "totp":
+		k.Host = otpauth.HostTOTP
+	case "hotp":
+		k.Host = otpauth.HostHOTP
+	default:
+		return k, fmt.Errorf("%w %q", ErrUnsupportedType, typ)
+	}
+
+	return k, nil
+}
+
+// setParams sets the algorithm, the digits and the period of the backup to the key
+// The zero values are ignored, so the default values remain
+func setParams(k *otpauth.Key, algorithm string, digits int, period uint) error {
+	if algorithm != "" {
+		a, err := parseAlgorithm(algorithm)
+		if err != nil {
+			return err
+		}
+		k.Algorithm = a
+	}
+	if digits != 0 {
+		if !otpauth.Digits(digits).Enabled() {
+			return fmt.Errorf("unsupported digits %d. only %d or %d is supported", digits, otpauth.DigitsSix, otpauth.DigitsEight)
+		}
+		k.Digits = otpauth.Digits(digits)
+	}
+	if period != 0 {
+		k.Period = period
+	}
+
+	return nil
+}
+
+func parseAlgorithm(s string) (otpauth.Algorithm, error) {
+	s = strings.TrimPrefix(strings.ToUpper(s), "HMAC")
+	for a := otpauth.AlgorithmSHA1; a.Enabled(); a++ {
+		if strings.EqualFold(a.String(), s) {
+			return a, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unsupported algorithm %q", s)
+}
+
+// keyFromTOTPField parses the one time password field of a password manager
+// The field is an otpauth URI or a bare base32 encoded secret of TOTP with the default values
+func keyFromTOTPField(field, issuer, accountName string) (*otpauth.Key, error) {
+	field = strings.TrimSpace(field)
+	if strings.Contains(field, "://") {
+		if !strings.HasPrefix(strings.ToLower(field), "otpauth://") {
+			return &otpauth.Key{Issuer: issuer, AccountName: accountName}, fmt.Errorf("%w %q", ErrUnsupportedType, field[:strings.Index(field, "://")])
+		}
+		k, err := otpauth.ParseURL(field)
+		if err != nil {
+			return &otpauth.Key{Issuer: issuer, AccountName: accountName}, err
+		}
+		if k.Issuer == "" {
+			k.Issuer = issuer
+		}
+		if k.AccountName == "" {
+			k.AccountName = accountName
+		}
+		return k, nil
+	}
+
+	k, _ := newKey("totp")
+	k.Issuer, k.AccountName, k.Secret = issuer, accountName, field
+	return k, nil
+}
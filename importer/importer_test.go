@@ -0,0 +1,202 @@
+package importer_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/butterv/one-time-password/importer"
+	"github.com/butterv/one-time-password/otpauth"
+)
+
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+var (
+	butterKey = &otpauth.Key{
+		Host:        otpauth.HostTOTP,
+		Issuer:      "butter",
+		AccountName: "butter@example.com",
+		Secret:      rfcSecret,
+		Algorithm:   otpauth.AlgorithmSHA1,
+		Digits:      otpauth.DigitsSix,
+		Period:      30,
+	}
+	deployKey = &otpauth.Key{
+		Host:        otpauth.HostHOTP,
+		Issuer:      "ci",
+		AccountName: "deploy",
+		Secret:      rfcSecret,
+		Algorithm:   otpauth.AlgorithmSHA256,
+		Digits:      otpauth.DigitsEight,
+		Period:      30,
+		Counter:     5,
+	}
+	deployTOTPKey = &otpauth.Key{
+		Host:        otpauth.HostTOTP,
+		Issuer:      "ci",
+		AccountName: "deploy",
+		Secret:      rfcSecret,
+		Algorithm:   otpauth.AlgorithmSHA1,
+		Digits:      otpauth.DigitsSix,
+		Period:      30,
+	}
+)
+
+func open(t *testing.T, name string) *os.File {
+	t.Helper()
+
+	f, err := os.Open(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = f.Close() })
+	return f
+}
+
+func TestImport(t *testing.T) {
+	tests := []struct {
+		file         string
+		passphrase   string
+		wantFormat   importer.Format
+		wantKeys     []*otpauth.Key
+		wantFailures []string
+		wantErr      error
+	}{
+		{
+			file:         "aegis_plain.json",
+			wantFormat:   importer.FormatAegis,
+			wantKeys:     []*otpauth.Key{butterKey, deployKey},
+			wantFailures: []string{"Steam:gamer"},
+			wantErr:      importer.ErrUnsupportedType,
+		},
+		{
+			file:         "aegis_encrypted.json",
+			passphrase:   "test",
+			wantFormat:   importer.FormatAegis,
+			wantKeys:     []*otpauth.Key{butterKey, deployKey},
+			wantFailures: []string{"Steam:gamer"},
+			wantErr:      importer.ErrUnsupportedType,
+		},
+		{
+			file:         "andotp.json",
+			wantFormat:   importer.FormatAndOTP,
+			wantKeys:     []*otpauth.Key{butterKey, deployKey},
+			wantFailures: []string{"Steam:gamer"},
+			wantErr:      importer.ErrUnsupportedType,
+		},
+		{
+			file:         "2fas.json",
+			wantFormat:   importer.Format2FAS,
+			wantKeys:     []*otpauth.Key{butterKey, deployKey},
+			wantFailures: []string{"Steam:gamer"},
+			wantErr:      importer.ErrUnsupportedType,
+		},
+		{
+			file:         "freeotp_plus.json",
+			wantFormat:   importer.FormatFreeOTPPlus,
+			wantKeys:     []*otpauth.Key{butterKey, deployKey},
+			wantFailures: []string{"legacy:admin"},
+		},
+		{
+			file:         "bitwarden.json",
+			wantFormat:   importer.FormatBitwarden,
+			wantKeys:     []*otpauth.Key{butterKey, deployTOTPKey},
+			wantFailures: []string{"Steam:gamer"},
+			wantErr:      importer.ErrUnsupportedType,
+		},
+		{
+			file:         "1password.json",
+			wantFormat:   importer.Format1Password,
+			wantKeys:     []*otpauth.Key{butterKey, deployTOTPKey},
+			wantFailures: []string{"legacy"},
+		},
+	}
+
+	for _, tt := range tests {
+		res, f, err := importer.Import(open(t, tt.file), []byte(tt.passphrase))
+		if err != nil {
+			t.Fatalf("Import(%s)=_, _, %#v; want nil", tt.file, err)
+		}
+		if f != tt.wantFormat {
+			t.Errorf("Import(%s) detects %v; want %v", tt.file, f, tt.wantFormat)
+		}
+		if !reflect.DeepEqual(res.Keys, tt.wantKeys) {
+			for i, k := range res.Keys {
+				t.Logf("Keys[%d]=%+v", i, k)
+			}
+			t.Errorf("Import(%s) imports unexpected keys", tt.file)
+		}
+		if len(res.Failures) != len(tt.wantFailures) {
+			t.Fatalf("Import(%s) fails %d entries %v; want %d", tt.file, len(res.Failures), res.Failures, len(tt.wantFailures))
+		}
+		for i, failure := range res.Failures {
+			if failure.Name != tt.wantFailures[i] {
+				t.Errorf("Import(%s) fails %q; want %q", tt.file, failure.Name, tt.wantFailures[i])
+			}
+			if tt.wantErr != nil && !errors.Is(failure, tt.wantErr) {
+				t.Errorf("Import(%s) fails by %v; want %v", tt.file, failure.Err, tt.wantErr)
+			}
+		}
+	}
+}
+
+func TestImport_UnknownFormat(t *testing.T) {
+	tests := []string{
+		`not json`,
+		`[]`,
+		`[{"name": "x"}]`,
+		`{"name": "x"}`,
+	}
+
+	for _, in := range tests {
+		_, _, err := importer.Import(strings.NewReader(in), nil)
+		if err != importer.ErrUnknownFormat {
+			t.Errorf("Import(%s)=_, _, %#v; want %v", in, err, importer.ErrUnknownFormat)
+		}
+	}
+}
+
+func TestFailure_Error(t *testing.T) {
+	f := &importer.Failure{Index: 2, Name: "Steam:gamer", Err: importer.ErrUnsupportedType}
+	want := `entry 2 "Steam:gamer": unsupported type of one time password`
+	if got := f.Error(); got != want {
+		t.Errorf("Error()=%s; want %s", got, want)
+	}
+}
+
+func TestFormat_String(t *testing.T) {
+	want := []string{"aegis", "andotp", "2fas", "freeotp+", "bitwarden", "1password", "unknown"}
+	for i, w := range want {
+		if got := importer.Format(i).String(); got != w {
+			t.Errorf("Format(%d).String()=%s; want %s", i, got, w)
+		}
+	}
+}
+
+func TestImport2FAS_Encrypted(t *testing.T) {
+	in := `{"services": [], "servicesEncrypted": "abc:def:ghi", "schemaVersion": 4}`
+	if _, err := importer.Import2FAS(strings.NewReader(in)); err != importer.ErrEncryptionNotSupported {
+		t.Errorf("Import2FAS(%s)=_, %#v; want %v", in, err, importer.ErrEncryptionNotSupported)
+	}
+}
+
+func TestImportBitwarden_Encrypted(t *testing.T) {
+	in := `{"encrypted": true, "encKeyValidation_DO_NOT_EDIT": "2.abc", "data": "2.def"}`
+	if _, err := importer.ImportBitwarden(strings.NewReader(in)); err != importer.ErrEncryptionNotSupported {
+		t.Errorf("ImportBitwarden(%s)=_, %#v; want %v", in, err, importer.ErrEncryptionNotSupported)
+	}
+}
+
+func TestImportAndOTP_AccountFallsBackToIssuer(t *testing.T) {
+	in := `[{"secret": "` + rfcSecret + `", "issuer": "butter", "label": "", "type": "TOTP"}]`
+	res, err := importer.ImportAndOTP(strings.NewReader(in), nil)
+	if err != nil {
+		t.Fatalf("ImportAndOTP(%s)=_, %#v; want nil", in, err)
+	}
+	if len(res.Keys) != 1 || res.Keys[0].AccountName != "butter" {
+		t.Errorf("ImportAndOTP(%s) imports %v; want account butter", in, res.Keys)
+	}
+}
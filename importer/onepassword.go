@@ -0,0 +1,71 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+type onePasswordExport struct {
+	Accounts []struct {
+		Vaults []struct {
+			Items []onePasswordItem `json:"items"`
+		} `json:"vaults"`
+	} `json:"accounts"`
+}
+
+type onePasswordItem struct {
+	Overview struct {
+		Title string `json:"title"`
+	} `json:"overview"`
+	Details struct {
+		LoginFields []struct {
+			Designation string `json:"designation"`
+			Value       string `json:"value"`
+		} `json:"loginFields"`
+		Sections []struct {
+			Fields []struct {
+				Value struct {
+					TOTP *string `json:"totp"`
+				} `json:"value"`
+			} `json:"fields"`
+		} `json:"sections"`
+	} `json:"details"`
+}
+
+// Import1Password imports the export.data JSON in the 1PUX export of 1Password
+// The index of a failure counts the one time password fields through all vaults
+func Import1Password(r io.Reader) (*Result, error) {
+	var e onePasswordExport
+	if err := json.NewDecoder(r).Decode(&e); err != nil {
+		return nil, fmt.Errorf("invalid 1password export: %w", err)
+	}
+
+	res := &Result{}
+	i := 0
+	for _, a := range e.Accounts {
+		for _, v := range a.Vaults {
+			for _, item := range v.Items {
+				username := ""
+				for _, f := range item.Details.LoginFields {
+					if f.Designation == "username" {
+						username = f.Value
+					}
+				}
+
+				for _, s := range item.Details.Sections {
+					for _, f := range s.Fields {
+						if f.Value.TOTP == nil || *f.Value.TOTP == "" {
+							continue
+						}
+						k, err := keyFromTOTPField(*f.Value.TOTP, item.Overview.Title, username)
+						res.add(i, k, err)
+						i++
+					}
+				}
+			}
+		}
+	}
+
+	return res, nil
+}
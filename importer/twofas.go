@@ -0,0 +1,70 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/butterv/one-time-password/otpauth"
+)
+
+type twoFASBackup struct {
+	SchemaVersion     int             `json:"schemaVersion"`
+	Services          []twoFASService `json:"services"`
+	ServicesEncrypted string          `json:"servicesEncrypted"`
+}
+
+type twoFASService struct {
+	Name   string `json:"name"`
+	Secret string `json:"secret"`
+	OTP    struct {
+		Account   string `json:"account"`
+		Label     string `json:"label"`
+		Issuer    string `json:"issuer"`
+		Digits    int    `json:"digits"`
+		Period    uint   `json:"period"`
+		Algorithm string `json:"algorithm"`
+		Counter   uint64 `json:"counter"`
+		TokenType string `json:"tokenType"`
+	} `json:"otp"`
+}
+
+// Import2FAS imports the plain JSON backup of 2FAS Authenticator
+// The name of the service is used as the issuer when the entry doesn't have it
+func Import2FAS(r io.Reader) (*Result, error) {
+	var b twoFASBackup
+	if err := json.NewDecoder(r).Decode(&b); err != nil {
+		return nil, fmt.Errorf("invalid 2fas backup: %w", err)
+	}
+	if b.ServicesEncrypted != "" {
+		return nil, ErrEncryptionNotSupported
+	}
+
+	res := &Result{}
+	for i, s := range b.Services {
+		issuer := s.OTP.Issuer
+		if issuer == "" {
+			issuer = s.Name
+		}
+		accountName := s.OTP.Account
+		if accountName == "" {
+			accountName = s.OTP.Label
+		}
+		typ := s.OTP.TokenType
+		if typ == "" {
+			typ = "totp"
+		}
+
+		k, err := newKey(typ)
+		k.Issuer, k.AccountName, k.Secret = issuer, accountName, s.Secret
+		if err == nil {
+			err = setParams(k, s.OTP.Algorithm, s.OTP.Digits, s.OTP.Period)
+		}
+		if k.Host == otpauth.HostHOTP {
+			k.Counter = s.OTP.Counter
+		}
+		res.add(i, k, err)
+	}
+
+	return res, nil
+}
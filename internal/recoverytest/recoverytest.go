@@ -0,0 +1,91 @@
+// Package recoverytest tests that the stores of recovery codes behave alike
+// The tests are shared by the in-memory store and the SQL store, which is tested in the sqltest module
+package recoverytest
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/butterv/one-time-password/recovery"
+)
+
+// NewStore generates an empty store of recovery codes
+type NewStore func(t *testing.T) recovery.Store
+
+// Run runs the tests of the stores generated by newStore
+func Run(t *testing.T, newStore NewStore) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, s recovery.Store)
+	}{
+		{name: "Store", test: testStore},
+		{name: "Manager_Redeem", test: testManagerRedeem},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.test(t, newStore(t))
+		})
+	}
+}
+
+func testStore(t *testing.T, s recovery.Store) {
+	ctx := context.Background()
+
+	if err := s.Save(ctx, "user", []string{"h1", "h2"}); err != nil {
+		t.Fatalf("Save(user, [h1 h2])=%#v; want nil", err)
+	}
+	if err := s.Consume(ctx, "user", "h2"); err != nil {
+		t.Fatalf("Consume(user, h2)=%#v; want nil", err)
+	}
+	if err := s.Consume(ctx, "user", "h2"); err != recovery.ErrRecoveryCodeConsumed {
+		t.Errorf("Consume(user, h2)=%#v; want %v", err, recovery.ErrRecoveryCodeConsumed)
+	}
+	if err := s.Consume(ctx, "user", "h3"); err != recovery.ErrInvalidRecoveryCode {
+		t.Errorf("Consume(user, h3)=%#v; want %v", err, recovery.ErrInvalidRecoveryCode)
+	}
+
+	want := []recovery.StoredCode{{Hash: "h1"}, {Hash: "h2", Consumed: true}}
+	got, err := s.List(ctx, "user")
+	if err != nil {
+		t.Fatalf("List(user)=_, %#v; want nil", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("List(user)=%v, _; want %v", got, want)
+	}
+
+	if err := s.Save(ctx, "user", []string{"h3"}); err != nil {
+		t.Fatalf("Save(user, [h3])=%#v; want nil", err)
+	}
+	want = []recovery.StoredCode{{Hash: "h3"}}
+	if got, _ := s.List(ctx, "user"); !reflect.DeepEqual(got, want) {
+		t.Errorf("List(user)=%v, _; want %v", got, want)
+	}
+}
+
+func testManagerRedeem(t *testing.T, s recovery.Store) {
+	ctx := context.Background()
+	// the hash of the codes is the fastest, so the test doesn't wait for the key derivation
+	hashOpt := recovery.NewHashOption()
+	_ = hashOpt.SetKDF(recovery.KDFPBKDF2)
+	_ = hashOpt.SetPBKDF2Iterations(1)
+	m, err := recovery.NewManager(s, recovery.NewOption(), hashOpt)
+	if err != nil {
+		t.Fatalf("NewManager()=_, %#v; want nil", err)
+	}
+
+	codes, err := m.Issue(ctx, "user")
+	if err != nil {
+		t.Fatalf("Issue(user)=_, %#v; want nil", err)
+	}
+	if err := m.Redeem(ctx, "user", codes[3]); err != nil {
+		t.Fatalf("Redeem(user, %s)=%#v; want nil", codes[3], err)
+	}
+	if err := m.Redeem(ctx, "user", codes[3]); err != recovery.ErrRecoveryCodeConsumed {
+		t.Errorf("Redeem(user, %s)=%#v; want %v", codes[3], err, recovery.ErrRecoveryCodeConsumed)
+	}
+	if got, _ := m.Remaining(ctx, "user"); got != len(codes)-1 {
+		t.Errorf("Remaining(user)=%d, _; want %d", got, len(codes)-1)
+	}
+}
@@ -0,0 +1,274 @@
+// Package storetest tests that the repositories of store behave alike
+// The tests are shared by the in-memory repository and the SQL repository, which is tested in the sqltest module
+package storetest
+
+import (
+	"context"
+	"math"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/butterv/one-time-password/otpauth"
+	"github.com/butterv/one-time-password/store"
+)
+
+// Now is the current time of the repositories under test
+var Now = time.Unix(1600000000, 0)
+
+// NewRepository generates an empty repository whose clock returns now
+type NewRepository func(t *testing.T, now func() time.Time) store.Repository
+
+// Run runs the tests of the repositories generated by newRepository
+func Run(t *testing.T, newRepository NewRepository) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, r store.Repository)
+	}{
+		{name: "CRUD", test: testCRUD},
+		{name: "CompareAndSwapCounter", test: testCompareAndSwapCounter},
+		{name: "CompareAndSwapCounter_Concurrent", test: testCompareAndSwapCounterConcurrent},
+		{name: "Create_Concurrent", test: testCreateConcurrent},
+		{name: "UpdateLastStep", test: testUpdateLastStep},
+		{name: "MaxValue", test: testMaxValue},
+		{name: "InvalidCredential", test: testInvalidCredential},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.test(t, newRepository(t, func() time.Time { return Now }))
+		})
+	}
+}
+
+// Credential returns a valid credential of TOTP
+func Credential(id, userID string) *store.Credential {
+	return &store.Credential{
+		ID:        id,
+		UserID:    userID,
+		Host:      otpauth.HostTOTP,
+		Secret:    "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ",
+		Algorithm: otpauth.AlgorithmSHA256,
+		Digits:    otpauth.DigitsEight,
+		Period:    30,
+	}
+}
+
+func testCRUD(t *testing.T, r store.Repository) {
+	ctx := context.Background()
+
+	c := Credential("c1", "user")
+	if err := r.Create(ctx, c); err != nil {
+		t.Fatalf("Create(c1)=%#v; want nil", err)
+	}
+	if !c.CreatedAt.Equal(Now) || !c.UpdatedAt.Equal(Now) {
+		t.Errorf("Create(c1) sets %v, %v; want %v", c.CreatedAt, c.UpdatedAt, Now)
+	}
+	if err := r.Create(ctx, Credential("c1", "other")); err != store.ErrCredentialExists {
+		t.Errorf("Create(c1)=%#v; want %v", err, store.ErrCredentialExists)
+	}
+
+	got, err := r.Get(ctx, "c1")
+	if err != nil {
+		t.Fatalf("Get(c1)=_, %#v; want nil", err)
+	}
+	if !reflect.DeepEqual(got, c) {
+		t.Errorf("Get(c1)=%+v, _; want %+v", got, c)
+	}
+	if _, err := r.Get(ctx, "unknown"); err != store.ErrCredentialNotFound {
+		t.Errorf("Get(unknown)=_, %#v; want %v", err, store.ErrCredentialNotFound)
+	}
+
+	hotp := Credential("c2", "user")
+	hotp.Host, hotp.Algorithm, hotp.Digits, hotp.Counter = otpauth.HostHOTP, otpauth.AlgorithmSHA1, otpauth.DigitsSix, 5
+	if err := r.Create(ctx, hotp); err != nil {
+		t.Fatalf("Create(c2)=%#v; want nil", err)
+	}
+	_ = r.Create(ctx, Credential("c3", "other"))
+
+	list, err := r.ListByUser(ctx, "user")
+	if err != nil {
+		t.Fatalf("ListByUser(user)=_, %#v; want nil", err)
+	}
+	if want := []*store.Credential{c, hotp}; !reflect.DeepEqual(list, want) {
+		t.Errorf("ListByUser(user)=%+v, _; want %+v", list, want)
+	}
+	if list, _ := r.ListByUser(ctx, "nobody"); len(list) != 0 {
+		t.Errorf("ListByUser(nobody)=%+v, _; want empty", list)
+	}
+
+	c.Secret, c.Period, c.LastStep = "JBSWY3DPEHPK3PXP", 60, 100
+	if err := r.Update(ctx, c); err != nil {
+		t.Fatalf("Update(c1)=%#v; want nil", err)
+	}
+	if got, _ := r.Get(ctx, "c1"); !reflect.DeepEqual(got, c) {
+		t.Errorf("Get(c1)=%+v, _; want %+v", got, c)
+	}
+	if err := r.Update(ctx, Credential("unknown", "user")); err != store.ErrCredentialNotFound {
+		t.Errorf("Update(unknown)=%#v; want %v", err, store.ErrCredentialNotFound)
+	}
+
+	if err := r.Delete(ctx, "c3"); err != nil {
+		t.Fatalf("Delete(c3)=%#v; want nil", err)
+	}
+	if _, err := r.Get(ctx, "c3"); err != store.ErrCredentialNotFound {
+		t.Errorf("Get(c3)=_, %#v; want %v", err, store.ErrCredentialNotFound)
+	}
+	if err := r.Delete(ctx, "c3"); err != store.ErrCredentialNotFound {
+		t.Errorf("Delete(c3)=%#v; want %v", err, store.ErrCredentialNotFound)
+	}
+}
+
+func testCompareAndSwapCounter(t *testing.T, r store.Repository) {
+	ctx := context.Background()
+	c := Credential("c1", "user")
+	c.Host, c.Counter = otpauth.HostHOTP, 5
+	_ = r.Create(ctx, c)
+
+	if err := r.CompareAndSwapCounter(ctx, "c1", 5, 8); err != nil {
+		t.Fatalf("CompareAndSwapCounter(c1, 5, 8)=%#v; want nil", err)
+	}
+	if err := r.CompareAndSwapCounter(ctx, "c1", 5, 6); err != store.ErrConflict {
+		t.Errorf("CompareAndSwapCounter(c1, 5, 6)=%#v; want %v", err, store.ErrConflict)
+	}
+	if got, _ := r.Get(ctx, "c1"); got.Counter != 8 {
+		t.Errorf("Get(c1) has counter %d; want 8", got.Counter)
+	}
+	if err := r.CompareAndSwapCounter(ctx, "unknown", 0, 1); err != store.ErrCredentialNotFound {
+		t.Errorf("CompareAndSwapCounter(unknown, 0, 1)=%#v; want %v", err, store.ErrCredentialNotFound)
+	}
+}
+
+func testCompareAndSwapCounterConcurrent(t *testing.T, r store.Repository) {
+	ctx := context.Background()
+	c := Credential("c1", "user")
+	c.Host = otpauth.HostHOTP
+	_ = r.Create(ctx, c)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	swapped := 0
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := r.CompareAndSwapCounter(ctx, "c1", 0, 1); err == nil {
+				mu.Lock()
+				swapped++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if swapped != 1 {
+		t.Errorf("CompareAndSwapCounter(c1, 0, 1) succeeds %d times; want 1", swapped)
+	}
+}
+
+func testCreateConcurrent(t *testing.T, r store.Repository) {
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	errs := make([]error, 10)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = r.Create(ctx, Credential("c1", "user"))
+		}(i)
+	}
+	wg.Wait()
+
+	created := 0
+	for _, err := range errs {
+		switch err {
+		case nil:
+			created++
+		case store.ErrCredentialExists:
+		default:
+			t.Errorf("Create(c1)=%#v; want nil or %v", err, store.ErrCredentialExists)
+		}
+	}
+	if created != 1 {
+		t.Errorf("Create(c1) succeeds %d times; want 1", created)
+	}
+}
+
+func testUpdateLastStep(t *testing.T, r store.Repository) {
+	ctx := context.Background()
+	_ = r.Create(ctx, Credential("c1", "user"))
+
+	if err := r.UpdateLastStep(ctx, "c1", 100); err != nil {
+		t.Fatalf("UpdateLastStep(c1, 100)=%#v; want nil", err)
+	}
+	for _, step := range []uint64{100, 99} {
+		if err := r.UpdateLastStep(ctx, "c1", step); err != store.ErrStepUsed {
+			t.Errorf("UpdateLastStep(c1, %d)=%#v; want %v", step, err, store.ErrStepUsed)
+		}
+	}
+	if err := r.UpdateLastStep(ctx, "c1", 101); err != nil {
+		t.Errorf("UpdateLastStep(c1, 101)=%#v; want nil", err)
+	}
+	if got, _ := r.Get(ctx, "c1"); got.LastStep != 101 {
+		t.Errorf("Get(c1) has last step %d; want 101", got.LastStep)
+	}
+	if err := r.UpdateLastStep(ctx, "unknown", 1); err != store.ErrCredentialNotFound {
+		t.Errorf("UpdateLastStep(unknown, 1)=%#v; want %v", err, store.ErrCredentialNotFound)
+	}
+}
+
+func testMaxValue(t *testing.T, r store.Repository) {
+	ctx := context.Background()
+	c := Credential("c1", "user")
+	c.Host, c.Counter, c.LastStep = otpauth.HostHOTP, math.MaxInt64, math.MaxInt64
+	if err := r.Create(ctx, c); err != nil {
+		t.Fatalf("Create(c1)=%#v; want nil", err)
+	}
+	if got, _ := r.Get(ctx, "c1"); got.Counter != math.MaxInt64 || got.LastStep != math.MaxInt64 {
+		t.Errorf("Get(c1) has counter %d and last step %d; want %d", got.Counter, got.LastStep, uint64(math.MaxInt64))
+	}
+
+	c = Credential("c2", "user")
+	c.Counter = math.MaxInt64 + 1
+	if err := r.Create(ctx, c); err == nil {
+		t.Error("Create(c2) with counter over math.MaxInt64=nil; want error")
+	}
+	c.Counter, c.LastStep = 0, math.MaxUint64
+	if err := r.Create(ctx, c); err == nil {
+		t.Error("Create(c2) with last step over math.MaxInt64=nil; want error")
+	}
+	c = Credential("c1", "user")
+	c.Counter = math.MaxUint64
+	if err := r.Update(ctx, c); err == nil {
+		t.Error("Update(c1) with counter over math.MaxInt64=nil; want error")
+	}
+	if err := r.CompareAndSwapCounter(ctx, "c1", math.MaxInt64, math.MaxInt64+1); err == nil || err == store.ErrConflict {
+		t.Errorf("CompareAndSwapCounter(c1, MaxInt64, MaxInt64+1)=%#v; want error of the limit", err)
+	}
+	if err := r.UpdateLastStep(ctx, "c1", math.MaxUint64); err == nil || err == store.ErrStepUsed {
+		t.Errorf("UpdateLastStep(c1, MaxUint64)=%#v; want error of the limit", err)
+	}
+}
+
+func testInvalidCredential(t *testing.T, r store.Repository) {
+	tests := map[string]func(c *store.Credential){
+		"empty id":       func(c *store.Credential) { c.ID = "" },
+		"invalid host":   func(c *store.Credential) { c.Host = 5 },
+		"empty secret":   func(c *store.Credential) { c.Secret = "" },
+		"invalid digits": func(c *store.Credential) { c.Digits = 7 },
+		"zero period":    func(c *store.Credential) { c.Period = 0 },
+	}
+
+	for name, modify := range tests {
+		c := Credential("c1", "user")
+		modify(c)
+		if err := r.Create(context.Background(), c); err == nil {
+			t.Errorf("Create() with %s=nil; want error", name)
+		}
+	}
+	if err := r.Create(context.Background(), nil); err != store.ErrCredentialIsNil {
+		t.Errorf("Create(nil)=%#v; want %v", err, store.ErrCredentialIsNil)
+	}
+}
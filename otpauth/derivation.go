@@ -0,0 +1,94 @@
+package otpauth
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+const (
+	// minMasterKeySize is the minimum size of the master key
+	minMasterKeySize = 32
+
+	derivationInfoPrefix = "one-time-password/otpauth/secret"
+)
+
+// ErrDerivationIsNil is an error when the derivation is nil
+var ErrDerivationIsNil = errors.New("derivation is nil")
+
+// Derivation derives a secret of each user from a master key of the server
+// The secret is derived with HKDF-SHA256, so the same user identifier and key version always derive the same secret
+// Rotating the secret is done by bumping the key version
+// See: https://tools.ietf.org/html/rfc5869
+type Derivation struct {
+	// masterKey is the master key of the server
+	masterKey []byte
+	// userID is the identifier of the user
+	userID string
+	// version is the version of the user's key
+	version uint32
+}
+
+// NewDerivation generates a derivation by passing master key, user identifier and key version
+func NewDerivation(masterKey []byte, userID string, version uint32) (*Derivation, error) {
+	if len(masterKey) < minMasterKeySize {
+		return nil, fmt.Errorf("invalid masterKey. please pass %d bytes or longer", minMasterKeySize)
+	}
+	if userID == "" {
+		return nil, errors.New("userID is empty")
+	}
+
+	mk := make([]byte, len(masterKey))
+	copy(mk, masterKey)
+
+	return &Derivation{
+		masterKey: mk,
+		userID:    userID,
+		version:   version,
+	}, nil
+}
+
+// Version returns the version of the user's key
+func (d *Derivation) Version() uint32 {
+	if d == nil {
+		return 0
+	}
+
+	return d.version
+}
+
+// Secret derives a base32 encoded secret of the size in bytes
+func (d *Derivation) Secret(size uint) (string, error) {
+	if d == nil {
+		return "", ErrDerivationIsNil
+	}
+	if size == 0 {
+		return "", errors.New("invalid size. please pass greater than 0")
+	}
+
+	secretBytes := make([]byte, size)
+	_, err := io.ReadFull(d.reader(), secretBytes)
+	if err != nil {
+		return "", err
+	}
+
+	return base32NoPadding.EncodeToString(secretBytes), nil
+}
+
+// reader returns a new reader of the derived key stream
+func (d *Derivation) reader() io.Reader {
+	// info is the prefix, the key version and the user identifier
+	// The version has fixed length, so the user identifier never collides with the version
+	info := make([]byte, 0, len(derivationInfoPrefix)+4+len(d.userID))
+	info = append(info, derivationInfoPrefix...)
+	vb := make([]byte, 4)
+	binary.BigEndian.PutUint32(vb, d.version)
+	info = append(info, vb...)
+	info = append(info, d.userID...)
+
+	return hkdf.New(sha256.New, d.masterKey, nil, info)
+}
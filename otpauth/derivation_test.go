@@ -0,0 +1,97 @@
+package otpauth_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/butterv/one-time-password/otpauth"
+)
+
+var masterKey = bytes.Repeat([]byte{0x42}, 32)
+
+func TestNewDerivation_InvalidMasterKey(t *testing.T) {
+	wantErr := errors.New("invalid masterKey. please pass 32 bytes or longer")
+
+	_, err := otpauth.NewDerivation(masterKey[:16], "user", 1)
+	if err == nil {
+		t.Fatalf("NewDerivation(_, user, 1)=_, nil; want %v", wantErr)
+	}
+	if err.Error() != wantErr.Error() {
+		t.Errorf("NewDerivation(_, user, 1)=_, %#v; want %v", err, wantErr)
+	}
+}
+
+func TestNewDerivation_UserIDIsEmpty(t *testing.T) {
+	wantErr := errors.New("userID is empty")
+
+	_, err := otpauth.NewDerivation(masterKey, "", 1)
+	if err == nil {
+		t.Fatalf("NewDerivation(_, \"\", 1)=_, nil; want %v", wantErr)
+	}
+	if err.Error() != wantErr.Error() {
+		t.Errorf("NewDerivation(_, \"\", 1)=_, %#v; want %v", err, wantErr)
+	}
+}
+
+func TestDerivation_Secret(t *testing.T) {
+	d, err := otpauth.NewDerivation(masterKey, "user", 1)
+	if err != nil {
+		t.Fatalf("NewDerivation(_, user, 1)=_, %#v; want nil", err)
+	}
+
+	got, err := d.Secret(20)
+	if err != nil {
+		t.Fatalf("Secret(20)=_, %#v; want nil", err)
+	}
+	if len(got) != 32 {
+		t.Errorf("Secret(20)=%s, _; want 32 characters", got)
+	}
+
+	again, _ := d.Secret(20)
+	if got != again {
+		t.Errorf("Secret(20)=%s, _; want %s for the same derivation", again, got)
+	}
+
+	for _, other := range []struct {
+		userID  string
+		version uint32
+	}{
+		{userID: "user", version: 2},
+		{userID: "another", version: 1},
+	} {
+		od, _ := otpauth.NewDerivation(masterKey, other.userID, other.version)
+		if s, _ := od.Secret(20); s == got {
+			t.Errorf("Secret(20) of (%s, %d)=%s, _; want different from (user, 1)", other.userID, other.version, s)
+		}
+	}
+}
+
+func TestDerivation_Secret_ErrDerivationIsNil(t *testing.T) {
+	wantErr := otpauth.ErrDerivationIsNil
+
+	var d *otpauth.Derivation
+	_, err := d.Secret(20)
+	if err == nil {
+		t.Fatalf("Secret(20)=_, nil; want %v, receiver nil", wantErr)
+	}
+	if err.Error() != wantErr.Error() {
+		t.Errorf("Secret(20)=_, %#v; want %v, receiver nil", err, wantErr)
+	}
+}
+
+func TestGenerateOtpAuthWithOption_Derivation(t *testing.T) {
+	d, _ := otpauth.NewDerivation(masterKey, "user", 1)
+	want, _ := d.Secret(20)
+
+	o, _ := otpauth.NewOption()
+	_ = o.SetDerivation(d)
+
+	oa, err := otpauth.GenerateOtpAuthWithOption("ISSUER", "ACCOUNT", otpauth.HostTOTP, o)
+	if err != nil {
+		t.Fatalf("GenerateOtpAuthWithOption(ISSUER, ACCOUNT, %d, %v)=_, %#v; want nil", otpauth.HostTOTP, o, err)
+	}
+	if got := oa.Secret(); got != want {
+		t.Errorf("Secret()=%s; want %s", got, want)
+	}
+}
@@ -8,6 +8,8 @@ var ExportHostName = Host.name
 
 var ExportAlgorithmName = Algorithm.name
 
+var ExportProfileEnabled = Profile.enabled
+
 func (opt *Option) Period() uint {
 	if opt == nil {
 		return 0
@@ -48,6 +50,30 @@ func (opt *Option) IconURL() string {
 	return opt.iconURL
 }
 
+func (opt *Option) Color() string {
+	if opt == nil {
+		return ""
+	}
+
+	return opt.color
+}
+
+func (opt *Option) Tags() []string {
+	if opt == nil {
+		return nil
+	}
+
+	return opt.tags
+}
+
+func (opt *Option) Profile() Profile {
+	if opt == nil {
+		return 0
+	}
+
+	return opt.profile
+}
+
 func DefaultOption() *Option {
 	return &Option{
 		period:     30,
@@ -55,7 +81,10 @@ func DefaultOption() *Option {
 		scheme:     "otpauth",
 		digits:     6,
 		algorithm:  0,
+		counter:    0,
 		iconURL:    "",
+		profile:    0,
+		policy:     NewPolicy(),
 		rand:       crand.Reader,
 	}
 }
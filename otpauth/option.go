@@ -10,6 +10,8 @@ import (
 	"fmt"
 	"hash"
 	"io"
+	"regexp"
+	"strings"
 )
 
 const (
@@ -20,6 +22,8 @@ const (
 	defaultSecretSize = 20
 )
 
+var colorPattern = regexp.MustCompile(`^[0-9A-Fa-f]{6}$`)
+
 // ErrOtpAuthOptionIsNil is an error when the otpauth option is nil
 var ErrOtpAuthOptionIsNil = errors.New("otpauth option is nil")
 
@@ -51,6 +55,15 @@ func (h Host) name() string {
 	panic("invalid host")
 }
 
+// String returns the name of the host like `totp`
+func (h Host) String() string {
+	if !h.enabled() {
+		return "unknown"
+	}
+
+	return h.name()
+}
+
 // Digits is the number of digits
 type Digits int
 
@@ -117,6 +130,15 @@ func (a Algorithm) name() string {
 	panic("invalid algorithm")
 }
 
+// String returns the name of the algorithm like `SHA1`
+func (a Algorithm) String() string {
+	if !a.Enabled() {
+		return "unknown"
+	}
+
+	return a.name()
+}
+
 // Hash returns an initialization function of each hash algorithm
 func (a Algorithm) Hash() hash.Hash {
 	switch a {
@@ -152,11 +174,27 @@ type Option struct {
 	// algorithm is the hash function to use in the HMAC operation
 	// The default value is SHA1
 	algorithm Algorithm
+	// counter is the initial counter of HMAC-based One Time Password
+	// This is used only when the host is HOTP
+	// The default value is 0
+	counter uint64
 	// iconURL is the url of icon
 	iconURL string
+	// color is the color of the account in hex format like `1E90FF`
+	color string
+	// tags is the tags of the account
+	tags []string
+	// profile is the profile of the authenticator app that reads the otpauth URI
+	// The default value is ProfileFull
+	profile Profile
+	// policy validates the strength of the option
+	// The default value flags weak configurations
+	policy *Policy
 	// rand is the reader to use for generating secret Key.
 	// The default value is reader of crypto/rand
 	rand io.Reader
+	// derivation derives the secret from a master key instead of rand
+	derivation *Derivation
 }
 
 // SetPeriod sets a period that Time-based One Time Password hash is valid
@@ -167,6 +205,9 @@ func (opt *Option) SetPeriod(period uint) error {
 	if period == 0 {
 		return errors.New("invalid period. please pass greater than 0")
 	}
+	if err := opt.policy.Enforce(opt.policy.CheckPeriod(period)); err != nil {
+		return err
+	}
 
 	opt.period = period
 	return nil
@@ -180,6 +221,9 @@ func (opt *Option) SetSecretSize(secretSize uint) error {
 	if secretSize == 0 {
 		return errors.New("invalid secretSize. please pass greater than 0")
 	}
+	if err := opt.policy.Enforce(opt.policy.CheckSecretSize(secretSize, opt.algorithm)); err != nil {
+		return err
+	}
 
 	opt.secretSize = secretSize
 	return nil
@@ -199,11 +243,28 @@ func (opt *Option) SetSecret(secret string) error {
 	if opt == nil {
 		return ErrOtpAuthOptionIsNil
 	}
+	if size, ok := decodedSecretSize(secret); ok {
+		if err := opt.policy.Enforce(opt.policy.CheckSecretSize(size, opt.algorithm)); err != nil {
+			return err
+		}
+	}
 
 	opt.secret = secret
 	return nil
 }
 
+// SetDerivation sets a derivation that derives the secret from a master key
+// When this is set, the secret is derived instead of reading from crypto/rand
+// Passing nil restores reading from crypto/rand
+func (opt *Option) SetDerivation(d *Derivation) error {
+	if opt == nil {
+		return ErrOtpAuthOptionIsNil
+	}
+
+	opt.derivation = d
+	return nil
+}
+
 // SetDigits sets the number of digits
 func (opt *Option) SetDigits(d Digits) error {
 	if opt == nil {
@@ -225,11 +286,33 @@ func (opt *Option) SetAlgorithm(a Algorithm) error {
 	if !a.Enabled() {
 		return fmt.Errorf("invalid algorithm. please pass any of %d to %d", AlgorithmSHA1, AlgorithmMD5)
 	}
+	if err := opt.policy.Enforce(opt.policy.CheckAlgorithm(a)); err != nil {
+		return err
+	}
 
 	opt.algorithm = a
 	return nil
 }
 
+// Counter returns the initial counter of HMAC-based One Time Password that option has
+func (opt *Option) Counter() uint64 {
+	if opt == nil {
+		return 0
+	}
+
+	return opt.counter
+}
+
+// SetCounter sets the initial counter of HMAC-based One Time Password
+func (opt *Option) SetCounter(counter uint64) error {
+	if opt == nil {
+		return ErrOtpAuthOptionIsNil
+	}
+
+	opt.counter = counter
+	return nil
+}
+
 // SetIconURL sets a url of icon
 func (opt *Option) SetIconURL(url string) error {
 	if opt == nil {
@@ -240,6 +323,102 @@ func (opt *Option) SetIconURL(url string) error {
 	return nil
 }
 
+// SetColor sets a color of the account in hex format like `1E90FF`
+func (opt *Option) SetColor(color string) error {
+	if opt == nil {
+		return ErrOtpAuthOptionIsNil
+	}
+	if !colorPattern.MatchString(color) {
+		return errors.New("invalid color. please pass 6 hex digits")
+	}
+
+	opt.color = strings.ToUpper(color)
+	return nil
+}
+
+// SetTags sets tags of the account
+func (opt *Option) SetTags(tags ...string) error {
+	if opt == nil {
+		return ErrOtpAuthOptionIsNil
+	}
+	for _, tag := range tags {
+		if tag == "" || strings.Contains(tag, ",") {
+			return fmt.Errorf("invalid tag %q. please pass non-empty tag without comma", tag)
+		}
+	}
+
+	opt.tags = make([]string, len(tags))
+	copy(opt.tags, tags)
+	return nil
+}
+
+// SetProfile sets a profile of the authenticator app that reads the otpauth URI
+func (opt *Option) SetProfile(p Profile) error {
+	if opt == nil {
+		return ErrOtpAuthOptionIsNil
+	}
+	if !p.enabled() {
+		return fmt.Errorf("invalid profile. please pass any of %d to %d", ProfileFull, ProfileMicrosoft)
+	}
+
+	opt.profile = p
+	return nil
+}
+
+// SetPolicy sets a policy that validates the strength of the option
+// Passing nil disables the validation
+func (opt *Option) SetPolicy(p *Policy) error {
+	if opt == nil {
+		return ErrOtpAuthOptionIsNil
+	}
+	if err := p.Enforce(opt.warnings(p, HostTOTP)); err != nil {
+		return err
+	}
+
+	opt.policy = p
+	return nil
+}
+
+// Warnings returns weak configurations of the option flagged by the policy
+// The period is checked as the option of TOTP, and GenerateOtpAuthWithOption doesn't check it for HOTP
+func (opt *Option) Warnings() []Warning {
+	if opt == nil {
+		return nil
+	}
+
+	return opt.warnings(opt.policy, HostTOTP)
+}
+
+// warnings returns weak configurations of the option for the host
+// The period is checked only for TOTP, because HOTP doesn't have a period
+func (opt *Option) warnings(p *Policy, host Host) []Warning {
+	var warnings []Warning
+	warnings = append(warnings, p.CheckAlgorithm(opt.algorithm)...)
+	if host == HostTOTP {
+		warnings = append(warnings, p.CheckPeriod(opt.period)...)
+	}
+
+	size := opt.secretSize
+	if opt.secret != "" {
+		if s, ok := decodedSecretSize(opt.secret); ok {
+			size = s
+		}
+	}
+	warnings = append(warnings, p.CheckSecretSize(size, opt.algorithm)...)
+
+	return warnings
+}
+
+// decodedSecretSize returns the size of the base32 encoded secret in bytes
+func decodedSecretSize(secret string) (uint, bool) {
+	b, err := base32NoPadding.DecodeString(strings.TrimRight(strings.ToUpper(secret), "="))
+	if err != nil {
+		return 0, false
+	}
+
+	return uint(len(b)), true
+}
+
 // NewOption generates an option by passing issuer, account name and host
 func NewOption() (*Option, error) {
 	return &Option{
@@ -248,6 +427,8 @@ func NewOption() (*Option, error) {
 		scheme:     defaultScheme,
 		digits:     DigitsSix,
 		algorithm:  AlgorithmSHA1,
+		profile:    ProfileFull,
+		policy:     NewPolicy(),
 		rand:       crand.Reader,
 	}, nil
 }
@@ -476,6 +476,142 @@ func TestOption_SetIconURL_ErrOptionIsNil(t *testing.T) {
 	}
 }
 
+func TestOption_SetCounter(t *testing.T) {
+	want := uint64(10)
+
+	counter := uint64(10)
+	o := &otpauth.Option{}
+	err := o.SetCounter(counter)
+	if err != nil {
+		t.Fatalf("SetCounter(%d)=%#v; want nil, receiver %#v", counter, err, o)
+	}
+	if got := o.Counter(); got != want {
+		t.Errorf("counter: got %d, want %d, receiver %#v", got, want, o)
+	}
+}
+
+func TestOption_SetCounter_ErrOptionIsNil(t *testing.T) {
+	wantErr := otpauth.ErrOtpAuthOptionIsNil
+
+	counter := uint64(10)
+	var o *otpauth.Option
+	err := o.SetCounter(counter)
+	if err == nil {
+		t.Fatalf("SetCounter(%d)=nil; want %v, receiver nil", counter, wantErr)
+	}
+	if err.Error() != wantErr.Error() {
+		t.Errorf("SetCounter(%d)=%#v; want %v, receiver nil", counter, err, wantErr)
+	}
+}
+
+func TestOption_SetColor(t *testing.T) {
+	want := "1E90FF"
+
+	color := "1e90ff"
+	o := &otpauth.Option{}
+	err := o.SetColor(color)
+	if err != nil {
+		t.Fatalf("SetColor(%s)=%#v; want nil, receiver %#v", color, err, o)
+	}
+	if got := o.Color(); got != want {
+		t.Errorf("color: got %s, want %s, receiver %#v", got, want, o)
+	}
+}
+
+func TestOption_SetColor_InvalidColor(t *testing.T) {
+	wantErr := errors.New("invalid color. please pass 6 hex digits")
+
+	color := "#1E90FF"
+	o := &otpauth.Option{}
+	err := o.SetColor(color)
+	if err == nil {
+		t.Fatalf("SetColor(%s)=nil; want %v, receiver %#v", color, wantErr, o)
+	}
+	if err.Error() != wantErr.Error() {
+		t.Errorf("SetColor(%s)=%#v; want %v, receiver %#v", color, err, wantErr, o)
+	}
+}
+
+func TestOption_SetTags(t *testing.T) {
+	want := []string{"work", "aws"}
+
+	o := &otpauth.Option{}
+	err := o.SetTags("work", "aws")
+	if err != nil {
+		t.Fatalf("SetTags(work, aws)=%#v; want nil, receiver %#v", err, o)
+	}
+	if got := o.Tags(); !reflect.DeepEqual(got, want) {
+		t.Errorf("tags: got %v, want %v, receiver %#v", got, want, o)
+	}
+}
+
+func TestOption_SetTags_Copy(t *testing.T) {
+	want := []string{"work", "aws"}
+
+	tags := []string{"work", "aws"}
+	o := &otpauth.Option{}
+	_ = o.SetTags(tags...)
+	tags[0] = "personal"
+	if got := o.Tags(); !reflect.DeepEqual(got, want) {
+		t.Errorf("tags: got %v, want %v, receiver %#v", got, want, o)
+	}
+}
+
+func TestOption_SetTags_InvalidTag(t *testing.T) {
+	wantErr := errors.New(`invalid tag "a,b". please pass non-empty tag without comma`)
+
+	o := &otpauth.Option{}
+	err := o.SetTags("a,b")
+	if err == nil {
+		t.Fatalf("SetTags(a,b)=nil; want %v, receiver %#v", wantErr, o)
+	}
+	if err.Error() != wantErr.Error() {
+		t.Errorf("SetTags(a,b)=%#v; want %v, receiver %#v", err, wantErr, o)
+	}
+}
+
+func TestOption_SetProfile(t *testing.T) {
+	want := otpauth.ProfileGoogle
+
+	p := otpauth.ProfileGoogle
+	o := &otpauth.Option{}
+	err := o.SetProfile(p)
+	if err != nil {
+		t.Fatalf("SetProfile(%d)=%#v; want nil, receiver %#v", p, err, o)
+	}
+	if got := o.Profile(); got != want {
+		t.Errorf("profile: got %d, want %d, receiver %#v", got, want, o)
+	}
+}
+
+func TestOption_SetProfile_ErrOptionIsNil(t *testing.T) {
+	wantErr := otpauth.ErrOtpAuthOptionIsNil
+
+	p := otpauth.ProfileGoogle
+	var o *otpauth.Option
+	err := o.SetProfile(p)
+	if err == nil {
+		t.Fatalf("SetProfile(%d)=nil; want %v, receiver nil", p, wantErr)
+	}
+	if err.Error() != wantErr.Error() {
+		t.Errorf("SetProfile(%d)=%#v; want %v, receiver nil", p, err, wantErr)
+	}
+}
+
+func TestOption_SetProfile_InvalidProfile(t *testing.T) {
+	wantErr := errors.New("invalid profile. please pass any of 0 to 3")
+
+	p := otpauth.Profile(4)
+	o := &otpauth.Option{}
+	err := o.SetProfile(p)
+	if err == nil {
+		t.Fatalf("SetProfile(%d)=nil; want %v, receiver %#v", p, wantErr, o)
+	}
+	if err.Error() != wantErr.Error() {
+		t.Errorf("SetProfile(%d)=%#v; want %v, receiver %#v", p, err, wantErr, o)
+	}
+}
+
 func TestNewOption(t *testing.T) {
 	want := otpauth.DefaultOption()
 
@@ -6,7 +6,6 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
-	"strconv"
 
 	"github.com/skip2/go-qrcode"
 )
@@ -15,10 +14,14 @@ const qrCodeSize = 256
 
 var base32NoPadding = base32.StdEncoding.WithPadding(base32.NoPadding)
 
+// ErrOtpAuthIsNil is an error when the otpauth is nil
+var ErrOtpAuthIsNil = errors.New("otpauth is nil")
+
 // OtpAuth has an optauth url and a secret key
 type OtpAuth struct {
-	url    string
-	secret string
+	url      string
+	secret   string
+	warnings []Warning
 }
 
 // URL returns an url that is included in otpAuth
@@ -39,6 +42,24 @@ func (oa *OtpAuth) Secret() string {
 	return oa.secret
 }
 
+// Warnings returns settings that the authenticator app of the profile doesn't support
+func (oa *OtpAuth) Warnings() []Warning {
+	if oa == nil {
+		return nil
+	}
+
+	return oa.warnings
+}
+
+// Key returns the parameters of the otpauth url
+func (oa *OtpAuth) Key() (*Key, error) {
+	if oa == nil {
+		return nil, ErrOtpAuthIsNil
+	}
+
+	return ParseURL(oa.url)
+}
+
 // QRCode returns value is the base64 encoded image data
 func (oa *OtpAuth) QRCode() (string, error) {
 	qr, err := qrcode.New(oa.URL(), qrcode.Medium)
@@ -70,8 +91,18 @@ func GenerateOtpAuthWithOption(issuer, accountName string, host Host, opt *Optio
 	if err != nil {
 		return nil, err
 	}
+	err = validateOption(host, opt)
+	if err != nil {
+		return nil, err
+	}
 
 	secret := opt.Secret()
+	if len(secret) == 0 && opt.derivation != nil {
+		secret, err = opt.derivation.Secret(opt.secretSize)
+		if err != nil {
+			return nil, err
+		}
+	}
 	if len(secret) == 0 {
 		secretBytes := make([]byte, opt.secretSize)
 		_, err := opt.rand.Read(secretBytes)
@@ -81,16 +112,8 @@ func GenerateOtpAuthWithOption(issuer, accountName string, host Host, opt *Optio
 		secret = base32NoPadding.EncodeToString(secretBytes)
 	}
 
-	v := url.Values{}
-	v.Set("issuer", issuer)
-	v.Set("period", strconv.FormatUint(uint64(opt.period), 10))
-	v.Set("algorithm", opt.algorithm.name())
-	v.Set("digits", fmt.Sprintf("%d", opt.digits))
-	v.Set("secret", secret)
-
-	if opt.iconURL != "" {
-		v.Set("icon", opt.iconURL)
-	}
+	v, warnings := opt.profile.values(issuer, secret, host, opt)
+	warnings = append(warnings, opt.warnings(opt.policy, host)...)
 
 	u := url.URL{
 		Scheme:   opt.scheme,
@@ -100,8 +123,9 @@ func GenerateOtpAuthWithOption(issuer, accountName string, host Host, opt *Optio
 	}
 
 	return &OtpAuth{
-		url:    u.String(),
-		secret: secret,
+		url:      u.String(),
+		secret:   secret,
+		warnings: warnings,
 	}, nil
 }
 
@@ -118,3 +142,22 @@ func validate(issuer, accountName string, host Host) error {
 
 	return nil
 }
+
+// validateOption validates that the option has the parameters required by the host
+// See: https://github.com/google/google-authenticator/wiki/Key-Uri-Format
+func validateOption(host Host, opt *Option) error {
+	if opt == nil {
+		return ErrOtpAuthOptionIsNil
+	}
+	if host == HostTOTP && opt.period == 0 {
+		return errors.New("period is required for totp. please pass greater than 0")
+	}
+	if opt.rand == nil && opt.derivation == nil && opt.secret == "" {
+		return errors.New("secret is required. please set a secret or a reader for generating secret")
+	}
+	if err := opt.policy.Enforce(opt.warnings(opt.policy, host)); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -61,6 +61,28 @@ func TestOtpAuth_QRCode(t *testing.T) {
 	}
 }
 
+func TestOtpAuth_Key(t *testing.T) {
+	o, _ := otpauth.NewOption()
+	_ = o.SetSecret("JXVF3ZJE2U52WP3B77D77VQJ3J3VYDUZ")
+	_ = o.SetDigits(otpauth.DigitsEight)
+
+	oa, _ := otpauth.GenerateOtpAuthWithOption("TEST_ISSUER", "TEST_ACCOUNT_NAME", otpauth.HostTOTP, o)
+	got, err := oa.Key()
+	if err != nil {
+		t.Fatalf("Key()=_, %#v; want nil", err)
+	}
+	if got.Issuer != "TEST_ISSUER" || got.AccountName != "TEST_ACCOUNT_NAME" || got.Secret != "JXVF3ZJE2U52WP3B77D77VQJ3J3VYDUZ" || got.Digits != otpauth.DigitsEight {
+		t.Errorf("Key()=%+v, _; want the parameters of %s", got, oa.URL())
+	}
+}
+
+func TestOtpAuth_Key_Nil(t *testing.T) {
+	var oa *otpauth.OtpAuth
+	if _, err := oa.Key(); err != otpauth.ErrOtpAuthIsNil {
+		t.Errorf("Key()=_, %#v; want %v", err, otpauth.ErrOtpAuthIsNil)
+	}
+}
+
 func TestGenerateOtpAuth_IssuerIsEmpty(t *testing.T) {
 	wantErr := errors.New("issuer is empty")
 
@@ -105,3 +127,74 @@ func TestGenerateOtpAuth_InvalidHost(t *testing.T) {
 		t.Errorf("GenerateOtpAuth(%s, %s, %d)=_, %#v; want %d", issuer, accountName, host, err, wantErr)
 	}
 }
+
+func TestGenerateOtpAuthWithOption_HOTP(t *testing.T) {
+	want := "otpauth://hotp/TEST_ISSUER:TEST_ACCOUNT_NAME?algorithm=SHA1&counter=5&digits=6&issuer=TEST_ISSUER&secret=JXVF3ZJE2U52WP3B77D77VQJ3J3VYDUZ"
+
+	issuer := "TEST_ISSUER"
+	accountName := "TEST_ACCOUNT_NAME"
+	host := otpauth.HostHOTP
+	o, _ := otpauth.NewOption()
+	_ = o.SetSecret("JXVF3ZJE2U52WP3B77D77VQJ3J3VYDUZ")
+	_ = o.SetCounter(5)
+
+	oa, err := otpauth.GenerateOtpAuthWithOption(issuer, accountName, host, o)
+	if err != nil {
+		t.Fatalf("GenerateOtpAuthWithOption(%s, %s, %d, %v)=_, %#v; want nil", issuer, accountName, host, o, err)
+	}
+	if got := oa.URL(); got != want {
+		t.Errorf("GenerateOtpAuthWithOption(%s, %s, %d, %v).URL()=%s; want %s", issuer, accountName, host, o, got, want)
+	}
+}
+
+func TestGenerateOtpAuthWithOption_TOTP(t *testing.T) {
+	want := "otpauth://totp/TEST_ISSUER:TEST_ACCOUNT_NAME?algorithm=SHA1&digits=6&issuer=TEST_ISSUER&period=30&secret=JXVF3ZJE2U52WP3B77D77VQJ3J3VYDUZ"
+
+	issuer := "TEST_ISSUER"
+	accountName := "TEST_ACCOUNT_NAME"
+	host := otpauth.HostTOTP
+	o, _ := otpauth.NewOption()
+	_ = o.SetSecret("JXVF3ZJE2U52WP3B77D77VQJ3J3VYDUZ")
+	_ = o.SetCounter(5)
+
+	oa, err := otpauth.GenerateOtpAuthWithOption(issuer, accountName, host, o)
+	if err != nil {
+		t.Fatalf("GenerateOtpAuthWithOption(%s, %s, %d, %v)=_, %#v; want nil", issuer, accountName, host, o, err)
+	}
+	if got := oa.URL(); got != want {
+		t.Errorf("GenerateOtpAuthWithOption(%s, %s, %d, %v).URL()=%s; want %s", issuer, accountName, host, o, got, want)
+	}
+}
+
+func TestGenerateOtpAuthWithOption_OptionIsNil(t *testing.T) {
+	wantErr := otpauth.ErrOtpAuthOptionIsNil
+
+	issuer := "TEST_ISSUER"
+	accountName := "TEST_ACCOUNT_NAME"
+	host := otpauth.HostHOTP
+	_, err := otpauth.GenerateOtpAuthWithOption(issuer, accountName, host, nil)
+	if err == nil {
+		t.Fatalf("GenerateOtpAuthWithOption(%s, %s, %d, nil)=_, nil; want %v", issuer, accountName, host, wantErr)
+	}
+	if err.Error() != wantErr.Error() {
+		t.Errorf("GenerateOtpAuthWithOption(%s, %s, %d, nil)=_, %#v; want %v", issuer, accountName, host, err, wantErr)
+	}
+}
+
+func TestGenerateOtpAuthWithOption_PeriodIsMissing(t *testing.T) {
+	wantErr := errors.New("period is required for totp. please pass greater than 0")
+
+	issuer := "TEST_ISSUER"
+	accountName := "TEST_ACCOUNT_NAME"
+	host := otpauth.HostTOTP
+	o := &otpauth.Option{}
+	_ = o.SetSecret("JXVF3ZJE2U52WP3B77D77VQJ3J3VYDUZ")
+
+	_, err := otpauth.GenerateOtpAuthWithOption(issuer, accountName, host, o)
+	if err == nil {
+		t.Fatalf("GenerateOtpAuthWithOption(%s, %s, %d, %v)=_, nil; want %v", issuer, accountName, host, o, wantErr)
+	}
+	if err.Error() != wantErr.Error() {
+		t.Errorf("GenerateOtpAuthWithOption(%s, %s, %d, %v)=_, %#v; want %v", issuer, accountName, host, o, err, wantErr)
+	}
+}
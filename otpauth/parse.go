@@ -0,0 +1,218 @@
+package otpauth
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ErrKeyIsNil is an error when the key is nil
+var ErrKeyIsNil = errors.New("key is nil")
+
+// Key is the parameters of an otpauth URI
+// See: https://github.com/google/google-authenticator/wiki/Key-Uri-Format
+type Key struct {
+	// Host is the type of the one time password
+	Host Host
+	// Issuer is the issuing organization or company
+	Issuer string
+	// AccountName is the user's account name or email address
+	AccountName string
+	// Secret is the base32 encoded secret without padding
+	Secret string
+	// Algorithm is the hash function to use in the HMAC operation
+	Algorithm Algorithm
+	// Digits is the number of digits
+	Digits Digits
+	// Period is the seconds that a Time-based One Time Password hash is valid
+	Period uint
+	// Counter is the initial counter of HMAC-based One Time Password
+	Counter uint64
+}
+
+// ParseURL parses an otpauth URI into a key
+// The secret is accepted in lower case, with spaces or with padding, and is normalized
+// The parameters that are omitted have the default values
+func ParseURL(rawURL string) (*Key, error) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(u.Scheme, defaultScheme) {
+		return nil, fmt.Errorf("invalid scheme %q. please pass %s", u.Scheme, defaultScheme)
+	}
+
+	k := &Key{
+		Algorithm: AlgorithmSHA1,
+		Digits:    DigitsSix,
+		Period:    DefaultPeriod,
+	}
+	switch strings.ToLower(u.Host) {
+	case HostHOTP.name():
+		k.Host = HostHOTP
+	case HostTOTP.name():
+		k.Host = HostTOTP
+	default:
+		return nil, fmt.Errorf("invalid host %q. please pass %s or %s", u.Host, HostHOTP.name(), HostTOTP.name())
+	}
+
+	// the label is `issuer:accountName` or `accountName`, and the issuer parameter takes precedence
+	label := strings.TrimPrefix(u.Path, "/")
+	if i := strings.Index(label, ":"); i >= 0 {
+		k.Issuer = strings.TrimSpace(label[:i])
+		label = label[i+1:]
+	}
+	k.AccountName = strings.TrimSpace(label)
+
+	q := u.Query()
+	if issuer := q.Get("issuer"); issuer != "" {
+		k.Issuer = issuer
+	}
+
+	k.Secret, err = normalizeSecret(q.Get("secret"))
+	if err != nil {
+		return nil, err
+	}
+
+	if v := q.Get("algorithm"); v != "" {
+		k.Algorithm, err = parseAlgorithm(v)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if v := q.Get("digits"); v != "" {
+		d, err := strconv.Atoi(v)
+		if err != nil || !Digits(d).Enabled() {
+			return nil, fmt.Errorf("invalid digits %q. please pass %d or %d", v, DigitsSix, DigitsEight)
+		}
+		k.Digits = Digits(d)
+	}
+	if v := q.Get("period"); v != "" {
+		p, err := strconv.ParseUint(v, 10, 32)
+		if err != nil || p == 0 {
+			return nil, fmt.Errorf("invalid period %q. please pass greater than 0", v)
+		}
+		k.Period = uint(p)
+	}
+	if k.Host == HostHOTP {
+		v := q.Get("counter")
+		if v == "" {
+			return nil, errors.New("counter is required for hotp")
+		}
+		k.Counter, err = strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid counter %q", v)
+		}
+	}
+
+	return k, nil
+}
+
+// Option returns an option that generates the otpauth URI of the key
+func (k *Key) Option() (*Option, error) {
+	if k == nil {
+		return nil, ErrKeyIsNil
+	}
+
+	opt, err := NewOption()
+	if err != nil {
+		return nil, err
+	}
+	if err := opt.SetAlgorithm(k.Algorithm); err != nil {
+		return nil, err
+	}
+	if err := opt.SetSecret(k.Secret); err != nil {
+		return nil, err
+	}
+	if err := opt.SetDigits(k.Digits); err != nil {
+		return nil, err
+	}
+	if k.Host == HostTOTP {
+		if err := opt.SetPeriod(k.Period); err != nil {
+			return nil, err
+		}
+	}
+	if err := opt.SetCounter(k.Counter); err != nil {
+		return nil, err
+	}
+
+	return opt, nil
+}
+
+// URL returns the otpauth URI of the key
+// Unlike GenerateOtpAuthWithOption, the issuer may be empty
+func (k *Key) URL() (string, error) {
+	if k == nil {
+		return "", ErrKeyIsNil
+	}
+	if !k.Host.enabled() {
+		return "", fmt.Errorf("invalid host. please pass %d or %d", HostHOTP, HostTOTP)
+	}
+	if !k.Algorithm.Enabled() {
+		return "", fmt.Errorf("invalid algorithm. please pass any of %d to %d", AlgorithmSHA1, AlgorithmMD5)
+	}
+	if !k.Digits.Enabled() {
+		return "", fmt.Errorf("invalid digits. please pass %d or %d", DigitsSix, DigitsEight)
+	}
+	if k.AccountName == "" {
+		return "", errors.New("accountName is empty")
+	}
+	secret, err := normalizeSecret(k.Secret)
+	if err != nil {
+		return "", err
+	}
+
+	v := url.Values{}
+	v.Set("secret", secret)
+	label := k.AccountName
+	if k.Issuer != "" {
+		v.Set("issuer", k.Issuer)
+		label = k.Issuer + ":" + label
+	}
+	switch k.Host {
+	case HostHOTP:
+		v.Set("counter", strconv.FormatUint(k.Counter, 10))
+	case HostTOTP:
+		if k.Period == 0 {
+			return "", errors.New("period is required for totp. please pass greater than 0")
+		}
+		v.Set("period", strconv.FormatUint(uint64(k.Period), 10))
+	}
+	v.Set("algorithm", k.Algorithm.name())
+	v.Set("digits", strconv.Itoa(int(k.Digits)))
+
+	u := url.URL{
+		Scheme:   defaultScheme,
+		Host:     k.Host.name(),
+		Path:     "/" + label,
+		RawQuery: v.Encode(),
+	}
+
+	return u.String(), nil
+}
+
+// normalizeSecret validates the base32 encoded secret and returns it in upper case without spaces and padding
+func normalizeSecret(secret string) (string, error) {
+	s := strings.ToUpper(strings.Join(strings.Fields(secret), ""))
+	s = strings.TrimRight(s, "=")
+	if s == "" {
+		return "", errors.New("secret is required")
+	}
+	if _, err := base32NoPadding.DecodeString(s); err != nil {
+		return "", errors.New("invalid secret. please pass base32 encoded secret")
+	}
+
+	return s, nil
+}
+
+func parseAlgorithm(s string) (Algorithm, error) {
+	for a := AlgorithmSHA1; a.Enabled(); a++ {
+		if strings.EqualFold(s, a.name()) {
+			return a, nil
+		}
+	}
+
+	return 0, fmt.Errorf("invalid algorithm %q. please pass any of SHA1, SHA256, SHA512 or MD5", s)
+}
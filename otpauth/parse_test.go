@@ -0,0 +1,101 @@
+package otpauth_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/butterv/one-time-password/otpauth"
+)
+
+func TestParseURL(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want *otpauth.Key
+	}{
+		{
+			name: "totp with every parameter",
+			in:   "otpauth://totp/butter:butter@example.com?algorithm=SHA256&digits=8&issuer=butter&period=60&secret=" + testSecret,
+			want: &otpauth.Key{Host: otpauth.HostTOTP, Issuer: "butter", AccountName: "butter@example.com", Secret: testSecret, Algorithm: otpauth.AlgorithmSHA256, Digits: otpauth.DigitsEight, Period: 60},
+		},
+		{
+			name: "totp with default values",
+			in:   "otpauth://totp/butter@example.com?secret=" + testSecret,
+			want: &otpauth.Key{Host: otpauth.HostTOTP, AccountName: "butter@example.com", Secret: testSecret, Algorithm: otpauth.AlgorithmSHA1, Digits: otpauth.DigitsSix, Period: 30},
+		},
+		{
+			name: "hotp with escaped label and lower case secret",
+			in:   "OTPAUTH://HOTP/Butter%20Company:butter%40example.com?counter=7&secret=" + "gezdgnbvgy3tqojq",
+			want: &otpauth.Key{Host: otpauth.HostHOTP, Issuer: "Butter Company", AccountName: "butter@example.com", Secret: "GEZDGNBVGY3TQOJQ", Algorithm: otpauth.AlgorithmSHA1, Digits: otpauth.DigitsSix, Period: 30, Counter: 7},
+		},
+		{
+			name: "issuer parameter takes precedence",
+			in:   "otpauth://totp/old:butter@example.com?issuer=new&secret=" + testSecret,
+			want: &otpauth.Key{Host: otpauth.HostTOTP, Issuer: "new", AccountName: "butter@example.com", Secret: testSecret, Algorithm: otpauth.AlgorithmSHA1, Digits: otpauth.DigitsSix, Period: 30},
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := otpauth.ParseURL(tt.in)
+		if err != nil {
+			t.Fatalf("%s: ParseURL(%s)=_, %#v; want nil", tt.name, tt.in, err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: ParseURL(%s)=%#v; want %#v", tt.name, tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseURL_Invalid(t *testing.T) {
+	tests := []string{
+		"https://totp/butter?secret=" + testSecret,
+		"otpauth://sms/butter?secret=" + testSecret,
+		"otpauth://totp/butter",
+		"otpauth://totp/butter?secret=1234",
+		"otpauth://totp/butter?algorithm=SHA3&secret=" + testSecret,
+		"otpauth://totp/butter?digits=7&secret=" + testSecret,
+		"otpauth://totp/butter?period=0&secret=" + testSecret,
+		"otpauth://hotp/butter?secret=" + testSecret,
+		"otpauth://hotp/butter?counter=-1&secret=" + testSecret,
+	}
+
+	for _, in := range tests {
+		if got, err := otpauth.ParseURL(in); err == nil {
+			t.Errorf("ParseURL(%s)=%#v, nil; want error", in, got)
+		}
+	}
+}
+
+func TestKey_URL(t *testing.T) {
+	tests := []string{
+		"otpauth://totp/butter:butter@example.com?algorithm=SHA256&digits=8&issuer=butter&period=60&secret=" + testSecret,
+		"otpauth://hotp/butter@example.com?algorithm=SHA1&counter=7&digits=6&secret=" + testSecret,
+	}
+
+	for _, in := range tests {
+		k, _ := otpauth.ParseURL(in)
+		got, err := k.URL()
+		if err != nil {
+			t.Fatalf("URL()=_, %#v; want nil", err)
+		}
+		if got != in {
+			t.Errorf("URL()=%s; want %s", got, in)
+		}
+	}
+}
+
+func TestKey_Option(t *testing.T) {
+	k, _ := otpauth.ParseURL("otpauth://hotp/butter:butter@example.com?algorithm=SHA512&counter=7&digits=8&secret=" + testSecret)
+	opt, err := k.Option()
+	if err != nil {
+		t.Fatalf("Option()=_, %#v; want nil", err)
+	}
+	if opt.Secret() != testSecret || opt.Algorithm() != otpauth.AlgorithmSHA512 || opt.Digits() != otpauth.DigitsEight || opt.Counter() != 7 {
+		t.Errorf("Option()=%#v; want option of %#v", opt, k)
+	}
+
+	var nilKey *otpauth.Key
+	if _, err := nilKey.Option(); err != otpauth.ErrKeyIsNil {
+		t.Errorf("Option()=_, %#v; want %v", err, otpauth.ErrKeyIsNil)
+	}
+}
@@ -0,0 +1,194 @@
+package otpauth
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	// minimumSecretSize is the minimum size of the secret required by RFC 4226
+	// See: https://tools.ietf.org/html/rfc4226#section-4
+	minimumSecretSize = 16
+
+	defaultMinSecretSize = minimumSecretSize
+	defaultMinPeriod     = 15
+)
+
+// ErrPolicyIsNil is an error when the policy is nil
+var ErrPolicyIsNil = errors.New("policy is nil")
+
+// Severity is the severity of a warning
+type Severity int
+
+const (
+	// SeverityNotice represents a setting that works but isn't recommended
+	SeverityNotice Severity = iota
+	// SeverityWeak represents a setting that weakens the security
+	// The policy in reject mode rejects the setting
+	SeverityWeak
+)
+
+// PolicyError is an error when the policy rejects weak configurations
+type PolicyError struct {
+	// Warnings is the weak configurations that are rejected
+	Warnings []Warning
+}
+
+// Error returns the rejected configurations
+func (e *PolicyError) Error() string {
+	ss := make([]string, 0, len(e.Warnings))
+	for _, w := range e.Warnings {
+		ss = append(ss, w.String())
+	}
+
+	return fmt.Sprintf("weak configuration is rejected: %s", strings.Join(ss, ", "))
+}
+
+// Policy validates the strength of configurations
+// A nil policy accepts every configuration without warnings
+type Policy struct {
+	// reject rejects weak configurations instead of flagging them
+	// The default value is false
+	reject bool
+	// minSecretSize is the minimum size of the secret in bytes
+	// The default value is 16 bytes (128 bits)
+	minSecretSize uint
+	// minPeriod is the minimum seconds of the period
+	// The default value is 15 seconds
+	minPeriod uint
+}
+
+// SetReject sets whether the policy rejects weak configurations
+func (p *Policy) SetReject(reject bool) error {
+	if p == nil {
+		return ErrPolicyIsNil
+	}
+
+	p.reject = reject
+	return nil
+}
+
+// SetMinSecretSize sets the minimum size of the secret in bytes
+func (p *Policy) SetMinSecretSize(size uint) error {
+	if p == nil {
+		return ErrPolicyIsNil
+	}
+	if size < minimumSecretSize {
+		return fmt.Errorf("invalid minSecretSize. please pass %d or greater", minimumSecretSize)
+	}
+
+	p.minSecretSize = size
+	return nil
+}
+
+// SetMinPeriod sets the minimum seconds of the period
+func (p *Policy) SetMinPeriod(period uint) error {
+	if p == nil {
+		return ErrPolicyIsNil
+	}
+	if period == 0 {
+		return errors.New("invalid minPeriod. please pass greater than 0")
+	}
+
+	p.minPeriod = period
+	return nil
+}
+
+// CheckAlgorithm checks the strength of the hash algorithm
+func (p *Policy) CheckAlgorithm(a Algorithm) []Warning {
+	if p == nil {
+		return nil
+	}
+
+	if a == AlgorithmMD5 {
+		return []Warning{{
+			Parameter: "algorithm",
+			Message:   "MD5 isn't permitted by RFC 4226 and RFC 6238",
+			Severity:  SeverityWeak,
+		}}
+	}
+
+	return nil
+}
+
+// CheckSecretSize checks the strength of the secret size in bytes
+// RFC 6238 recommends the secret as long as the output of the hash algorithm
+// See: https://tools.ietf.org/html/rfc6238#section-5.1
+func (p *Policy) CheckSecretSize(size uint, a Algorithm) []Warning {
+	if p == nil {
+		return nil
+	}
+
+	if size < p.minSecretSize {
+		return []Warning{{
+			Parameter: "secret",
+			Message:   fmt.Sprintf("%d bits is shorter than %d bits", size*8, p.minSecretSize*8),
+			Severity:  SeverityWeak,
+		}}
+	}
+	if a.Enabled() {
+		if hashSize := uint(a.Hash().Size()); size < hashSize {
+			return []Warning{{
+				Parameter: "secret",
+				Message:   fmt.Sprintf("%d bits is shorter than the %d bits output of %s", size*8, hashSize*8, a.name()),
+				Severity:  SeverityNotice,
+			}}
+		}
+	}
+
+	return nil
+}
+
+// CheckPeriod checks the strength of the period in seconds
+func (p *Policy) CheckPeriod(period uint) []Warning {
+	if p == nil {
+		return nil
+	}
+
+	if period < p.minPeriod {
+		return []Warning{{
+			Parameter: "period",
+			Message:   fmt.Sprintf("%d seconds is shorter than %d seconds", period, p.minPeriod),
+			Severity:  SeverityWeak,
+		}}
+	}
+
+	return nil
+}
+
+// Enforce returns an error when the policy rejects any of warnings
+// This returns nil when the policy flags weak configurations instead of rejecting them
+func (p *Policy) Enforce(warnings []Warning) error {
+	if p == nil || !p.reject {
+		return nil
+	}
+
+	var weak []Warning
+	for _, w := range warnings {
+		if w.Severity == SeverityWeak {
+			weak = append(weak, w)
+		}
+	}
+	if len(weak) > 0 {
+		return &PolicyError{Warnings: weak}
+	}
+
+	return nil
+}
+
+// NewPolicy generates a policy that flags weak configurations
+func NewPolicy() *Policy {
+	return &Policy{
+		reject:        false,
+		minSecretSize: defaultMinSecretSize,
+		minPeriod:     defaultMinPeriod,
+	}
+}
+
+// NewStrictPolicy generates a policy that rejects weak configurations
+func NewStrictPolicy() *Policy {
+	p := NewPolicy()
+	p.reject = true
+	return p
+}
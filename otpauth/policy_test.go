@@ -0,0 +1,228 @@
+package otpauth_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/butterv/one-time-password/otpauth"
+)
+
+func TestPolicy_SetMinSecretSize_InvalidSize(t *testing.T) {
+	wantErr := errors.New("invalid minSecretSize. please pass 16 or greater")
+
+	size := uint(10)
+	p := otpauth.NewPolicy()
+	err := p.SetMinSecretSize(size)
+	if err == nil {
+		t.Fatalf("SetMinSecretSize(%d)=nil; want %v", size, wantErr)
+	}
+	if err.Error() != wantErr.Error() {
+		t.Errorf("SetMinSecretSize(%d)=%#v; want %v", size, err, wantErr)
+	}
+}
+
+func TestPolicy_SetMinPeriod_ErrPolicyIsNil(t *testing.T) {
+	wantErr := otpauth.ErrPolicyIsNil
+
+	period := uint(30)
+	var p *otpauth.Policy
+	err := p.SetMinPeriod(period)
+	if err == nil {
+		t.Fatalf("SetMinPeriod(%d)=nil; want %v, receiver nil", period, wantErr)
+	}
+	if err.Error() != wantErr.Error() {
+		t.Errorf("SetMinPeriod(%d)=%#v; want %v, receiver nil", period, err, wantErr)
+	}
+}
+
+func TestPolicy_CheckAlgorithm(t *testing.T) {
+	tests := []struct {
+		in   otpauth.Algorithm
+		want []otpauth.Warning
+	}{
+		{in: otpauth.AlgorithmSHA1, want: nil},
+		{in: otpauth.AlgorithmSHA512, want: nil},
+		{in: otpauth.AlgorithmMD5, want: []otpauth.Warning{
+			{Parameter: "algorithm", Message: "MD5 isn't permitted by RFC 4226 and RFC 6238", Severity: otpauth.SeverityWeak},
+		}},
+	}
+
+	p := otpauth.NewPolicy()
+	for _, tt := range tests {
+		got := p.CheckAlgorithm(tt.in)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("CheckAlgorithm(%d)=%v; want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestPolicy_CheckSecretSize(t *testing.T) {
+	tests := []struct {
+		size      uint
+		algorithm otpauth.Algorithm
+		want      []otpauth.Warning
+	}{
+		{size: 20, algorithm: otpauth.AlgorithmSHA1, want: nil},
+		{size: 1, algorithm: otpauth.AlgorithmSHA1, want: []otpauth.Warning{
+			{Parameter: "secret", Message: "8 bits is shorter than 128 bits", Severity: otpauth.SeverityWeak},
+		}},
+		{size: 16, algorithm: otpauth.AlgorithmSHA1, want: []otpauth.Warning{
+			{Parameter: "secret", Message: "128 bits is shorter than the 160 bits output of SHA1", Severity: otpauth.SeverityNotice},
+		}},
+		{size: 64, algorithm: otpauth.AlgorithmSHA512, want: nil},
+	}
+
+	p := otpauth.NewPolicy()
+	for _, tt := range tests {
+		got := p.CheckSecretSize(tt.size, tt.algorithm)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("CheckSecretSize(%d, %d)=%v; want %v", tt.size, tt.algorithm, got, tt.want)
+		}
+	}
+}
+
+func TestPolicy_CheckPeriod(t *testing.T) {
+	tests := []struct {
+		in   uint
+		want []otpauth.Warning
+	}{
+		{in: 30, want: nil},
+		{in: 15, want: nil},
+		{in: 5, want: []otpauth.Warning{
+			{Parameter: "period", Message: "5 seconds is shorter than 15 seconds", Severity: otpauth.SeverityWeak},
+		}},
+	}
+
+	p := otpauth.NewPolicy()
+	for _, tt := range tests {
+		got := p.CheckPeriod(tt.in)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("CheckPeriod(%d)=%v; want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestPolicy_Enforce(t *testing.T) {
+	weak := otpauth.Warning{Parameter: "algorithm", Message: "weak", Severity: otpauth.SeverityWeak}
+	notice := otpauth.Warning{Parameter: "secret", Message: "notice", Severity: otpauth.SeverityNotice}
+
+	if err := otpauth.NewPolicy().Enforce([]otpauth.Warning{weak}); err != nil {
+		t.Errorf("NewPolicy().Enforce(%v)=%#v; want nil", weak, err)
+	}
+	if err := otpauth.NewStrictPolicy().Enforce([]otpauth.Warning{notice}); err != nil {
+		t.Errorf("NewStrictPolicy().Enforce(%v)=%#v; want nil", notice, err)
+	}
+
+	err := otpauth.NewStrictPolicy().Enforce([]otpauth.Warning{weak, notice})
+	var policyErr *otpauth.PolicyError
+	if !errors.As(err, &policyErr) {
+		t.Fatalf("NewStrictPolicy().Enforce(%v, %v)=%#v; want *PolicyError", weak, notice, err)
+	}
+	if want := []otpauth.Warning{weak}; !reflect.DeepEqual(policyErr.Warnings, want) {
+		t.Errorf("Warnings: got %v, want %v", policyErr.Warnings, want)
+	}
+	if want := "weak configuration is rejected: algorithm: weak"; err.Error() != want {
+		t.Errorf("Error()=%s; want %s", err.Error(), want)
+	}
+}
+
+func TestOption_SetAlgorithm_StrictPolicy(t *testing.T) {
+	o, _ := otpauth.NewOption()
+	_ = o.SetPolicy(otpauth.NewStrictPolicy())
+
+	err := o.SetAlgorithm(otpauth.AlgorithmMD5)
+	var policyErr *otpauth.PolicyError
+	if !errors.As(err, &policyErr) {
+		t.Errorf("SetAlgorithm(%d)=%#v; want *PolicyError", otpauth.AlgorithmMD5, err)
+	}
+}
+
+func TestOption_SetSecretSize_StrictPolicy(t *testing.T) {
+	o, _ := otpauth.NewOption()
+	_ = o.SetPolicy(otpauth.NewStrictPolicy())
+
+	err := o.SetSecretSize(1)
+	var policyErr *otpauth.PolicyError
+	if !errors.As(err, &policyErr) {
+		t.Errorf("SetSecretSize(1)=%#v; want *PolicyError", err)
+	}
+}
+
+func TestOption_SetPolicy_RejectsCurrentOption(t *testing.T) {
+	o, _ := otpauth.NewOption()
+	_ = o.SetAlgorithm(otpauth.AlgorithmMD5)
+
+	err := o.SetPolicy(otpauth.NewStrictPolicy())
+	var policyErr *otpauth.PolicyError
+	if !errors.As(err, &policyErr) {
+		t.Errorf("SetPolicy(strict)=%#v; want *PolicyError", err)
+	}
+}
+
+func TestOption_Warnings(t *testing.T) {
+	want := []otpauth.Warning{
+		{Parameter: "algorithm", Message: "MD5 isn't permitted by RFC 4226 and RFC 6238", Severity: otpauth.SeverityWeak},
+		{Parameter: "secret", Message: "40 bits is shorter than 128 bits", Severity: otpauth.SeverityWeak},
+	}
+
+	o, _ := otpauth.NewOption()
+	_ = o.SetAlgorithm(otpauth.AlgorithmMD5)
+	_ = o.SetSecret("JBSWY3DP")
+
+	if got := o.Warnings(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Warnings()=%v; want %v", got, want)
+	}
+}
+
+func TestGenerateOtpAuthWithOption_PeriodWarnings(t *testing.T) {
+	periodWarning := otpauth.Warning{Parameter: "period", Message: "5 seconds is shorter than 15 seconds", Severity: otpauth.SeverityWeak}
+	tests := []struct {
+		host        otpauth.Host
+		wantWarning bool
+	}{
+		{host: otpauth.HostTOTP, wantWarning: true},
+		{host: otpauth.HostHOTP, wantWarning: false},
+	}
+
+	for _, tt := range tests {
+		o, _ := otpauth.NewOption()
+		_ = o.SetPeriod(5)
+
+		oa, err := otpauth.GenerateOtpAuthWithOption("butter", "butter@example.com", tt.host, o)
+		if err != nil {
+			t.Fatalf("GenerateOtpAuthWithOption(%d)=_, %#v; want nil", tt.host, err)
+		}
+		got := false
+		for _, w := range oa.Warnings() {
+			if w == periodWarning {
+				got = true
+			}
+		}
+		if got != tt.wantWarning {
+			t.Errorf("GenerateOtpAuthWithOption(%d) warns the period %t; want %t, warnings %v", tt.host, got, tt.wantWarning, oa.Warnings())
+		}
+	}
+}
+
+func TestGenerateOtpAuthWithOption_MD5(t *testing.T) {
+	o, _ := otpauth.NewOption()
+	_ = o.SetAlgorithm(otpauth.AlgorithmMD5)
+	oa, err := otpauth.GenerateOtpAuthWithOption("butter", "butter@example.com", otpauth.HostHOTP, o)
+	if err != nil {
+		t.Fatalf("GenerateOtpAuthWithOption()=_, %#v; want nil", err)
+	}
+	weak := false
+	for _, w := range oa.Warnings() {
+		if w.Parameter == "algorithm" && w.Severity == otpauth.SeverityWeak {
+			weak = true
+		}
+	}
+	if !weak {
+		t.Errorf("GenerateOtpAuthWithOption() with MD5 warns %v; want the weak algorithm", oa.Warnings())
+	}
+
+	if err := otpauth.NewStrictPolicy().Enforce(otpauth.NewPolicy().CheckAlgorithm(otpauth.AlgorithmMD5)); err == nil {
+		t.Error("Enforce() of MD5 with the strict policy=nil; want error")
+	}
+}
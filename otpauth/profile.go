@@ -0,0 +1,225 @@
+package otpauth
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Profile is the profile of the authenticator app that reads the otpauth URI
+type Profile int
+
+const (
+	// ProfileFull is the profile that emits every parameter
+	// This profile emits default-valued parameters and all of extension parameters
+	ProfileFull Profile = iota
+	// ProfileGoogle is the strict profile for Google Authenticator
+	// This profile omits default-valued parameters and doesn't emit extension parameters
+	ProfileGoogle
+	// ProfileFreeOTP is the profile for FreeOTP
+	// This profile omits default-valued parameters and emits `image` and `color` parameters
+	ProfileFreeOTP
+	// ProfileMicrosoft is the profile for Microsoft Authenticator
+	// This profile omits default-valued parameters and doesn't emit extension parameters
+	ProfileMicrosoft
+)
+
+// Warning represents a setting that may not work as expected
+type Warning struct {
+	// Parameter is the name of the parameter which the warning is about
+	Parameter string
+	// Message is the reason of the warning
+	Message string
+	// Severity is the severity of the warning
+	Severity Severity
+}
+
+// String returns a human readable warning
+func (w Warning) String() string {
+	return fmt.Sprintf("%s: %s", w.Parameter, w.Message)
+}
+
+// profileSpec describes the parameters that an authenticator app supports
+type profileSpec struct {
+	// name is the name of the authenticator app
+	name string
+	// omitDefaults omits parameters that have the default value
+	omitDefaults bool
+	// hosts is the supported hosts
+	hosts []Host
+	// algorithms is the supported algorithms
+	// nil means all algorithms are supported
+	algorithms []Algorithm
+	// digits is the supported digits
+	// nil means all digits are supported
+	digits []Digits
+	// periods is the supported periods
+	// nil means all periods are supported
+	periods []uint
+	// iconParameter is the parameter name of the icon url
+	// empty means the icon isn't supported
+	iconParameter string
+	// color reports whether the `color` parameter is supported
+	color bool
+	// tags reports whether the `tags` parameter is supported
+	tags bool
+}
+
+var profileSpecs = map[Profile]profileSpec{
+	ProfileFull: {
+		name:          "full",
+		hosts:         []Host{HostHOTP, HostTOTP},
+		iconParameter: "icon",
+		color:         true,
+		tags:          true,
+	},
+	ProfileGoogle: {
+		name:         "Google Authenticator",
+		omitDefaults: true,
+		hosts:        []Host{HostHOTP, HostTOTP},
+		algorithms:   []Algorithm{AlgorithmSHA1},
+		digits:       []Digits{DigitsSix},
+		periods:      []uint{DefaultPeriod},
+	},
+	ProfileFreeOTP: {
+		name:          "FreeOTP",
+		omitDefaults:  true,
+		hosts:         []Host{HostHOTP, HostTOTP},
+		iconParameter: "image",
+		color:         true,
+	},
+	ProfileMicrosoft: {
+		name:         "Microsoft Authenticator",
+		omitDefaults: true,
+		hosts:        []Host{HostTOTP},
+		algorithms:   []Algorithm{AlgorithmSHA1},
+		digits:       []Digits{DigitsSix, DigitsEight},
+		periods:      []uint{DefaultPeriod},
+	},
+}
+
+func (p Profile) enabled() bool {
+	return p >= ProfileFull && p <= ProfileMicrosoft
+}
+
+func (p Profile) spec() profileSpec {
+	spec, ok := profileSpecs[p]
+	if !ok {
+		panic("invalid profile")
+	}
+
+	return spec
+}
+
+// values builds the query parameters of otpauth URI for the profile
+// This returns warnings when the option has settings that the authenticator app doesn't support
+func (p Profile) values(issuer, secret string, host Host, opt *Option) (url.Values, []Warning) {
+	spec := p.spec()
+
+	var warnings []Warning
+	warn := func(parameter, format string, args ...interface{}) {
+		warnings = append(warnings, Warning{
+			Parameter: parameter,
+			Message:   fmt.Sprintf("%s %s", spec.name, fmt.Sprintf(format, args...)),
+		})
+	}
+
+	v := url.Values{}
+	v.Set("issuer", issuer)
+	v.Set("secret", secret)
+
+	if !containsHost(spec.hosts, host) {
+		warn("host", "doesn't support %s", host.name())
+	}
+
+	switch host {
+	case HostHOTP:
+		v.Set("counter", strconv.FormatUint(opt.counter, 10))
+	case HostTOTP:
+		if !spec.omitDefaults || opt.period != DefaultPeriod {
+			v.Set("period", strconv.FormatUint(uint64(opt.period), 10))
+		}
+		if spec.periods != nil && !containsPeriod(spec.periods, opt.period) {
+			warn("period", "ignores period %d", opt.period)
+		}
+	}
+
+	if !spec.omitDefaults || opt.algorithm != AlgorithmSHA1 {
+		v.Set("algorithm", opt.algorithm.name())
+	}
+	if spec.algorithms != nil && !containsAlgorithm(spec.algorithms, opt.algorithm) {
+		warn("algorithm", "doesn't support %s", opt.algorithm.name())
+	}
+
+	if !spec.omitDefaults || opt.digits != DigitsSix {
+		v.Set("digits", fmt.Sprintf("%d", opt.digits))
+	}
+	if spec.digits != nil && !containsDigits(spec.digits, opt.digits) {
+		warn("digits", "doesn't support %d digits", opt.digits)
+	}
+
+	if opt.iconURL != "" {
+		if spec.iconParameter != "" {
+			v.Set(spec.iconParameter, opt.iconURL)
+		} else {
+			warn("icon", "doesn't support icon")
+		}
+	}
+	if opt.color != "" {
+		if spec.color {
+			v.Set("color", opt.color)
+		} else {
+			warn("color", "doesn't support color")
+		}
+	}
+	if len(opt.tags) > 0 {
+		if spec.tags {
+			v.Set("tags", strings.Join(opt.tags, ","))
+		} else {
+			warn("tags", "doesn't support tags")
+		}
+	}
+
+	return v, warnings
+}
+
+func containsHost(hosts []Host, h Host) bool {
+	for _, host := range hosts {
+		if host == h {
+			return true
+		}
+	}
+
+	return false
+}
+
+func containsAlgorithm(algorithms []Algorithm, a Algorithm) bool {
+	for _, algorithm := range algorithms {
+		if algorithm == a {
+			return true
+		}
+	}
+
+	return false
+}
+
+func containsDigits(digits []Digits, d Digits) bool {
+	for _, digit := range digits {
+		if digit == d {
+			return true
+		}
+	}
+
+	return false
+}
+
+func containsPeriod(periods []uint, p uint) bool {
+	for _, period := range periods {
+		if period == p {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,133 @@
+package otpauth_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/butterv/one-time-password/otpauth"
+)
+
+const testSecret = "JXVF3ZJE2U52WP3B77D77VQJ3J3VYDUZ"
+
+func TestProfile_Enabled(t *testing.T) {
+	tests := []struct {
+		in   otpauth.Profile
+		want bool
+	}{
+		{in: otpauth.ProfileFull, want: true},
+		{in: otpauth.ProfileGoogle, want: true},
+		{in: otpauth.ProfileFreeOTP, want: true},
+		{in: otpauth.ProfileMicrosoft, want: true},
+		{in: 4, want: false},
+		{in: -1, want: false},
+	}
+
+	for _, tt := range tests {
+		got := otpauth.ExportProfileEnabled(tt.in)
+		if got != tt.want {
+			t.Errorf("ExportProfileEnabled(%d)=%v; want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestWarning_String(t *testing.T) {
+	want := "digits: FreeOTP doesn't support 8 digits"
+
+	w := otpauth.Warning{Parameter: "digits", Message: "FreeOTP doesn't support 8 digits"}
+	if got := w.String(); got != want {
+		t.Errorf("String()=%s; want %s, receiver %#v", got, want, w)
+	}
+}
+
+func TestGenerateOtpAuthWithOption_Profile(t *testing.T) {
+	tests := []struct {
+		name         string
+		profile      otpauth.Profile
+		host         otpauth.Host
+		setup        func(o *otpauth.Option)
+		want         string
+		wantWarnings []otpauth.Warning
+	}{
+		{
+			name:    "full emits extension parameters",
+			profile: otpauth.ProfileFull,
+			host:    otpauth.HostTOTP,
+			setup: func(o *otpauth.Option) {
+				_ = o.SetIconURL("https://example.com/icon.png")
+				_ = o.SetColor("1E90FF")
+				_ = o.SetTags("work", "aws")
+			},
+			want: "otpauth://totp/ISSUER:ACCOUNT?algorithm=SHA1&color=1E90FF&digits=6&icon=https%3A%2F%2Fexample.com%2Ficon.png&issuer=ISSUER&period=30&secret=" + testSecret + "&tags=work%2Caws",
+		},
+		{
+			name:    "google omits default values",
+			profile: otpauth.ProfileGoogle,
+			host:    otpauth.HostTOTP,
+			setup:   func(o *otpauth.Option) {},
+			want:    "otpauth://totp/ISSUER:ACCOUNT?issuer=ISSUER&secret=" + testSecret,
+		},
+		{
+			name:    "google warns unsupported settings",
+			profile: otpauth.ProfileGoogle,
+			host:    otpauth.HostTOTP,
+			setup: func(o *otpauth.Option) {
+				_ = o.SetPeriod(60)
+				_ = o.SetAlgorithm(otpauth.AlgorithmSHA256)
+				_ = o.SetDigits(otpauth.DigitsEight)
+				_ = o.SetColor("1E90FF")
+			},
+			want: "otpauth://totp/ISSUER:ACCOUNT?algorithm=SHA256&digits=8&issuer=ISSUER&period=60&secret=" + testSecret,
+			wantWarnings: []otpauth.Warning{
+				{Parameter: "period", Message: "Google Authenticator ignores period 60"},
+				{Parameter: "algorithm", Message: "Google Authenticator doesn't support SHA256"},
+				{Parameter: "digits", Message: "Google Authenticator doesn't support 8 digits"},
+				{Parameter: "color", Message: "Google Authenticator doesn't support color"},
+				{Parameter: "secret", Message: "160 bits is shorter than the 256 bits output of SHA256", Severity: otpauth.SeverityNotice},
+			},
+		},
+		{
+			name:    "freeotp emits image and color",
+			profile: otpauth.ProfileFreeOTP,
+			host:    otpauth.HostHOTP,
+			setup: func(o *otpauth.Option) {
+				_ = o.SetIconURL("https://example.com/icon.png")
+				_ = o.SetColor("1E90FF")
+				_ = o.SetTags("work")
+			},
+			want: "otpauth://hotp/ISSUER:ACCOUNT?color=1E90FF&counter=0&image=https%3A%2F%2Fexample.com%2Ficon.png&issuer=ISSUER&secret=" + testSecret,
+			wantWarnings: []otpauth.Warning{
+				{Parameter: "tags", Message: "FreeOTP doesn't support tags"},
+			},
+		},
+		{
+			name:    "microsoft warns hotp",
+			profile: otpauth.ProfileMicrosoft,
+			host:    otpauth.HostHOTP,
+			setup:   func(o *otpauth.Option) {},
+			want:    "otpauth://hotp/ISSUER:ACCOUNT?counter=0&issuer=ISSUER&secret=" + testSecret,
+			wantWarnings: []otpauth.Warning{
+				{Parameter: "host", Message: "Microsoft Authenticator doesn't support hotp"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o, _ := otpauth.NewOption()
+			_ = o.SetSecret(testSecret)
+			_ = o.SetProfile(tt.profile)
+			tt.setup(o)
+
+			oa, err := otpauth.GenerateOtpAuthWithOption("ISSUER", "ACCOUNT", tt.host, o)
+			if err != nil {
+				t.Fatalf("GenerateOtpAuthWithOption(ISSUER, ACCOUNT, %d, %v)=_, %#v; want nil", tt.host, o, err)
+			}
+			if got := oa.URL(); got != tt.want {
+				t.Errorf("URL()=%s; want %s", got, tt.want)
+			}
+			if got := oa.Warnings(); !reflect.DeepEqual(got, tt.wantWarnings) {
+				t.Errorf("Warnings()=%v; want %v", got, tt.wantWarnings)
+			}
+		})
+	}
+}
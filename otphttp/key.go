@@ -0,0 +1,104 @@
+package otphttp
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/butterv/one-time-password/otpauth"
+)
+
+// ErrKeyNotFound is an error when the user doesn't have an active key
+var ErrKeyNotFound = errors.New("key is not found")
+
+// ErrKeyConflict is an error when the key has been updated by another request
+var ErrKeyConflict = errors.New("key has been updated by another request")
+
+// Key is an active key of a user
+type Key struct {
+	// Host is the type of the one time password
+	Host otpauth.Host
+	// Secret is the base32 encoded secret of the key
+	Secret string
+	// Algorithm is the hash algorithm of the key shown to the user
+	Algorithm otpauth.Algorithm
+	// Digits is the number of digits of the key shown to the user
+	Digits otpauth.Digits
+	// Period is the period of TOTP in seconds, which is 0 for HOTP
+	Period uint
+	// Counter is the next counter of HOTP
+	Counter uint64
+	// Step is the time step of the last valid TOTP passcode
+	// A passcode of the same or older step is rejected, so a passcode can be used only once
+	Step uint64
+}
+
+// KeyStore persists active keys of users
+type KeyStore interface {
+	// Get returns the key of the user
+	// This returns ErrKeyNotFound when the user doesn't have an active key
+	Get(ctx context.Context, userID string) (*Key, error)
+	// Save replaces the key of the user
+	Save(ctx context.Context, userID string, key *Key) error
+	// CompareAndSwap replaces the key of the user with next only when the stored key equals old
+	// The counter and the time step are advanced through this, so concurrent requests never accept the same passcode twice
+	// This returns ErrKeyConflict when the key has been updated by another request
+	CompareAndSwap(ctx context.Context, userID string, old, next *Key) error
+}
+
+// MemoryKeyStore is a KeyStore that holds keys in memory
+type MemoryKeyStore struct {
+	mu   sync.Mutex
+	keys map[string]Key
+}
+
+// NewMemoryKeyStore generates an empty key store in memory
+func NewMemoryKeyStore() *MemoryKeyStore {
+	return &MemoryKeyStore{
+		keys: map[string]Key{},
+	}
+}
+
+// Get returns the key of the user
+func (s *MemoryKeyStore) Get(_ context.Context, userID string) (*Key, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	k, ok := s.keys[userID]
+	if !ok {
+		return nil, ErrKeyNotFound
+	}
+	return &k, nil
+}
+
+// Save replaces the key of the user
+func (s *MemoryKeyStore) Save(_ context.Context, userID string, key *Key) error {
+	if key == nil {
+		return errors.New("key is nil")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys[userID] = *key
+	return nil
+}
+
+// CompareAndSwap replaces the key of the user with next only when the stored key equals old
+func (s *MemoryKeyStore) CompareAndSwap(_ context.Context, userID string, old, next *Key) error {
+	if old == nil || next == nil {
+		return errors.New("key is nil")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.keys[userID]
+	if !ok {
+		return ErrKeyNotFound
+	}
+	if stored != *old {
+		return ErrKeyConflict
+	}
+	s.keys[userID] = *next
+	return nil
+}
@@ -0,0 +1,631 @@
+package otphttp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/butterv/one-time-password/enrollment"
+	"github.com/butterv/one-time-password/hotp"
+	"github.com/butterv/one-time-password/otpauth"
+	"github.com/butterv/one-time-password/recovery"
+	"github.com/butterv/one-time-password/throttle"
+	"github.com/butterv/one-time-password/totp"
+)
+
+const (
+	// maxRequestBodySize is the maximum size of a request body in bytes
+	maxRequestBodySize = 4096
+	defaultLookAhead   = uint(10)
+	defaultSkew        = uint(1)
+)
+
+var (
+	// ErrServerIsNil is an error when the server is nil
+	ErrServerIsNil = errors.New("server is nil")
+	// ErrUnauthenticated is an error when the request doesn't identify a user
+	// UserIDFunc returns this error to respond 401 Unauthorized
+	ErrUnauthenticated = errors.New("unauthenticated")
+	// ErrKeyActive is an error when the user enrolls a key without the recent verification of the active key
+	ErrKeyActive = errors.New("key is already active. please verify the active key to replace it")
+)
+
+// UserIDFunc identifies the user of the request
+// This is typically backed by the session or the token that the first factor authentication issues
+type UserIDFunc func(r *http.Request) (string, error)
+
+// StartEnrollmentRequest is the request body of StartEnrollment
+type StartEnrollmentRequest struct {
+	// AccountName is the account name shown in the authenticator app
+	AccountName string `json:"account_name"`
+	// Type is the type of the one time password, `totp` or `hotp`
+	// The default value is `totp`
+	Type string `json:"type"`
+}
+
+// StartEnrollmentResponse is the response body of StartEnrollment
+type StartEnrollmentResponse struct {
+	// EnrollmentID is the identifier of the pending enrollment passed to ConfirmEnrollment
+	EnrollmentID string `json:"enrollment_id"`
+	// URL is the otpauth URI of the pending key
+	URL string `json:"url"`
+	// QRCode is the base64 encoded image data of the otpauth URI
+	QRCode string `json:"qr_code"`
+	// ExpiresAt is the time when the pending enrollment expires
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// ConfirmEnrollmentRequest is the request body of ConfirmEnrollment
+type ConfirmEnrollmentRequest struct {
+	// EnrollmentID is the identifier returned by StartEnrollment
+	EnrollmentID string `json:"enrollment_id"`
+	// Passcode is the one time password shown in the authenticator app
+	Passcode string `json:"passcode"`
+}
+
+// ConfirmEnrollmentResponse is the response body of ConfirmEnrollment
+type ConfirmEnrollmentResponse struct {
+	// State is the state of the enrollment, `pending` or `active`
+	State string `json:"state"`
+	// Confirmations is the count of consecutive valid passcodes so far
+	Confirmations uint `json:"confirmations"`
+}
+
+// VerifyRequest is the request body of Verify
+type VerifyRequest struct {
+	// Passcode is the one time password shown in the authenticator app
+	Passcode string `json:"passcode"`
+}
+
+// VerifyResponse is the response body of Verify
+type VerifyResponse struct {
+	// Valid reports whether the passcode is valid
+	Valid bool `json:"valid"`
+}
+
+// RecoveryRequest is the request body of Recover
+type RecoveryRequest struct {
+	// Code is the recovery code typed by the user
+	Code string `json:"code"`
+}
+
+// RecoveryResponse is the response body of Recover
+type RecoveryResponse struct {
+	// Remaining is the count of recovery codes that are not consumed
+	Remaining int `json:"remaining"`
+}
+
+// ErrorResponse is the response body when the request fails
+type ErrorResponse struct {
+	// Error is the reason of the failure
+	Error string `json:"error"`
+}
+
+// Server provides http.Handlers for enrolling keys and verifying passcodes and recovery codes
+type Server struct {
+	// issuer is the issuer of the otpauth URI
+	issuer string
+	// userID identifies the user of the request
+	userID UserIDFunc
+	// enrollments enrolls keys
+	enrollments *enrollment.Manager
+	// keys persists the active keys
+	keys KeyStore
+	// recoveries redeems recovery codes
+	// nil disables Recover
+	recoveries *recovery.Manager
+	// skew is the count of time steps back and forth that a TOTP passcode is accepted for
+	// The other settings of passcodes are the ones of each key
+	// The default value is 1
+	skew uint
+	// lookAhead is the count of counters ahead of the current one that a HOTP passcode is accepted for
+	// The default value is 10
+	lookAhead uint
+	// throttle limits attempts of Verify and Recover of each user
+	// nil disables throttling
+	throttle *throttle.Throttle
+	// stepUp sets the claim of the verification when the passcode is valid
+	// nil disables the claim
+	stepUp *StepUp
+	// now returns the current time
+	now func() time.Time
+}
+
+// NewServer generates a server by passing issuer, user identification, enrollment manager and key store
+func NewServer(issuer string, userID UserIDFunc, enrollments *enrollment.Manager, keys KeyStore) (*Server, error) {
+	if issuer == "" {
+		return nil, errors.New("issuer is empty")
+	}
+	if userID == nil {
+		return nil, errors.New("userID is nil")
+	}
+	if enrollments == nil {
+		return nil, enrollment.ErrManagerIsNil
+	}
+	if keys == nil {
+		return nil, errors.New("keys is nil")
+	}
+
+	return &Server{
+		issuer:      issuer,
+		userID:      userID,
+		enrollments: enrollments,
+		keys:        keys,
+		skew:        defaultSkew,
+		lookAhead:   defaultLookAhead,
+		now:         time.Now,
+	}, nil
+}
+
+// SetRecoveryManager sets a recovery code manager that Recover redeems recovery codes with
+func (s *Server) SetRecoveryManager(m *recovery.Manager) error {
+	if s == nil {
+		return ErrServerIsNil
+	}
+	if m == nil {
+		return recovery.ErrManagerIsNil
+	}
+
+	s.recoveries = m
+	return nil
+}
+
+// SetThrottle sets a throttle that limits attempts of Verify and Recover of each user
+// Verify and Recover share the attempts of the user, so guessing both is limited together
+func (s *Server) SetThrottle(th *throttle.Throttle) error {
+	if s == nil {
+		return ErrServerIsNil
+	}
+	if th == nil {
+		return throttle.ErrThrottleIsNil
+	}
+
+	s.throttle = th
+	return nil
+}
+
+// SetStepUp sets a step-up middleware that Verify sets the claim of the verification with
+func (s *Server) SetStepUp(su *StepUp) error {
+	if s == nil {
+		return ErrServerIsNil
+	}
+	if su == nil {
+		return ErrStepUpIsNil
+	}
+
+	s.stepUp = su
+	return nil
+}
+
+// SetSkew sets a count of time steps back and forth that a TOTP passcode is accepted for
+func (s *Server) SetSkew(skew uint) error {
+	if s == nil {
+		return ErrServerIsNil
+	}
+	if skew == 0 {
+		return errors.New("invalid skew. please pass greater than 0")
+	}
+
+	s.skew = skew
+	return nil
+}
+
+// SetLookAhead sets a count of counters ahead of the current one that a HOTP passcode is accepted for
+func (s *Server) SetLookAhead(lookAhead uint) error {
+	if s == nil {
+		return ErrServerIsNil
+	}
+
+	s.lookAhead = lookAhead
+	return nil
+}
+
+// SetClock sets a function that returns the current time
+func (s *Server) SetClock(now func() time.Time) error {
+	if s == nil {
+		return ErrServerIsNil
+	}
+	if now == nil {
+		return errors.New("now is nil")
+	}
+
+	s.now = now
+	return nil
+}
+
+// StartEnrollment returns a handler that creates a pending key of the user
+// The response has the otpauth URI and its QR code
+// When the user has an active key, the handler must be wrapped by StepUp.Require to replace it, or responds 409 Conflict
+func (s *Server) StartEnrollment() http.Handler {
+	return s.handle(func(w http.ResponseWriter, r *http.Request, userID string) {
+		var req StartEnrollmentRequest
+		if !decode(w, r, &req) {
+			return
+		}
+		if !s.checkReplaceable(w, r, userID) {
+			return
+		}
+
+		host, err := parseHost(req.Type)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if req.AccountName == "" {
+			writeError(w, http.StatusBadRequest, "account_name is empty")
+			return
+		}
+
+		e, oa, err := s.enrollments.Start(r.Context(), s.issuer, req.AccountName, userID, host, s.now())
+		if err != nil {
+			writeInternalError(w)
+			return
+		}
+		qr, err := oa.QRCode()
+		if err != nil {
+			writeInternalError(w)
+			return
+		}
+
+		writeJSON(w, http.StatusCreated, StartEnrollmentResponse{
+			EnrollmentID: e.ID,
+			URL:          oa.URL(),
+			QRCode:       qr,
+			ExpiresAt:    e.ExpiresAt,
+		})
+	})
+}
+
+// ConfirmEnrollment returns a handler that activates the pending key with the passcode
+// The activated key is saved in the key store
+// When the user has an active key, the handler must be wrapped by StepUp.Require to replace it, or responds 409 Conflict
+func (s *Server) ConfirmEnrollment() http.Handler {
+	return s.handle(func(w http.ResponseWriter, r *http.Request, userID string) {
+		var req ConfirmEnrollmentRequest
+		if !decode(w, r, &req) {
+			return
+		}
+		if !s.checkReplaceable(w, r, userID) {
+			return
+		}
+
+		ctx := r.Context()
+		e, err := s.enrollments.Get(ctx, req.EnrollmentID)
+		// the enrollment of another user is hidden as if it doesn't exist
+		if err == enrollment.ErrEnrollmentNotFound || (err == nil && e.UserID != userID) {
+			writeError(w, http.StatusNotFound, enrollment.ErrEnrollmentNotFound.Error())
+			return
+		}
+		if err != nil {
+			writeInternalError(w)
+			return
+		}
+
+		e, err = s.enrollments.Confirm(ctx, req.EnrollmentID, req.Passcode, s.now())
+		switch err {
+		case nil:
+		case enrollment.ErrInvalidPasscode:
+			writeError(w, http.StatusUnauthorized, err.Error())
+			return
+		case enrollment.ErrEnrollmentExpired:
+			writeError(w, http.StatusGone, err.Error())
+			return
+		case enrollment.ErrEnrollmentNotPending, enrollment.ErrConflict:
+			writeError(w, http.StatusConflict, err.Error())
+			return
+		default:
+			writeInternalError(w)
+			return
+		}
+
+		if e.State == enrollment.StateActive {
+			// the settings of the key shown to the user are saved, so the passcodes are verified as the authenticator app generates
+			k, err := otpauth.ParseURL(e.URL)
+			if err != nil {
+				writeInternalError(w)
+				return
+			}
+			period := k.Period
+			if e.Host != otpauth.HostTOTP {
+				period = 0
+			}
+			err = s.keys.Save(ctx, userID, &Key{
+				Host:      e.Host,
+				Secret:    e.Secret,
+				Algorithm: k.Algorithm,
+				Digits:    k.Digits,
+				Period:    period,
+				Counter:   e.Counter,
+				Step:      e.Step,
+			})
+			if err != nil {
+				writeInternalError(w)
+				return
+			}
+		}
+
+		writeJSON(w, http.StatusOK, ConfirmEnrollmentResponse{
+			State:         e.State.String(),
+			Confirmations: e.Confirmations,
+		})
+	})
+}
+
+// Verify returns a handler that verifies the passcode of the active key of the user
+// The counter of HOTP and the time step of TOTP are saved, so a passcode can be used only once
+// When the step-up middleware is set, the claim of the verification is set too
+func (s *Server) Verify() http.Handler {
+	return s.handle(func(w http.ResponseWriter, r *http.Request, userID string) {
+		var req VerifyRequest
+		if !decode(w, r, &req) {
+			return
+		}
+
+		ctx := r.Context()
+		key, err := s.keys.Get(ctx, userID)
+		if err == ErrKeyNotFound {
+			writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		if err != nil {
+			writeInternalError(w)
+			return
+		}
+
+		// the key is advanced only when it isn't updated by another request,
+		// so the same passcode sent concurrently is accepted only once
+		verify := func() (bool, error) {
+			old := *key
+			ok, err := s.verify(key, req.Passcode)
+			if err == otpauth.ErrInvalidDigitsLength {
+				return false, nil
+			}
+			if err != nil || !ok {
+				return ok, err
+			}
+			err = s.keys.CompareAndSwap(ctx, userID, &old, key)
+			if err == ErrKeyConflict || err == ErrKeyNotFound {
+				return false, nil
+			}
+			return err == nil, err
+		}
+		var ok bool
+		if s.throttle != nil {
+			ok, err = s.throttle.Verify(ctx, userID, s.now(), verify)
+		} else {
+			ok, err = verify()
+		}
+		if writeThrottled(w, err) {
+			return
+		}
+		if err != nil {
+			writeInternalError(w)
+			return
+		}
+		if !ok {
+			writeJSON(w, http.StatusUnauthorized, VerifyResponse{Valid: false})
+			return
+		}
+		if s.stepUp != nil {
+			if err := s.stepUp.SetVerified(w, r, s.now()); err != nil {
+				writeInternalError(w)
+				return
+			}
+		}
+
+		writeJSON(w, http.StatusOK, VerifyResponse{Valid: true})
+	})
+}
+
+// Recover returns a handler that redeems the recovery code of the user
+func (s *Server) Recover() http.Handler {
+	return s.handle(func(w http.ResponseWriter, r *http.Request, userID string) {
+		if s.recoveries == nil {
+			writeError(w, http.StatusNotFound, "recovery codes are not enabled")
+			return
+		}
+
+		var req RecoveryRequest
+		if !decode(w, r, &req) {
+			return
+		}
+
+		ctx := r.Context()
+		var err error
+		if s.throttle != nil {
+			err = s.throttle.RedeemRecoveryCode(ctx, userID, s.recoveries, userID, req.Code, s.now())
+		} else {
+			err = s.recoveries.Redeem(ctx, userID, req.Code)
+		}
+		if writeThrottled(w, err) {
+			return
+		}
+		switch err {
+		case nil:
+		case recovery.ErrInvalidFormat, recovery.ErrChecksumMismatch:
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		case recovery.ErrInvalidRecoveryCode, recovery.ErrRecoveryCodeConsumed:
+			writeError(w, http.StatusUnauthorized, recovery.ErrInvalidRecoveryCode.Error())
+			return
+		default:
+			writeInternalError(w)
+			return
+		}
+
+		remaining, err := s.recoveries.Remaining(ctx, userID)
+		if err != nil {
+			writeInternalError(w)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, RecoveryResponse{Remaining: remaining})
+	})
+}
+
+// checkReplaceable responds 409 Conflict when the user has an active key and the request isn't verified by StepUp.Require
+// This prevents a stolen session of the first factor from replacing the second factor
+func (s *Server) checkReplaceable(w http.ResponseWriter, r *http.Request, userID string) bool {
+	_, err := s.keys.Get(r.Context(), userID)
+	if err == ErrKeyNotFound {
+		return true
+	}
+	if err != nil {
+		writeInternalError(w)
+		return false
+	}
+	if _, ok := VerifiedAt(r.Context()); !ok {
+		writeError(w, http.StatusConflict, ErrKeyActive.Error())
+		return false
+	}
+
+	return true
+}
+
+// verify validates the passcode with the settings of the key and advances the counter or the time step of the key
+func (s *Server) verify(key *Key, passcode string) (bool, error) {
+	switch key.Host {
+	case otpauth.HostTOTP:
+		opt, err := s.totpOption(key)
+		if err != nil {
+			return false, err
+		}
+		step, ok, err := totp.ValidateStepWithOption(passcode, key.Secret, s.now(), opt)
+		if err != nil || !ok || step <= key.Step {
+			return false, err
+		}
+		key.Step = step
+		return true, nil
+	case otpauth.HostHOTP:
+		opt, err := hotpOption(key)
+		if err != nil {
+			return false, err
+		}
+		for i := uint64(0); i <= uint64(s.lookAhead); i++ {
+			ok, err := hotp.ValidateWithOption(passcode, key.Secret, key.Counter+i, opt)
+			if err != nil {
+				return false, err
+			}
+			if ok {
+				key.Counter += i + 1
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+
+	return false, fmt.Errorf("invalid host. please pass %d or %d", otpauth.HostHOTP, otpauth.HostTOTP)
+}
+
+// totpOption returns the option of TOTP with the period, the digits and the algorithm of the key
+// The key has been validated when it is enrolled, so the policy isn't enforced again
+func (s *Server) totpOption(key *Key) (*totp.Option, error) {
+	opt := totp.NewOption()
+	if err := opt.SetPolicy(nil); err != nil {
+		return nil, err
+	}
+	if err := opt.SetPeriod(key.Period); err != nil {
+		return nil, err
+	}
+	if err := opt.SetDigits(key.Digits); err != nil {
+		return nil, err
+	}
+	if err := opt.SetAlgorithm(key.Algorithm); err != nil {
+		return nil, err
+	}
+	if err := opt.SetSkew(s.skew); err != nil {
+		return nil, err
+	}
+
+	return opt, nil
+}
+
+// hotpOption returns the option of HOTP with the digits and the algorithm of the key
+func hotpOption(key *Key) (*hotp.Option, error) {
+	opt := hotp.NewOption()
+	if err := opt.SetPolicy(nil); err != nil {
+		return nil, err
+	}
+	if err := opt.SetDigits(key.Digits); err != nil {
+		return nil, err
+	}
+	if err := opt.SetAlgorithm(key.Algorithm); err != nil {
+		return nil, err
+	}
+
+	return opt, nil
+}
+
+// handle wraps a handler that accepts only POST requests of an identified user
+func (s *Server) handle(h func(w http.ResponseWriter, r *http.Request, userID string)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			writeError(w, http.StatusMethodNotAllowed, "method is not allowed")
+			return
+		}
+
+		userID, err := s.userID(r)
+		if err != nil || userID == "" {
+			writeError(w, http.StatusUnauthorized, ErrUnauthenticated.Error())
+			return
+		}
+
+		h(w, r, userID)
+	})
+}
+
+func parseHost(t string) (otpauth.Host, error) {
+	switch t {
+	case "", "totp":
+		return otpauth.HostTOTP, nil
+	case "hotp":
+		return otpauth.HostHOTP, nil
+	}
+
+	return 0, fmt.Errorf("invalid type %q. please pass totp or hotp", t)
+}
+
+// decode decodes the request body into v, and responds 400 Bad Request when the body is invalid
+func decode(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	d := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBodySize))
+	d.DisallowUnknownFields()
+	if err := d.Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return false
+	}
+
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeThrottled responds 429 Too Many Requests with Retry-After when the error is ThrottledError
+func writeThrottled(w http.ResponseWriter, err error) bool {
+	var te *throttle.ThrottledError
+	if !errors.As(err, &te) {
+		return false
+	}
+
+	w.Header().Set("Retry-After", strconv.FormatInt(int64(math.Ceil(te.RetryAfter.Seconds())), 10))
+	writeError(w, http.StatusTooManyRequests, te.Error())
+	return true
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, ErrorResponse{Error: message})
+}
+
+// writeInternalError responds 500 Internal Server Error without the detail of the error
+func writeInternalError(w http.ResponseWriter) {
+	writeError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+}
@@ -0,0 +1,389 @@
+package otphttp_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/butterv/one-time-password/enrollment"
+	"github.com/butterv/one-time-password/hotp"
+	"github.com/butterv/one-time-password/otpauth"
+	"github.com/butterv/one-time-password/otphttp"
+	"github.com/butterv/one-time-password/recovery"
+	"github.com/butterv/one-time-password/throttle"
+	"github.com/butterv/one-time-password/totp"
+)
+
+const secret = "3EOJMVMDTXHMHFQ3CK45R6NWIG4VWAQA"
+
+var now = time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC)
+
+// userID identifies the user by the header for testing
+func userID(r *http.Request) (string, error) {
+	id := r.Header.Get("X-User-ID")
+	if id == "" {
+		return "", otphttp.ErrUnauthenticated
+	}
+
+	return id, nil
+}
+
+func newServer(t *testing.T) (*otphttp.Server, *otphttp.MemoryKeyStore) {
+	t.Helper()
+
+	otpAuthOpt, _ := otpauth.NewOption()
+	_ = otpAuthOpt.SetSecret(secret)
+	opt, _ := enrollment.NewOption()
+	_ = opt.SetOtpAuthOption(otpAuthOpt)
+	enrollments, _ := enrollment.NewManager(enrollment.NewMemoryStore(), opt)
+
+	keys := otphttp.NewMemoryKeyStore()
+	s, err := otphttp.NewServer("butter", userID, enrollments, keys)
+	if err != nil {
+		t.Fatalf("NewServer()=_, %#v; want nil", err)
+	}
+	_ = s.SetClock(func() time.Time { return now })
+
+	return s, keys
+}
+
+func do(t *testing.T, h http.Handler, user string, body interface{}, v interface{}) int {
+	t.Helper()
+
+	b, _ := json.Marshal(body)
+	r := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(b))
+	if user != "" {
+		r.Header.Set("X-User-ID", user)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	if got := w.Header().Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type: got %s, want application/json", got)
+	}
+	if v != nil {
+		if err := json.NewDecoder(w.Body).Decode(v); err != nil {
+			t.Fatalf("Decode(%s)=%#v; want nil", w.Body, err)
+		}
+	}
+
+	return w.Code
+}
+
+func passcode(t *testing.T, ti time.Time) string {
+	t.Helper()
+
+	p, err := hotp.GeneratePasscode(secret, uint64(ti.Unix()/30))
+	if err != nil {
+		t.Fatalf("GeneratePasscode()=_, %#v; want nil", err)
+	}
+
+	return p
+}
+
+func enroll(t *testing.T, s *otphttp.Server, user string) {
+	t.Helper()
+
+	var start otphttp.StartEnrollmentResponse
+	if code := do(t, s.StartEnrollment(), user, otphttp.StartEnrollmentRequest{AccountName: "butter@example.com"}, &start); code != http.StatusCreated {
+		t.Fatalf("StartEnrollment: got %d, want %d", code, http.StatusCreated)
+	}
+
+	var confirm otphttp.ConfirmEnrollmentResponse
+	req := otphttp.ConfirmEnrollmentRequest{EnrollmentID: start.EnrollmentID, Passcode: passcode(t, now.Add(-30*time.Second))}
+	if code := do(t, s.ConfirmEnrollment(), user, req, &confirm); code != http.StatusOK {
+		t.Fatalf("ConfirmEnrollment: got %d, want %d", code, http.StatusOK)
+	}
+}
+
+func TestServer_StartEnrollment(t *testing.T) {
+	s, _ := newServer(t)
+
+	var got otphttp.StartEnrollmentResponse
+	code := do(t, s.StartEnrollment(), "user", otphttp.StartEnrollmentRequest{AccountName: "butter@example.com", Type: "totp"}, &got)
+	if code != http.StatusCreated {
+		t.Fatalf("StartEnrollment: got %d, want %d", code, http.StatusCreated)
+	}
+	if got.EnrollmentID == "" || !strings.HasPrefix(got.URL, "otpauth://totp/butter:butter@example.com?") {
+		t.Errorf("StartEnrollment=%#v; want enrollment of totp", got)
+	}
+	if !strings.HasPrefix(got.QRCode, "data:image/png;base64,") {
+		t.Errorf("QRCode: got %s, want base64 encoded png", got.QRCode)
+	}
+	if !got.ExpiresAt.Equal(now.Add(10 * time.Minute)) {
+		t.Errorf("ExpiresAt: got %v, want %v", got.ExpiresAt, now.Add(10*time.Minute))
+	}
+}
+
+func TestServer_StartEnrollment_BadRequest(t *testing.T) {
+	s, _ := newServer(t)
+
+	tests := []interface{}{
+		otphttp.StartEnrollmentRequest{AccountName: "butter@example.com", Type: "sms"},
+		otphttp.StartEnrollmentRequest{},
+		map[string]string{"unknown": "field"},
+	}
+
+	for _, body := range tests {
+		var got otphttp.ErrorResponse
+		if code := do(t, s.StartEnrollment(), "user", body, &got); code != http.StatusBadRequest || got.Error == "" {
+			t.Errorf("StartEnrollment(%v): got %d %#v, want %d", body, code, got, http.StatusBadRequest)
+		}
+	}
+}
+
+func TestServer_Unauthenticated(t *testing.T) {
+	s, _ := newServer(t)
+
+	if code := do(t, s.StartEnrollment(), "", otphttp.StartEnrollmentRequest{AccountName: "butter@example.com"}, nil); code != http.StatusUnauthorized {
+		t.Errorf("StartEnrollment: got %d, want %d", code, http.StatusUnauthorized)
+	}
+}
+
+func TestServer_MethodNotAllowed(t *testing.T) {
+	s, _ := newServer(t)
+
+	w := httptest.NewRecorder()
+	s.Verify().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Code != http.StatusMethodNotAllowed || w.Header().Get("Allow") != http.MethodPost {
+		t.Errorf("Verify: got %d, Allow %s; want %d, POST", w.Code, w.Header().Get("Allow"), http.StatusMethodNotAllowed)
+	}
+}
+
+func TestServer_ConfirmEnrollment(t *testing.T) {
+	s, keys := newServer(t)
+
+	var start otphttp.StartEnrollmentResponse
+	_ = do(t, s.StartEnrollment(), "user", otphttp.StartEnrollmentRequest{AccountName: "butter@example.com"}, &start)
+
+	// the enrollment of another user isn't found
+	req := otphttp.ConfirmEnrollmentRequest{EnrollmentID: start.EnrollmentID, Passcode: passcode(t, now)}
+	if code := do(t, s.ConfirmEnrollment(), "other", req, nil); code != http.StatusNotFound {
+		t.Errorf("ConfirmEnrollment by other: got %d, want %d", code, http.StatusNotFound)
+	}
+
+	invalid := otphttp.ConfirmEnrollmentRequest{EnrollmentID: start.EnrollmentID, Passcode: "000000"}
+	if code := do(t, s.ConfirmEnrollment(), "user", invalid, nil); code != http.StatusUnauthorized {
+		t.Errorf("ConfirmEnrollment with invalid passcode: got %d, want %d", code, http.StatusUnauthorized)
+	}
+
+	var got otphttp.ConfirmEnrollmentResponse
+	if code := do(t, s.ConfirmEnrollment(), "user", req, &got); code != http.StatusOK {
+		t.Fatalf("ConfirmEnrollment: got %d, want %d", code, http.StatusOK)
+	}
+	if got.State != "active" || got.Confirmations != 1 {
+		t.Errorf("ConfirmEnrollment=%#v; want active", got)
+	}
+
+	key, err := keys.Get(context.Background(), "user")
+	if err != nil || key.Secret != secret || key.Host != otpauth.HostTOTP {
+		t.Errorf("Get(user)=%#v, %#v; want saved key", key, err)
+	}
+
+	if code := do(t, s.ConfirmEnrollment(), "user", req, nil); code != http.StatusConflict {
+		t.Errorf("ConfirmEnrollment again: got %d, want %d", code, http.StatusConflict)
+	}
+}
+
+func TestServer_Verify(t *testing.T) {
+	s, _ := newServer(t)
+
+	var notFound otphttp.ErrorResponse
+	if code := do(t, s.Verify(), "user", otphttp.VerifyRequest{Passcode: passcode(t, now)}, &notFound); code != http.StatusNotFound {
+		t.Errorf("Verify before enrollment: got %d, want %d", code, http.StatusNotFound)
+	}
+
+	enroll(t, s, "user")
+
+	var got otphttp.VerifyResponse
+	if code := do(t, s.Verify(), "user", otphttp.VerifyRequest{Passcode: passcode(t, now)}, &got); code != http.StatusOK || !got.Valid {
+		t.Errorf("Verify: got %d %#v, want %d valid", code, got, http.StatusOK)
+	}
+	// the same passcode is used only once
+	if code := do(t, s.Verify(), "user", otphttp.VerifyRequest{Passcode: passcode(t, now)}, &got); code != http.StatusUnauthorized || got.Valid {
+		t.Errorf("Verify again: got %d %#v, want %d invalid", code, got, http.StatusUnauthorized)
+	}
+	if code := do(t, s.Verify(), "user", otphttp.VerifyRequest{Passcode: "12345"}, &got); code != http.StatusUnauthorized || got.Valid {
+		t.Errorf("Verify with short passcode: got %d %#v, want %d invalid", code, got, http.StatusUnauthorized)
+	}
+}
+
+func TestServer_Verify_HOTP(t *testing.T) {
+	s, keys := newServer(t)
+	_ = keys.Save(context.Background(), "user", &otphttp.Key{Host: otpauth.HostHOTP, Secret: secret, Digits: otpauth.DigitsSix, Counter: 3})
+
+	p, _ := hotp.GeneratePasscode(secret, 5)
+	var got otphttp.VerifyResponse
+	if code := do(t, s.Verify(), "user", otphttp.VerifyRequest{Passcode: p}, &got); code != http.StatusOK || !got.Valid {
+		t.Errorf("Verify: got %d %#v, want %d valid", code, got, http.StatusOK)
+	}
+	if key, _ := keys.Get(context.Background(), "user"); key.Counter != 6 {
+		t.Errorf("Counter: got %d, want 6", key.Counter)
+	}
+}
+
+func TestServer_Verify_OtpAuthOption(t *testing.T) {
+	otpAuthOpt, _ := otpauth.NewOption()
+	_ = otpAuthOpt.SetSecret(secret)
+	_ = otpAuthOpt.SetDigits(otpauth.DigitsEight)
+	_ = otpAuthOpt.SetAlgorithm(otpauth.AlgorithmSHA256)
+	opt, _ := enrollment.NewOption()
+	_ = opt.SetOtpAuthOption(otpAuthOpt)
+	enrollments, _ := enrollment.NewManager(enrollment.NewMemoryStore(), opt)
+	keys := otphttp.NewMemoryKeyStore()
+	s, _ := otphttp.NewServer("butter", userID, enrollments, keys)
+	_ = s.SetClock(func() time.Time { return now })
+
+	totpOpt := totp.NewOption()
+	_ = totpOpt.SetDigits(otpauth.DigitsEight)
+	_ = totpOpt.SetAlgorithm(otpauth.AlgorithmSHA256)
+	p := func(ti time.Time) string {
+		p, err := totp.GeneratePasscodeWithOption(secret, ti, totpOpt)
+		if err != nil {
+			t.Fatalf("GeneratePasscodeWithOption()=_, %#v; want nil", err)
+		}
+		return p
+	}
+
+	var start otphttp.StartEnrollmentResponse
+	if code := do(t, s.StartEnrollment(), "user", otphttp.StartEnrollmentRequest{AccountName: "butter@example.com"}, &start); code != http.StatusCreated {
+		t.Fatalf("StartEnrollment: got %d, want %d", code, http.StatusCreated)
+	}
+	req := otphttp.ConfirmEnrollmentRequest{EnrollmentID: start.EnrollmentID, Passcode: p(now.Add(-30 * time.Second))}
+	if code := do(t, s.ConfirmEnrollment(), "user", req, nil); code != http.StatusOK {
+		t.Fatalf("ConfirmEnrollment: got %d, want %d", code, http.StatusOK)
+	}
+
+	key, _ := keys.Get(context.Background(), "user")
+	if key.Digits != otpauth.DigitsEight || key.Algorithm != otpauth.AlgorithmSHA256 || key.Period != 30 {
+		t.Errorf("Get(user)=%#v; want the key of 8 digits, SHA256 and 30 seconds", key)
+	}
+
+	var got otphttp.VerifyResponse
+	if code := do(t, s.Verify(), "user", otphttp.VerifyRequest{Passcode: p(now)}, &got); code != http.StatusOK || !got.Valid {
+		t.Errorf("Verify: got %d %#v, want %d valid", code, got, http.StatusOK)
+	}
+}
+
+func TestServer_Verify_ConcurrentReplay(t *testing.T) {
+	tests := map[string]struct {
+		key      *otphttp.Key
+		passcode func(t *testing.T) string
+	}{
+		"totp": {
+			key:      &otphttp.Key{Host: otpauth.HostTOTP, Secret: secret, Digits: otpauth.DigitsSix, Period: 30},
+			passcode: func(t *testing.T) string { return passcode(t, now) },
+		},
+		"hotp": {
+			key: &otphttp.Key{Host: otpauth.HostHOTP, Secret: secret, Digits: otpauth.DigitsSix, Counter: 3},
+			passcode: func(t *testing.T) string {
+				p, _ := hotp.GeneratePasscode(secret, 3)
+				return p
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			s, keys := newServer(t)
+			_ = keys.Save(context.Background(), "user", tt.key)
+			req := otphttp.VerifyRequest{Passcode: tt.passcode(t)}
+
+			var wg sync.WaitGroup
+			codes := make([]int, 20)
+			for i := range codes {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					codes[i] = do(t, s.Verify(), "user", req, nil)
+				}(i)
+			}
+			wg.Wait()
+
+			accepted := 0
+			for _, code := range codes {
+				if code == http.StatusOK {
+					accepted++
+				}
+			}
+			if accepted != 1 {
+				t.Errorf("Verify with the same passcode concurrently: accepted %d times, want 1", accepted)
+			}
+		})
+	}
+}
+
+func TestMemoryKeyStore_CompareAndSwap(t *testing.T) {
+	ctx := context.Background()
+	keys := otphttp.NewMemoryKeyStore()
+	old := &otphttp.Key{Host: otpauth.HostTOTP, Secret: secret, Step: 10}
+	next := &otphttp.Key{Host: otpauth.HostTOTP, Secret: secret, Step: 11}
+
+	if err := keys.CompareAndSwap(ctx, "user", old, next); err != otphttp.ErrKeyNotFound {
+		t.Errorf("CompareAndSwap(user)=%#v; want %v", err, otphttp.ErrKeyNotFound)
+	}
+	_ = keys.Save(ctx, "user", old)
+	if err := keys.CompareAndSwap(ctx, "user", old, next); err != nil {
+		t.Errorf("CompareAndSwap(user)=%#v; want nil", err)
+	}
+	if err := keys.CompareAndSwap(ctx, "user", old, next); err != otphttp.ErrKeyConflict {
+		t.Errorf("CompareAndSwap(user) again=%#v; want %v", err, otphttp.ErrKeyConflict)
+	}
+	if key, _ := keys.Get(ctx, "user"); key.Step != 11 {
+		t.Errorf("Step: got %d, want 11", key.Step)
+	}
+}
+
+func TestServer_Recover(t *testing.T) {
+	s, _ := newServer(t)
+
+	if code := do(t, s.Recover(), "user", otphttp.RecoveryRequest{Code: "abcd1234"}, nil); code != http.StatusNotFound {
+		t.Errorf("Recover without manager: got %d, want %d", code, http.StatusNotFound)
+	}
+
+	hashOpt := recovery.NewHashOption()
+	_ = hashOpt.SetKDF(recovery.KDFPBKDF2)
+	_ = hashOpt.SetPBKDF2Iterations(1000)
+	m, _ := recovery.NewManager(recovery.NewMemoryStore(), recovery.NewOption(), hashOpt)
+	codes, _ := m.Issue(context.Background(), "user")
+	_ = s.SetRecoveryManager(m)
+
+	var got otphttp.RecoveryResponse
+	if code := do(t, s.Recover(), "user", otphttp.RecoveryRequest{Code: codes[0]}, &got); code != http.StatusOK || got.Remaining != len(codes)-1 {
+		t.Errorf("Recover: got %d %#v, want %d with %d remaining", code, got, http.StatusOK, len(codes)-1)
+	}
+	if code := do(t, s.Recover(), "user", otphttp.RecoveryRequest{Code: codes[0]}, nil); code != http.StatusUnauthorized {
+		t.Errorf("Recover again: got %d, want %d", code, http.StatusUnauthorized)
+	}
+	if code := do(t, s.Recover(), "user", otphttp.RecoveryRequest{Code: "abc"}, nil); code != http.StatusBadRequest {
+		t.Errorf("Recover with mistyped code: got %d, want %d", code, http.StatusBadRequest)
+	}
+}
+
+func TestServer_Verify_Throttle(t *testing.T) {
+	s, _ := newServer(t)
+	l := throttle.NewMemoryLimiter()
+	_ = l.SetBucket(1, 90*time.Second)
+	th, _ := throttle.NewThrottle(l)
+	_ = s.SetThrottle(th)
+	enroll(t, s, "user")
+
+	if code := do(t, s.Verify(), "user", otphttp.VerifyRequest{Passcode: "000000"}, nil); code != http.StatusUnauthorized {
+		t.Errorf("Verify: got %d, want %d", code, http.StatusUnauthorized)
+	}
+
+	b, _ := json.Marshal(otphttp.VerifyRequest{Passcode: passcode(t, now)})
+	r := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(b))
+	r.Header.Set("X-User-ID", "user")
+	w := httptest.NewRecorder()
+	s.Verify().ServeHTTP(w, r)
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") != "90" {
+		t.Errorf("Verify: got %d, Retry-After %s; want %d, 90", w.Code, w.Header().Get("Retry-After"), http.StatusTooManyRequests)
+	}
+}
@@ -0,0 +1,265 @@
+package otphttp
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// minStepUpKeySize is the minimum size of the key that signs the claim
+	minStepUpKeySize     = 32
+	defaultStepUpCookie  = "otp_verified_at"
+	stepUpSignatureInfo  = "one-time-password/otphttp/verified-at"
+	stepUpChallengeRealm = "step-up"
+)
+
+// ErrStepUpIsNil is an error when the step-up middleware is nil
+var ErrStepUpIsNil = errors.New("step-up is nil")
+
+// SessionIDFunc identifies the login session of the request
+type SessionIDFunc func(r *http.Request) (string, error)
+
+type verifiedAtKey struct{}
+
+// VerifiedAt returns the time when the user verified the second factor
+// This is available in the handlers wrapped by StepUp.Require
+func VerifiedAt(ctx context.Context) (time.Time, bool) {
+	t, ok := ctx.Value(verifiedAtKey{}).(time.Time)
+	return t, ok
+}
+
+// StepUp is a middleware that requires a recent verification of the second factor
+// The time of the verification is kept in a cookie signed with HMAC-SHA256 and bound to the user and the session
+type StepUp struct {
+	// key signs the claim
+	key []byte
+	// userID identifies the user of the request
+	userID UserIDFunc
+	// sessionID identifies the login session of the request
+	// The default value is nil, which means the claim is bound only to the user
+	sessionID SessionIDFunc
+	// cookieName is the name of the cookie that has the claim
+	// The default value is `otp_verified_at`
+	cookieName string
+	// insecure allows the cookie over plain HTTP
+	// The default value is false
+	insecure bool
+	// redirectURL is the page of the verification that a stale request is redirected to
+	// The default value is empty, which means a stale request is responded 401 Unauthorized with a challenge
+	redirectURL string
+	// now returns the current time
+	now func() time.Time
+}
+
+// NewStepUp generates a step-up middleware by passing the key that signs the claim and user identification
+func NewStepUp(key []byte, userID UserIDFunc) (*StepUp, error) {
+	if len(key) < minStepUpKeySize {
+		return nil, fmt.Errorf("invalid key. please pass %d bytes or longer", minStepUpKeySize)
+	}
+	if userID == nil {
+		return nil, errors.New("userID is nil")
+	}
+
+	k := make([]byte, len(key))
+	copy(k, key)
+
+	return &StepUp{
+		key:        k,
+		userID:     userID,
+		cookieName: defaultStepUpCookie,
+		now:        time.Now,
+	}, nil
+}
+
+// SetCookieName sets a name of the cookie that has the claim
+func (su *StepUp) SetCookieName(name string) error {
+	if su == nil {
+		return ErrStepUpIsNil
+	}
+	if name == "" || strings.ContainsAny(name, " \t\r\n;,=") {
+		return errors.New("invalid cookie name. please pass non-empty name without separators")
+	}
+
+	su.cookieName = name
+	return nil
+}
+
+// SetSessionID sets a function that identifies the login session of the request
+// The claim is bound to the session, so the claim of a previous session is rejected after the user logs in again
+func (su *StepUp) SetSessionID(sessionID SessionIDFunc) error {
+	if su == nil {
+		return ErrStepUpIsNil
+	}
+	if sessionID == nil {
+		return errors.New("sessionID is nil")
+	}
+
+	su.sessionID = sessionID
+	return nil
+}
+
+// SetInsecure sets whether the cookie is sent over plain HTTP
+// This is intended for local development
+func (su *StepUp) SetInsecure(insecure bool) error {
+	if su == nil {
+		return ErrStepUpIsNil
+	}
+
+	su.insecure = insecure
+	return nil
+}
+
+// SetRedirectURL sets a page of the verification that a stale request is redirected to
+// The requested URI is passed in the `next` query parameter
+func (su *StepUp) SetRedirectURL(redirectURL string) error {
+	if su == nil {
+		return ErrStepUpIsNil
+	}
+	if _, err := url.Parse(redirectURL); err != nil {
+		return err
+	}
+
+	su.redirectURL = redirectURL
+	return nil
+}
+
+// SetClock sets a function that returns the current time
+func (su *StepUp) SetClock(now func() time.Time) error {
+	if su == nil {
+		return ErrStepUpIsNil
+	}
+	if now == nil {
+		return errors.New("now is nil")
+	}
+
+	su.now = now
+	return nil
+}
+
+// Require returns a middleware that passes the request only when the user verified the second factor within the max age
+// A stale request is redirected to the redirect URL or responded 401 Unauthorized with a challenge
+func (su *StepUp) Require(maxAge time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			verifiedAt, ok := su.verifiedAt(r)
+			if !ok || su.now().Sub(verifiedAt) > maxAge {
+				su.challenge(w, r, maxAge)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), verifiedAtKey{}, verifiedAt)))
+		})
+	}
+}
+
+// SetVerified sets the claim that the user of the request verified the second factor at the time
+// Call this after the passcode is validated, like totp.ValidateWithOption returns true
+func (su *StepUp) SetVerified(w http.ResponseWriter, r *http.Request, t time.Time) error {
+	if su == nil {
+		return ErrStepUpIsNil
+	}
+
+	subject, err := su.subject(r)
+	if err != nil {
+		return err
+	}
+
+	value := strconv.FormatInt(t.Unix(), 10)
+	http.SetCookie(w, &http.Cookie{
+		Name:     su.cookieName,
+		Value:    value + "." + su.sign(subject, value),
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   !su.insecure,
+		SameSite: http.SameSiteLaxMode,
+	})
+	return nil
+}
+
+// subject returns the user and the session of the request that the claim is bound to
+// Each identifier is length-prefixed, so it never collides with another identifier or the time
+func (su *StepUp) subject(r *http.Request) (string, error) {
+	userID, err := su.userID(r)
+	if err != nil {
+		return "", err
+	}
+	if userID == "" {
+		return "", ErrUnauthenticated
+	}
+	subject := fmt.Sprintf("%d:%s", len(userID), userID)
+	if su.sessionID == nil {
+		return subject, nil
+	}
+
+	sessionID, err := su.sessionID(r)
+	if err != nil {
+		return "", err
+	}
+	if sessionID == "" {
+		return "", ErrUnauthenticated
+	}
+	return fmt.Sprintf("%s%d:%s", subject, len(sessionID), sessionID), nil
+}
+
+// verifiedAt returns the time in the claim when the signature is valid for the user and the session of the request
+func (su *StepUp) verifiedAt(r *http.Request) (time.Time, bool) {
+	c, err := r.Cookie(su.cookieName)
+	if err != nil {
+		return time.Time{}, false
+	}
+	subject, err := su.subject(r)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	i := strings.LastIndexByte(c.Value, '.')
+	if i < 0 {
+		return time.Time{}, false
+	}
+	value, sig := c.Value[:i], c.Value[i+1:]
+	if !hmac.Equal([]byte(sig), []byte(su.sign(subject, value))) {
+		return time.Time{}, false
+	}
+
+	unix, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	t := time.Unix(unix, 0)
+	// a claim from the future isn't trusted
+	if t.After(su.now()) {
+		return time.Time{}, false
+	}
+
+	return t, true
+}
+
+// sign returns the signature of the time for the subject
+func (su *StepUp) sign(subject, value string) string {
+	mac := hmac.New(sha256.New, su.key)
+	fmt.Fprintf(mac, "%s\x00%s%s", stepUpSignatureInfo, subject, value)
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func (su *StepUp) challenge(w http.ResponseWriter, r *http.Request, maxAge time.Duration) {
+	if su.redirectURL != "" {
+		u, _ := url.Parse(su.redirectURL)
+		q := u.Query()
+		q.Set("next", r.URL.RequestURI())
+		u.RawQuery = q.Encode()
+		http.Redirect(w, r, u.String(), http.StatusSeeOther)
+		return
+	}
+
+	w.Header().Set("WWW-Authenticate", fmt.Sprintf("OTP realm=%q, max_age=%d", stepUpChallengeRealm, int64(maxAge/time.Second)))
+	writeError(w, http.StatusUnauthorized, "second factor verification is required")
+}
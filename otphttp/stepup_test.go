@@ -0,0 +1,263 @@
+package otphttp_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/butterv/one-time-password/hotp"
+	"github.com/butterv/one-time-password/otpauth"
+	"github.com/butterv/one-time-password/otphttp"
+)
+
+var stepUpKey = bytes.Repeat([]byte{0x42}, 32)
+
+func newStepUp(t *testing.T, clock *time.Time) *otphttp.StepUp {
+	t.Helper()
+
+	su, err := otphttp.NewStepUp(stepUpKey, userID)
+	if err != nil {
+		t.Fatalf("NewStepUp()=_, %#v; want nil", err)
+	}
+	_ = su.SetClock(func() time.Time { return *clock })
+
+	return su
+}
+
+// protected responds the time in the claim
+var protected = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	t, ok := otphttp.VerifiedAt(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	_, _ = w.Write([]byte(t.UTC().Format(time.RFC3339)))
+})
+
+func request(h http.Handler, user string, cookies []*http.Cookie) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodGet, "/settings?tab=security", nil)
+	if user != "" {
+		r.Header.Set("X-User-ID", user)
+	}
+	for _, c := range cookies {
+		r.AddCookie(c)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func verified(t *testing.T, su *otphttp.StepUp, user string, at time.Time) []*http.Cookie {
+	t.Helper()
+
+	r := httptest.NewRequest(http.MethodPost, "/", nil)
+	r.Header.Set("X-User-ID", user)
+	w := httptest.NewRecorder()
+	if err := su.SetVerified(w, r, at); err != nil {
+		t.Fatalf("SetVerified()=%#v; want nil", err)
+	}
+
+	return w.Result().Cookies()
+}
+
+func TestNewStepUp_Invalid(t *testing.T) {
+	if _, err := otphttp.NewStepUp(stepUpKey[:31], userID); err == nil {
+		t.Errorf("NewStepUp(31 bytes)=_, nil; want error")
+	}
+	if _, err := otphttp.NewStepUp(stepUpKey, nil); err == nil {
+		t.Errorf("NewStepUp(nil)=_, nil; want error")
+	}
+}
+
+func TestStepUp_Require(t *testing.T) {
+	clock := now
+	su := newStepUp(t, &clock)
+	h := su.Require(5 * time.Minute)(protected)
+
+	w := request(h, "user", nil)
+	if w.Code != http.StatusUnauthorized || w.Header().Get("WWW-Authenticate") != `OTP realm="step-up", max_age=300` {
+		t.Errorf("without claim: got %d %s, want %d with challenge", w.Code, w.Header().Get("WWW-Authenticate"), http.StatusUnauthorized)
+	}
+
+	cookies := verified(t, su, "user", now)
+	if len(cookies) != 1 || !cookies[0].HttpOnly || !cookies[0].Secure {
+		t.Fatalf("cookies: got %v, want a secure cookie", cookies)
+	}
+
+	w = request(h, "user", cookies)
+	if w.Code != http.StatusOK || w.Body.String() != now.Format(time.RFC3339) {
+		t.Errorf("with claim: got %d %s, want %d %s", w.Code, w.Body, http.StatusOK, now.Format(time.RFC3339))
+	}
+
+	// the claim of another user is rejected
+	if w := request(h, "other", cookies); w.Code != http.StatusUnauthorized {
+		t.Errorf("claim of another user: got %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+
+	// the claim is stale after max age
+	clock = now.Add(5*time.Minute + time.Second)
+	if w := request(h, "user", cookies); w.Code != http.StatusUnauthorized {
+		t.Errorf("stale claim: got %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+	// a route with the longer max age still accepts the claim
+	if w := request(su.Require(time.Hour)(protected), "user", cookies); w.Code != http.StatusOK {
+		t.Errorf("stale claim with longer max age: got %d, want %d", w.Code, http.StatusOK)
+	}
+}
+
+func TestStepUp_Require_Tampered(t *testing.T) {
+	clock := now
+	su := newStepUp(t, &clock)
+	h := su.Require(5 * time.Minute)(protected)
+
+	cookies := verified(t, su, "user", now.Add(-time.Hour))
+	// moving the time forward invalidates the signature
+	cookies[0].Value = "1601510400" + cookies[0].Value[len("1601506800"):]
+	if w := request(h, "user", cookies); w.Code != http.StatusUnauthorized {
+		t.Errorf("tampered claim: got %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+
+	other, _ := otphttp.NewStepUp(bytes.Repeat([]byte{0x43}, 32), userID)
+	if w := request(h, "user", verified(t, other, "user", now)); w.Code != http.StatusUnauthorized {
+		t.Errorf("claim signed with another key: got %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestStepUp_Require_Session(t *testing.T) {
+	clock := now
+	su := newStepUp(t, &clock)
+	_ = su.SetSessionID(func(r *http.Request) (string, error) {
+		return r.Header.Get("X-Session-ID"), nil
+	})
+	h := su.Require(5 * time.Minute)(protected)
+
+	newRequest := func(session string, cookies []*http.Cookie) *http.Request {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("X-User-ID", "user")
+		if session != "" {
+			r.Header.Set("X-Session-ID", session)
+		}
+		for _, c := range cookies {
+			r.AddCookie(c)
+		}
+		return r
+	}
+
+	w := httptest.NewRecorder()
+	if err := su.SetVerified(w, newRequest("session", nil), now); err != nil {
+		t.Fatalf("SetVerified()=%#v; want nil", err)
+	}
+	if err := su.SetVerified(httptest.NewRecorder(), newRequest("", nil), now); err != otphttp.ErrUnauthenticated {
+		t.Errorf("SetVerified(without session)=%#v; want %v", err, otphttp.ErrUnauthenticated)
+	}
+	cookies := w.Result().Cookies()
+
+	tests := []struct {
+		name    string
+		session string
+		want    int
+	}{
+		{name: "same session", session: "session", want: http.StatusOK},
+		{name: "another session", session: "other", want: http.StatusUnauthorized},
+		{name: "without session", session: "", want: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, newRequest(tt.session, cookies))
+		if w.Code != tt.want {
+			t.Errorf("%s: got %d, want %d", tt.name, w.Code, tt.want)
+		}
+	}
+
+	// the claim without the session isn't accepted once the session is required
+	unbound := newStepUp(t, &clock)
+	if w := request(h, "user", verified(t, unbound, "user", now)); w.Code != http.StatusUnauthorized {
+		t.Errorf("claim without session: got %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+	if err := su.SetSessionID(nil); err == nil {
+		t.Errorf("SetSessionID(nil)=nil; want error")
+	}
+}
+
+func TestStepUp_Require_Redirect(t *testing.T) {
+	clock := now
+	su := newStepUp(t, &clock)
+	_ = su.SetRedirectURL("/verify")
+
+	w := request(su.Require(time.Minute)(protected), "user", nil)
+	if w.Code != http.StatusSeeOther {
+		t.Fatalf("got %d, want %d", w.Code, http.StatusSeeOther)
+	}
+	if got, want := w.Header().Get("Location"), "/verify?next=%2Fsettings%3Ftab%3Dsecurity"; got != want {
+		t.Errorf("Location: got %s, want %s", got, want)
+	}
+}
+
+func TestServer_Verify_StepUp(t *testing.T) {
+	s, _ := newServer(t)
+	clock := now
+	su := newStepUp(t, &clock)
+	_ = s.SetStepUp(su)
+	enroll(t, s, "user")
+
+	b, _ := json.Marshal(otphttp.VerifyRequest{Passcode: passcode(t, now)})
+	r := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(b))
+	r.Header.Set("X-User-ID", "user")
+	w := httptest.NewRecorder()
+	s.Verify().ServeHTTP(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Verify: got %d, want %d", w.Code, http.StatusOK)
+	}
+
+	if got := request(su.Require(time.Minute)(protected), "user", w.Result().Cookies()); got.Code != http.StatusOK {
+		t.Errorf("after Verify: got %d, want %d", got.Code, http.StatusOK)
+	}
+}
+
+func TestServer_Enrollment_ReplaceActiveKey(t *testing.T) {
+	s, keys := newServer(t)
+	clock := now
+	su := newStepUp(t, &clock)
+	enroll(t, s, "user")
+
+	// a session of the first factor can't replace the active key
+	var got otphttp.ErrorResponse
+	if code := do(t, s.StartEnrollment(), "user", otphttp.StartEnrollmentRequest{AccountName: "butter@example.com"}, &got); code != http.StatusConflict || got.Error != otphttp.ErrKeyActive.Error() {
+		t.Errorf("StartEnrollment with active key: got %d %#v, want %d", code, got, http.StatusConflict)
+	}
+	if code := do(t, s.ConfirmEnrollment(), "user", otphttp.ConfirmEnrollmentRequest{EnrollmentID: "id", Passcode: "000000"}, nil); code != http.StatusConflict {
+		t.Errorf("ConfirmEnrollment with active key: got %d, want %d", code, http.StatusConflict)
+	}
+
+	// a recent verification of the active key allows replacing it
+	cookies := verified(t, su, "user", now)
+	post := func(h http.Handler, body interface{}, v interface{}) int {
+		b, _ := json.Marshal(body)
+		r := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(b))
+		r.Header.Set("X-User-ID", "user")
+		for _, c := range cookies {
+			r.AddCookie(c)
+		}
+		w := httptest.NewRecorder()
+		su.Require(time.Minute)(h).ServeHTTP(w, r)
+		_ = json.NewDecoder(w.Body).Decode(v)
+		return w.Code
+	}
+
+	var start otphttp.StartEnrollmentResponse
+	if code := post(s.StartEnrollment(), otphttp.StartEnrollmentRequest{AccountName: "butter@example.com", Type: "hotp"}, &start); code != http.StatusCreated {
+		t.Fatalf("StartEnrollment after step-up: got %d, want %d", code, http.StatusCreated)
+	}
+	p, _ := hotp.GeneratePasscode(secret, 0)
+	var confirm otphttp.ConfirmEnrollmentResponse
+	if code := post(s.ConfirmEnrollment(), otphttp.ConfirmEnrollmentRequest{EnrollmentID: start.EnrollmentID, Passcode: p}, &confirm); code != http.StatusOK {
+		t.Fatalf("ConfirmEnrollment after step-up: got %d, want %d", code, http.StatusOK)
+	}
+	if key, _ := keys.Get(context.Background(), "user"); key.Host != otpauth.HostHOTP {
+		t.Errorf("Host: got %v, want hotp", key.Host)
+	}
+}
@@ -0,0 +1,195 @@
+package pskc
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"hash"
+	"io"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// encryptionKey returns the key that decrypts the values of the key container
+func (c *xmlKeyContainer) encryptionKey(opt *Option) ([]byte, error) {
+	if c.EncryptionKey == nil {
+		// the key container doesn't identify the key, so the pre-shared key is used as it is
+		if len(opt.preSharedKey) == 0 {
+			return nil, ErrDecryptionKeyRequired
+		}
+		return opt.preSharedKey, nil
+	}
+
+	dk := c.EncryptionKey.DerivedKey
+	if dk == nil {
+		if len(opt.preSharedKey) == 0 {
+			return nil, fmt.Errorf("%w: pre-shared key %q", ErrDecryptionKeyRequired, c.EncryptionKey.KeyName)
+		}
+		return opt.preSharedKey, nil
+	}
+
+	if len(opt.passphrase) == 0 {
+		return nil, fmt.Errorf("%w: passphrase %q", ErrDecryptionKeyRequired, dk.MasterKeyName)
+	}
+	m := dk.KeyDerivationMethod
+	if m.Algorithm != algorithmPBKDF2 || m.Params == nil {
+		return nil, fmt.Errorf("%w: key derivation %q", ErrUnsupportedAlgorithm, m.Algorithm)
+	}
+	p := m.Params
+	salt, err := decodeBase64(p.Salt.Specified)
+	if err != nil || len(salt) == 0 {
+		return nil, fmt.Errorf("%w: invalid salt of pbkdf2 %q", ErrInvalidContainer, p.Salt.Specified)
+	}
+	// the parameters come from the document, so they are bounded before the key is derived
+	if p.IterationCount <= 0 || p.IterationCount > maxPBKDF2Iterations {
+		return nil, fmt.Errorf("%w: invalid iteration count of pbkdf2 %d. please pass 1 to %d", ErrInvalidContainer, p.IterationCount, maxPBKDF2Iterations)
+	}
+	keyLength := p.KeyLength
+	switch keyLength {
+	case 0:
+		keyLength = passphraseKeySize
+	case 16, 24, 32:
+	default:
+		return nil, fmt.Errorf("%w: invalid key length of pbkdf2 %d. please pass 16, 24 or 32", ErrInvalidContainer, p.KeyLength)
+	}
+	prf := sha1.New
+	if p.PRF != nil && strings.HasSuffix(p.PRF.Algorithm, "hmac-sha256") {
+		prf = sha256.New
+	} else if p.PRF != nil && p.PRF.Algorithm != "" && !strings.HasSuffix(p.PRF.Algorithm, "hmac-sha1") {
+		return nil, fmt.Errorf("%w: prf %q", ErrUnsupportedAlgorithm, p.PRF.Algorithm)
+	}
+
+	return pbkdf2.Key(opt.passphrase, salt, p.IterationCount, keyLength, prf), nil
+}
+
+// macKey returns the MAC key and the hash function of the key container
+func (c *xmlKeyContainer) macKey(key []byte) ([]byte, func() hash.Hash, error) {
+	if c.MACMethod == nil {
+		return nil, nil, nil
+	}
+
+	var h func() hash.Hash
+	switch c.MACMethod.Algorithm {
+	case algorithmHMACSHA1:
+		h = sha1.New
+	case algorithmHMACSHA256:
+		h = sha256.New
+	default:
+		return nil, nil, fmt.Errorf("%w: mac %q", ErrUnsupportedAlgorithm, c.MACMethod.Algorithm)
+	}
+	if c.MACMethod.MACKey == nil {
+		return nil, nil, fmt.Errorf("%w: mac key is missing", ErrInvalidContainer)
+	}
+
+	macKey, err := decrypt(key, c.MACMethod.MACKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return macKey, h, nil
+}
+
+// decrypt decrypts the value encrypted with AES-CBC whose IV is prepended
+func decrypt(key []byte, v *xmlEncryptedValue) ([]byte, error) {
+	size := 0
+	switch v.EncryptionMethod.Algorithm {
+	case algorithmAES128CBC:
+		size = 16
+	case algorithmAES192CBC:
+		size = 24
+	case algorithmAES256CBC:
+		size = 32
+	default:
+		return nil, fmt.Errorf("%w: encryption %q", ErrUnsupportedAlgorithm, v.EncryptionMethod.Algorithm)
+	}
+	if len(key) != size {
+		return nil, fmt.Errorf("%w: the key isn't %d bytes for %s", ErrDecryptionFailed, size, v.EncryptionMethod.Algorithm)
+	}
+
+	value, err := v.cipherValue()
+	if err != nil {
+		return nil, err
+	}
+	if len(value) < aes.BlockSize*2 || len(value)%aes.BlockSize != 0 {
+		return nil, ErrDecryptionFailed
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	iv, ciphertext := value[:aes.BlockSize], value[aes.BlockSize:]
+	plaintext := make([]byte, len(ciphertext))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(plaintext, ciphertext)
+
+	// the padding of XML Encryption has only the length in the last byte
+	// See: https://www.w3.org/TR/xmlenc-core1/#sec-Alg-Block
+	n := int(plaintext[len(plaintext)-1])
+	if n == 0 || n > aes.BlockSize {
+		return nil, ErrDecryptionFailed
+	}
+
+	return plaintext[:len(plaintext)-n], nil
+}
+
+// cipherValue returns the IV and the ciphertext, which is the input of ValueMAC
+func (v *xmlEncryptedValue) cipherValue() ([]byte, error) {
+	value, err := decodeBase64(v.CipherData.CipherValue)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidContainer, err)
+	}
+
+	return value, nil
+}
+
+// encrypt encrypts the plaintext with AES-CBC under a random IV and the padding of PKCS#7
+func encrypt(key, plaintext []byte, rand io.Reader) (*xmlEncryptedValue, []byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	n := aes.BlockSize - len(plaintext)%aes.BlockSize
+	padded := make([]byte, len(plaintext)+n)
+	copy(padded, plaintext)
+	for i := len(plaintext); i < len(padded); i++ {
+		padded[i] = byte(n)
+	}
+
+	value := make([]byte, aes.BlockSize+len(padded))
+	if _, err := io.ReadFull(rand, value[:aes.BlockSize]); err != nil {
+		return nil, nil, err
+	}
+	cipher.NewCBCEncrypter(block, value[:aes.BlockSize]).CryptBlocks(value[aes.BlockSize:], padded)
+
+	v := &xmlEncryptedValue{}
+	v.EncryptionMethod.Algorithm = aesCBCAlgorithm(len(key))
+	v.CipherData.CipherValue = base64.StdEncoding.EncodeToString(value)
+	return v, value, nil
+}
+
+func aesCBCAlgorithm(keySize int) string {
+	switch keySize {
+	case 24:
+		return algorithmAES192CBC
+	case 32:
+		return algorithmAES256CBC
+	}
+
+	return algorithmAES128CBC
+}
+
+func mac(key []byte, h func() hash.Hash, value []byte) []byte {
+	m := hmac.New(h, key)
+	m.Write(value)
+	return m.Sum(nil)
+}
+
+// decodeBase64 decodes the base64 encoded value that may be folded with whitespaces
+func decodeBase64(s string) ([]byte, error) {
+	return base64.StdEncoding.DecodeString(strings.Join(strings.Fields(s), ""))
+}
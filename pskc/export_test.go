@@ -0,0 +1,21 @@
+package pskc
+
+func (opt *Option) KeyName() string {
+	return opt.keyName
+}
+
+func (opt *Option) PreSharedKey() []byte {
+	return opt.preSharedKey
+}
+
+func (opt *Option) MasterKeyName() string {
+	return opt.masterKeyName
+}
+
+func (opt *Option) Passphrase() []byte {
+	return opt.passphrase
+}
+
+func (opt *Option) PBKDF2Iterations() int {
+	return opt.pbkdf2Iterations
+}
@@ -0,0 +1,122 @@
+package pskc
+
+import (
+	"encoding/base32"
+	"fmt"
+	"strings"
+
+	"github.com/butterv/one-time-password/hotp"
+	"github.com/butterv/one-time-password/otpauth"
+	"github.com/butterv/one-time-password/totp"
+)
+
+// encodingDecimal is the encoding of the response in decimal digits
+const encodingDecimal = "DECIMAL"
+
+// Host returns the host of otpauth for the algorithm of the key
+func (kp *KeyPackage) Host() (otpauth.Host, error) {
+	if kp == nil {
+		return 0, ErrKeyPackageIsNil
+	}
+
+	switch kp.Algorithm {
+	case AlgorithmHOTP:
+		return otpauth.HostHOTP, nil
+	case AlgorithmTOTP:
+		return otpauth.HostTOTP, nil
+	}
+
+	return 0, fmt.Errorf("%w: key %q", ErrUnsupportedAlgorithm, kp.Algorithm)
+}
+
+// Base32Secret returns the secret encoded in base32 with padding, which is usable with hotp and totp
+func (kp *KeyPackage) Base32Secret() string {
+	if kp == nil {
+		return ""
+	}
+
+	return base32.StdEncoding.EncodeToString(kp.Secret)
+}
+
+// HOTPOption returns the option of HMAC-based One Time Password for the key
+// The counter of the key is passed to hotp separately
+func (kp *KeyPackage) HOTPOption() (*hotp.Option, error) {
+	h, err := kp.Host()
+	if err != nil {
+		return nil, err
+	}
+	if h != otpauth.HostHOTP {
+		return nil, fmt.Errorf("%w: key %q isn't hotp", ErrUnsupportedAlgorithm, kp.Algorithm)
+	}
+	d, a, err := kp.params()
+	if err != nil {
+		return nil, err
+	}
+
+	opt := hotp.NewOption()
+	if err := opt.SetDigits(d); err != nil {
+		return nil, err
+	}
+	if err := opt.SetAlgorithm(a); err != nil {
+		return nil, err
+	}
+
+	return opt, nil
+}
+
+// TOTPOption returns the option of Time-based One Time Password for the key
+func (kp *KeyPackage) TOTPOption() (*totp.Option, error) {
+	h, err := kp.Host()
+	if err != nil {
+		return nil, err
+	}
+	if h != otpauth.HostTOTP {
+		return nil, fmt.Errorf("%w: key %q isn't totp", ErrUnsupportedAlgorithm, kp.Algorithm)
+	}
+	d, a, err := kp.params()
+	if err != nil {
+		return nil, err
+	}
+
+	opt := totp.NewOption()
+	if err := opt.SetDigits(d); err != nil {
+		return nil, err
+	}
+	if err := opt.SetAlgorithm(a); err != nil {
+		return nil, err
+	}
+	if kp.TimeInterval != 0 {
+		if err := opt.SetPeriod(kp.TimeInterval); err != nil {
+			return nil, err
+		}
+	}
+
+	return opt, nil
+}
+
+// params returns the digits and the hash algorithm of the key
+func (kp *KeyPackage) params() (otpauth.Digits, otpauth.Algorithm, error) {
+	if kp.Encoding != "" && !strings.EqualFold(kp.Encoding, encodingDecimal) {
+		return 0, 0, fmt.Errorf("unsupported encoding of response %q. only %s is supported", kp.Encoding, encodingDecimal)
+	}
+
+	d := otpauth.DigitsSix
+	if kp.Digits != 0 {
+		d = otpauth.Digits(kp.Digits)
+	}
+	if !d.Enabled() {
+		return 0, 0, fmt.Errorf("unsupported digits %d. only %d or %d is supported", kp.Digits, otpauth.DigitsSix, otpauth.DigitsEight)
+	}
+
+	if kp.Suite == "" {
+		return d, otpauth.AlgorithmSHA1, nil
+	}
+	s := strings.TrimPrefix(strings.ReplaceAll(strings.ToUpper(kp.Suite), "-", ""), "HMAC")
+	for a := otpauth.AlgorithmSHA1; a.Enabled(); a++ {
+		if strings.EqualFold(a.String(), s) {
+			return d, a, nil
+		}
+	}
+
+	return 0, 0, fmt.Errorf("%w: suite %q", ErrUnsupportedAlgorithm, kp.Suite)
+}
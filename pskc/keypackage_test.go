@@ -0,0 +1,99 @@
+package pskc_test
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/butterv/one-time-password/otpauth"
+	"github.com/butterv/one-time-password/pskc"
+	"github.com/butterv/one-time-password/totp"
+)
+
+func TestKeyPackage_Host(t *testing.T) {
+	tests := []struct {
+		algorithm string
+		want      otpauth.Host
+		wantErr   bool
+	}{
+		{algorithm: pskc.AlgorithmHOTP, want: otpauth.HostHOTP},
+		{algorithm: pskc.AlgorithmTOTP, want: otpauth.HostTOTP},
+		{algorithm: "urn:ietf:params:xml:ns:keyprov:pskc#OCRA-1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		kp := &pskc.KeyPackage{Algorithm: tt.algorithm}
+		got, err := kp.Host()
+		if (err != nil) != tt.wantErr || (!tt.wantErr && got != tt.want) {
+			t.Errorf("Host()=%v, %#v; want %v, error %t", got, err, tt.want, tt.wantErr)
+		}
+	}
+
+	var nilKP *pskc.KeyPackage
+	if _, err := nilKP.Host(); err != pskc.ErrKeyPackageIsNil {
+		t.Errorf("Host()=_, %#v; want %v", err, pskc.ErrKeyPackageIsNil)
+	}
+}
+
+func TestKeyPackage_Base32Secret(t *testing.T) {
+	kp := &pskc.KeyPackage{Secret: []byte(testSecret)}
+	if got, want := kp.Base32Secret(), "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"; got != want {
+		t.Errorf("Base32Secret()=%s; want %s", got, want)
+	}
+
+	kp.Secret = []byte("1234-secret")
+	if got, want := kp.Base32Secret(), "GEZDGNBNONSWG4TFOQ======"; got != want {
+		t.Errorf("Base32Secret()=%s; want %s", got, want)
+	}
+}
+
+func TestKeyPackage_HOTPOption(t *testing.T) {
+	tests := []struct {
+		name    string
+		kp      *pskc.KeyPackage
+		wantErr bool
+	}{
+		{name: "default", kp: &pskc.KeyPackage{Algorithm: pskc.AlgorithmHOTP}},
+		{name: "suite", kp: &pskc.KeyPackage{Algorithm: pskc.AlgorithmHOTP, Suite: "HMAC-SHA-512", Digits: 8}},
+		{name: "totp", kp: &pskc.KeyPackage{Algorithm: pskc.AlgorithmTOTP}, wantErr: true},
+		{name: "unsupported digits", kp: &pskc.KeyPackage{Algorithm: pskc.AlgorithmHOTP, Digits: 7}, wantErr: true},
+		{name: "unsupported encoding", kp: &pskc.KeyPackage{Algorithm: pskc.AlgorithmHOTP, Encoding: "HEXADECIMAL"}, wantErr: true},
+		{name: "unsupported suite", kp: &pskc.KeyPackage{Algorithm: pskc.AlgorithmHOTP, Suite: "OCRA-1:HOTP-SHA1-6:QN08"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opt, err := tt.kp.HOTPOption()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("HOTPOption()=_, %#v; want error %t", err, tt.wantErr)
+			}
+			if !tt.wantErr && opt == nil {
+				t.Error("HOTPOption()=nil, nil; want option")
+			}
+		})
+	}
+}
+
+func TestKeyPackage_TOTPOption(t *testing.T) {
+	f, _ := os.Open("testdata/totp.xml")
+	defer f.Close()
+	c, err := pskc.Parse(f)
+	if err != nil {
+		t.Fatalf("Parse()=_, %#v; want nil", err)
+	}
+
+	kp := c.KeyPackages[0]
+	opt, err := kp.TOTPOption()
+	if err != nil {
+		t.Fatalf("TOTPOption()=_, %#v; want nil", err)
+	}
+	// See: https://tools.ietf.org/html/rfc6238#appendix-B
+	if got, _ := totp.GeneratePasscodeWithOption(kp.Base32Secret(), time.Unix(59, 0), opt); got != "46119246" {
+		t.Errorf("GeneratePasscodeWithOption()=%s; want 46119246", got)
+	}
+
+	if _, err := (&pskc.KeyPackage{Algorithm: pskc.AlgorithmHOTP}).TOTPOption(); !errors.Is(err, pskc.ErrUnsupportedAlgorithm) {
+		t.Errorf("TOTPOption()=_, %#v; want %v", err, pskc.ErrUnsupportedAlgorithm)
+	}
+}
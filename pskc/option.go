@@ -0,0 +1,104 @@
+package pskc
+
+import (
+	crand "crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const (
+	defaultPBKDF2Iterations = 100000
+	// maxPBKDF2Iterations bounds the cost of deriving the key of a key container
+	maxPBKDF2Iterations = 10000000
+	// passphraseKeySize is the size of the key derived from the passphrase for AES-128-CBC
+	passphraseKeySize = 16
+	pbkdf2SaltSize    = 16
+	macKeySize        = 20
+)
+
+// ErrPSKCOptionIsNil is an error when the pskc option is nil
+var ErrPSKCOptionIsNil = errors.New("pskc option is nil")
+
+// Option is used when parses and writes a key container
+// A key container is decrypted and encrypted with either the pre-shared key or the passphrase
+type Option struct {
+	// keyName is the name of the pre-shared key
+	keyName string
+	// preSharedKey is the key of AES-128-CBC, AES-192-CBC or AES-256-CBC
+	preSharedKey []byte
+	// masterKeyName is the name of the passphrase
+	masterKeyName string
+	// passphrase derives the key of AES-128-CBC with PBKDF2
+	passphrase []byte
+	// pbkdf2Iterations is the iteration count of PBKDF2 when writes a key container
+	// The default value is 100000
+	pbkdf2Iterations int
+	// rand is the reader to use for generating IVs, salts and MAC keys
+	rand io.Reader
+}
+
+// SetPreSharedKey sets a pre-shared key of 16, 24 or 32 bytes and its name
+// This clears the passphrase
+func (opt *Option) SetPreSharedKey(name string, key []byte) error {
+	if opt == nil {
+		return ErrPSKCOptionIsNil
+	}
+	if name == "" {
+		return errors.New("name is empty")
+	}
+	switch len(key) {
+	case 16, 24, 32:
+	default:
+		return errors.New("invalid key. please pass 16, 24 or 32 bytes")
+	}
+
+	opt.keyName = name
+	opt.preSharedKey = make([]byte, len(key))
+	copy(opt.preSharedKey, key)
+	opt.masterKeyName, opt.passphrase = "", nil
+	return nil
+}
+
+// SetPassphrase sets a passphrase and its name, which may be empty
+// This clears the pre-shared key
+func (opt *Option) SetPassphrase(name string, passphrase []byte) error {
+	if opt == nil {
+		return ErrPSKCOptionIsNil
+	}
+	if len(passphrase) == 0 {
+		return errors.New("passphrase is empty")
+	}
+
+	opt.masterKeyName = name
+	opt.passphrase = make([]byte, len(passphrase))
+	copy(opt.passphrase, passphrase)
+	opt.keyName, opt.preSharedKey = "", nil
+	return nil
+}
+
+// SetPBKDF2Iterations sets the iteration count of PBKDF2 when writes a key container
+func (opt *Option) SetPBKDF2Iterations(iterations int) error {
+	if opt == nil {
+		return ErrPSKCOptionIsNil
+	}
+	if iterations <= 0 || iterations > maxPBKDF2Iterations {
+		return fmt.Errorf("invalid pbkdf2 iterations. please pass 1 to %d", maxPBKDF2Iterations)
+	}
+
+	opt.pbkdf2Iterations = iterations
+	return nil
+}
+
+// NewOption generates an option with default values
+// The default option neither decrypts nor encrypts key containers
+func NewOption() *Option {
+	return &Option{
+		pbkdf2Iterations: defaultPBKDF2Iterations,
+		rand:             crand.Reader,
+	}
+}
+
+func (opt *Option) encrypted() bool {
+	return len(opt.preSharedKey) > 0 || len(opt.passphrase) > 0
+}
@@ -0,0 +1,88 @@
+package pskc_test
+
+import (
+	"testing"
+
+	"github.com/butterv/one-time-password/pskc"
+)
+
+func TestNewOption(t *testing.T) {
+	o := pskc.NewOption()
+	if len(o.PreSharedKey()) != 0 || len(o.Passphrase()) != 0 {
+		t.Errorf("NewOption() has pre-shared key %x and passphrase %q; want empty", o.PreSharedKey(), o.Passphrase())
+	}
+	if got := o.PBKDF2Iterations(); got != 100000 {
+		t.Errorf("NewOption() has pbkdf2 iterations %d; want 100000", got)
+	}
+}
+
+func TestOption_SetPreSharedKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		keyName string
+		key     []byte
+		wantErr bool
+	}{
+		{name: "aes-128", keyName: "Pre-shared-key", key: make([]byte, 16), wantErr: false},
+		{name: "aes-192", keyName: "Pre-shared-key", key: make([]byte, 24), wantErr: false},
+		{name: "aes-256", keyName: "Pre-shared-key", key: make([]byte, 32), wantErr: false},
+		{name: "invalid size", keyName: "Pre-shared-key", key: make([]byte, 20), wantErr: true},
+		{name: "empty name", keyName: "", key: make([]byte, 16), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := pskc.NewOption()
+			_ = o.SetPassphrase("", []byte("qwerty"))
+			err := o.SetPreSharedKey(tt.keyName, tt.key)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SetPreSharedKey()=%#v; want error %t", err, tt.wantErr)
+			}
+			if !tt.wantErr && (o.KeyName() != tt.keyName || len(o.PreSharedKey()) != len(tt.key) || len(o.Passphrase()) != 0) {
+				t.Errorf("SetPreSharedKey() sets %q, %x and keeps passphrase %q", o.KeyName(), o.PreSharedKey(), o.Passphrase())
+			}
+		})
+	}
+
+	var nilOpt *pskc.Option
+	if err := nilOpt.SetPreSharedKey("Pre-shared-key", make([]byte, 16)); err != pskc.ErrPSKCOptionIsNil {
+		t.Errorf("SetPreSharedKey()=%#v; want %v", err, pskc.ErrPSKCOptionIsNil)
+	}
+}
+
+func TestOption_SetPassphrase(t *testing.T) {
+	o := pskc.NewOption()
+	_ = o.SetPreSharedKey("Pre-shared-key", make([]byte, 16))
+	if err := o.SetPassphrase("My Password 1", []byte("qwerty")); err != nil {
+		t.Errorf("SetPassphrase(qwerty)=%#v; want nil", err)
+	}
+	if o.MasterKeyName() != "My Password 1" || string(o.Passphrase()) != "qwerty" || len(o.PreSharedKey()) != 0 {
+		t.Errorf("SetPassphrase() sets %q, %q and keeps pre-shared key %x", o.MasterKeyName(), o.Passphrase(), o.PreSharedKey())
+	}
+	if err := o.SetPassphrase("", nil); err == nil {
+		t.Error("SetPassphrase(nil)=nil; want error")
+	}
+
+	var nilOpt *pskc.Option
+	if err := nilOpt.SetPassphrase("", []byte("qwerty")); err != pskc.ErrPSKCOptionIsNil {
+		t.Errorf("SetPassphrase(qwerty)=%#v; want %v", err, pskc.ErrPSKCOptionIsNil)
+	}
+}
+
+func TestOption_SetPBKDF2Iterations(t *testing.T) {
+	o := pskc.NewOption()
+	if err := o.SetPBKDF2Iterations(1000); err != nil || o.PBKDF2Iterations() != 1000 {
+		t.Errorf("SetPBKDF2Iterations(1000)=%#v; want nil", err)
+	}
+	if err := o.SetPBKDF2Iterations(0); err == nil {
+		t.Error("SetPBKDF2Iterations(0)=nil; want error")
+	}
+	if err := o.SetPBKDF2Iterations(10000001); err == nil {
+		t.Error("SetPBKDF2Iterations(10000001)=nil; want error")
+	}
+
+	var nilOpt *pskc.Option
+	if err := nilOpt.SetPBKDF2Iterations(1000); err != pskc.ErrPSKCOptionIsNil {
+		t.Errorf("SetPBKDF2Iterations(1000)=%#v; want %v", err, pskc.ErrPSKCOptionIsNil)
+	}
+}
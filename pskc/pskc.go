@@ -0,0 +1,400 @@
+package pskc
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const (
+	// AlgorithmHOTP is the algorithm of the key of HMAC-based One Time Password
+	AlgorithmHOTP = "urn:ietf:params:xml:ns:keyprov:pskc:hotp"
+	// AlgorithmTOTP is the algorithm of the key of Time-based One Time Password
+	AlgorithmTOTP = "urn:ietf:params:xml:ns:keyprov:pskc:totp"
+
+	version = "1.0"
+)
+
+// ErrContainerIsNil is an error when the key container is nil
+var ErrContainerIsNil = errors.New("pskc container is nil")
+
+// ErrKeyPackageIsNil is an error when the key package is nil
+var ErrKeyPackageIsNil = errors.New("pskc key package is nil")
+
+// ErrInvalidContainer is an error when the document isn't a valid key container
+var ErrInvalidContainer = errors.New("invalid pskc key container")
+
+// ErrUnsupportedAlgorithm is an error when the key container uses an algorithm that isn't supported
+var ErrUnsupportedAlgorithm = errors.New("unsupported algorithm")
+
+// ErrDecryptionKeyRequired is an error when the key container is encrypted and neither the pre-shared key nor the passphrase is set
+var ErrDecryptionKeyRequired = errors.New("pre-shared key or passphrase is required for encrypted key container")
+
+// ErrDecryptionFailed is an error when the pre-shared key or the passphrase doesn't decrypt the key container
+var ErrDecryptionFailed = errors.New("failed to decrypt. the pre-shared key or the passphrase may be wrong")
+
+// ErrInvalidMAC is an error when the MAC of an encrypted value doesn't match
+var ErrInvalidMAC = errors.New("invalid mac of encrypted value")
+
+// Container is a key container of the Portable Symmetric Key Container
+// See: https://tools.ietf.org/html/rfc6030
+type Container struct {
+	// ID is the identifier of the key container, which may be empty
+	ID string
+	// KeyPackages is the key packages in the order of the document
+	KeyPackages []*KeyPackage
+}
+
+// KeyPackage is a key with the information of the device that the key is provisioned to
+type KeyPackage struct {
+	// Manufacturer is the manufacturer of the device
+	Manufacturer string
+	// SerialNo is the serial number of the device
+	SerialNo string
+	// Model is the model of the device
+	Model string
+
+	// KeyID is the identifier of the key
+	KeyID string
+	// Algorithm is the URI of the algorithm of the key like AlgorithmHOTP and AlgorithmTOTP
+	Algorithm string
+	// Issuer is the issuer of the key
+	Issuer string
+	// FriendlyName is the name of the key for humans
+	FriendlyName string
+	// UserID is the user that the key is assigned to
+	UserID string
+	// Suite is the hash algorithm of the key like `HMAC-SHA256`
+	// The empty value means SHA1
+	Suite string
+	// Digits is the length of the response
+	// The zero value means 6
+	Digits int
+	// Encoding is the encoding of the response
+	// The empty value means DECIMAL
+	Encoding string
+
+	// Secret is the decrypted secret of the key
+	Secret []byte
+	// Counter is the counter of HMAC-based One Time Password
+	Counter uint64
+	// TimeInterval is the period of Time-based One Time Password in seconds
+	// The zero value means 30 seconds
+	TimeInterval uint
+}
+
+// Parse parses a key container that isn't encrypted
+func Parse(r io.Reader) (*Container, error) {
+	return ParseWithOption(r, NewOption())
+}
+
+// ParseWithOption parses a key container and decrypts the encrypted values with the pre-shared key or the passphrase of the option
+func ParseWithOption(r io.Reader, opt *Option) (*Container, error) {
+	if opt == nil {
+		return nil, ErrPSKCOptionIsNil
+	}
+
+	var x xmlKeyContainer
+	if err := xml.NewDecoder(r).Decode(&x); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidContainer, err)
+	}
+	if x.Version != version {
+		return nil, fmt.Errorf("%w: unsupported version %q", ErrInvalidContainer, x.Version)
+	}
+
+	d := &decrypter{c: &x, opt: opt}
+	c := &Container{ID: x.ID}
+	for i, p := range x.KeyPackages {
+		kp, err := d.keyPackage(p)
+		if err != nil {
+			return nil, fmt.Errorf("key package %d: %w", i, err)
+		}
+		c.KeyPackages = append(c.KeyPackages, kp)
+	}
+
+	return c, nil
+}
+
+// decrypter decrypts the values of a key container
+// The key is derived only when the key container has an encrypted value
+type decrypter struct {
+	c   *xmlKeyContainer
+	opt *Option
+
+	loaded bool
+	err    error
+	key    []byte
+	macKey []byte
+	hash   func() hash.Hash
+}
+
+func (d *decrypter) keyPackage(p *xmlKeyPackage) (*KeyPackage, error) {
+	if p.Key == nil {
+		return nil, fmt.Errorf("%w: key is missing", ErrInvalidContainer)
+	}
+
+	k := p.Key
+	kp := &KeyPackage{
+		KeyID:        k.ID,
+		Algorithm:    k.Algorithm,
+		Issuer:       strings.TrimSpace(k.Issuer),
+		FriendlyName: strings.TrimSpace(k.FriendlyName),
+		UserID:       strings.TrimSpace(k.UserID),
+	}
+	if p.DeviceInfo != nil {
+		kp.Manufacturer = strings.TrimSpace(p.DeviceInfo.Manufacturer)
+		kp.SerialNo = strings.TrimSpace(p.DeviceInfo.SerialNo)
+		kp.Model = strings.TrimSpace(p.DeviceInfo.Model)
+	}
+	if ap := k.AlgorithmParameters; ap != nil {
+		kp.Suite = strings.TrimSpace(ap.Suite)
+		if ap.ResponseFormat != nil {
+			kp.Digits = ap.ResponseFormat.Length
+			kp.Encoding = ap.ResponseFormat.Encoding
+		}
+	}
+	if k.Data == nil || k.Data.Secret == nil {
+		return nil, fmt.Errorf("%w: secret is missing", ErrInvalidContainer)
+	}
+
+	secret, err := d.value(k.Data.Secret)
+	if err != nil {
+		return nil, err
+	}
+	kp.Secret = secret
+
+	if v := k.Data.Counter; v != nil {
+		s, err := plainValue("counter", v)
+		if err != nil {
+			return nil, err
+		}
+		if kp.Counter, err = strconv.ParseUint(s, 10, 64); err != nil {
+			return nil, fmt.Errorf("%w: invalid counter %q", ErrInvalidContainer, s)
+		}
+	}
+	if v := k.Data.TimeInterval; v != nil {
+		s, err := plainValue("time interval", v)
+		if err != nil {
+			return nil, err
+		}
+		ti, err := strconv.ParseUint(s, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid time interval %q", ErrInvalidContainer, s)
+		}
+		kp.TimeInterval = uint(ti)
+	}
+
+	return kp, nil
+}
+
+// value returns the binary value that is either plain or encrypted
+func (d *decrypter) value(v *xmlValue) ([]byte, error) {
+	if v.EncryptedValue == nil {
+		b, err := decodeBase64(v.PlainValue)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidContainer, err)
+		}
+		return b, nil
+	}
+
+	if !d.loaded {
+		d.loaded = true
+		d.key, d.err = d.c.encryptionKey(d.opt)
+		if d.err == nil {
+			d.macKey, d.hash, d.err = d.c.macKey(d.key)
+		}
+	}
+	if d.err != nil {
+		return nil, d.err
+	}
+
+	if d.macKey != nil {
+		value, err := v.EncryptedValue.cipherValue()
+		if err != nil {
+			return nil, err
+		}
+		want, err := decodeBase64(v.ValueMAC)
+		if err != nil || !hmac.Equal(want, mac(d.macKey, d.hash, value)) {
+			return nil, ErrInvalidMAC
+		}
+	} else if v.ValueMAC != "" {
+		return nil, fmt.Errorf("%w: mac method is missing", ErrInvalidContainer)
+	}
+
+	return decrypt(d.key, v.EncryptedValue)
+}
+
+// plainValue returns the trimmed plain value of the integer value
+func plainValue(name string, v *xmlValue) (string, error) {
+	if v.EncryptedValue != nil {
+		return "", fmt.Errorf("encrypted %s isn't supported", name)
+	}
+
+	return strings.TrimSpace(v.PlainValue), nil
+}
+
+// Write writes a key container whose secrets aren't encrypted
+func Write(w io.Writer, c *Container) error {
+	return WriteWithOption(w, c, NewOption())
+}
+
+// WriteWithOption writes a key container
+// The secrets are encrypted with AES-CBC and authenticated with HMAC-SHA1 when the option has the pre-shared key or the passphrase
+func WriteWithOption(w io.Writer, c *Container, opt *Option) error {
+	if c == nil {
+		return ErrContainerIsNil
+	}
+	if opt == nil {
+		return ErrPSKCOptionIsNil
+	}
+
+	x := &xmlKeyContainer{Version: version, ID: c.ID}
+	var e *encrypter
+	if opt.encrypted() {
+		var err error
+		if e, err = newEncrypter(opt); err != nil {
+			return err
+		}
+		x.EncryptionKey, x.MACMethod = e.encryptionKey, e.macMethod
+	}
+
+	for i, kp := range c.KeyPackages {
+		p, err := kp.xml(e)
+		if err != nil {
+			return fmt.Errorf("key package %d: %w", i, err)
+		}
+		x.KeyPackages = append(x.KeyPackages, p)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(x); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func (kp *KeyPackage) xml(e *encrypter) (*xmlKeyPackage, error) {
+	if kp == nil {
+		return nil, ErrKeyPackageIsNil
+	}
+	if kp.KeyID == "" {
+		return nil, errors.New("key id is empty")
+	}
+	if kp.Algorithm == "" {
+		return nil, errors.New("algorithm is empty")
+	}
+	if len(kp.Secret) == 0 {
+		return nil, errors.New("secret is empty")
+	}
+
+	k := &xmlKey{
+		ID:           kp.KeyID,
+		Algorithm:    kp.Algorithm,
+		Issuer:       kp.Issuer,
+		FriendlyName: kp.FriendlyName,
+		UserID:       kp.UserID,
+		Data:         &xmlData{},
+	}
+	if kp.Suite != "" || kp.Digits != 0 {
+		k.AlgorithmParameters = &xmlAlgorithmParameters{Suite: kp.Suite}
+		if kp.Digits != 0 {
+			k.AlgorithmParameters.ResponseFormat = &struct {
+				Length   int    `xml:"Length,attr"`
+				Encoding string `xml:"Encoding,attr"`
+			}{Length: kp.Digits, Encoding: kp.Encoding}
+			if kp.Encoding == "" {
+				k.AlgorithmParameters.ResponseFormat.Encoding = encodingDecimal
+			}
+		}
+	}
+
+	if e == nil {
+		k.Data.Secret = &xmlValue{PlainValue: base64.StdEncoding.EncodeToString(kp.Secret)}
+	} else {
+		v, err := e.value(kp.Secret)
+		if err != nil {
+			return nil, err
+		}
+		k.Data.Secret = v
+	}
+	if kp.Algorithm == AlgorithmHOTP || kp.Counter != 0 {
+		k.Data.Counter = &xmlValue{PlainValue: strconv.FormatUint(kp.Counter, 10)}
+	}
+	if kp.TimeInterval != 0 {
+		k.Data.TimeInterval = &xmlValue{PlainValue: strconv.FormatUint(uint64(kp.TimeInterval), 10)}
+	}
+
+	p := &xmlKeyPackage{Key: k}
+	if kp.Manufacturer != "" || kp.SerialNo != "" || kp.Model != "" {
+		p.DeviceInfo = &xmlDeviceInfo{Manufacturer: kp.Manufacturer, SerialNo: kp.SerialNo, Model: kp.Model}
+	}
+
+	return p, nil
+}
+
+// encrypter encrypts the secrets of a key container with a random MAC key
+type encrypter struct {
+	key           []byte
+	macKey        []byte
+	rand          io.Reader
+	encryptionKey *xmlEncryptionKey
+	macMethod     *xmlMACMethod
+}
+
+func newEncrypter(opt *Option) (*encrypter, error) {
+	e := &encrypter{rand: opt.rand}
+	if len(opt.preSharedKey) > 0 {
+		e.key = opt.preSharedKey
+		e.encryptionKey = &xmlEncryptionKey{KeyName: opt.keyName}
+	} else {
+		salt := make([]byte, pbkdf2SaltSize)
+		if _, err := io.ReadFull(opt.rand, salt); err != nil {
+			return nil, err
+		}
+		e.key = pbkdf2.Key(opt.passphrase, salt, opt.pbkdf2Iterations, passphraseKeySize, sha1.New)
+
+		dk := &xmlDerivedKey{MasterKeyName: opt.masterKeyName}
+		dk.KeyDerivationMethod.Algorithm = algorithmPBKDF2
+		dk.KeyDerivationMethod.Params = &xmlPBKDF2Params{IterationCount: opt.pbkdf2Iterations, KeyLength: passphraseKeySize}
+		dk.KeyDerivationMethod.Params.Salt.Specified = base64.StdEncoding.EncodeToString(salt)
+		e.encryptionKey = &xmlEncryptionKey{DerivedKey: dk}
+	}
+
+	e.macKey = make([]byte, macKeySize)
+	if _, err := io.ReadFull(opt.rand, e.macKey); err != nil {
+		return nil, err
+	}
+	encryptedMACKey, _, err := encrypt(e.key, e.macKey, e.rand)
+	if err != nil {
+		return nil, err
+	}
+	e.macMethod = &xmlMACMethod{Algorithm: algorithmHMACSHA1, MACKey: encryptedMACKey}
+
+	return e, nil
+}
+
+func (e *encrypter) value(plaintext []byte) (*xmlValue, error) {
+	v, value, err := encrypt(e.key, plaintext, e.rand)
+	if err != nil {
+		return nil, err
+	}
+
+	return &xmlValue{
+		EncryptedValue: v,
+		ValueMAC:       base64.StdEncoding.EncodeToString(mac(e.macKey, sha1.New, value)),
+	}, nil
+}
@@ -0,0 +1,305 @@
+package pskc_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/butterv/one-time-password/hotp"
+	"github.com/butterv/one-time-password/pskc"
+)
+
+const (
+	// testSecret is the secret of the test vectors of RFC 4226 and RFC 6030
+	testSecret = "12345678901234567890"
+	// testSecret256 is the secret of the test vectors of SHA256 in RFC 6238
+	testSecret256 = "12345678901234567890123456789012"
+)
+
+func parseFile(t *testing.T, name string, opt *pskc.Option) (*pskc.Container, error) {
+	t.Helper()
+	f, err := os.Open("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	return pskc.ParseWithOption(f, opt)
+}
+
+func preSharedKeyOption(t *testing.T, key string) *pskc.Option {
+	t.Helper()
+	b, _ := hex.DecodeString(key)
+	opt := pskc.NewOption()
+	if err := opt.SetPreSharedKey("Pre-shared-key", b); err != nil {
+		t.Fatal(err)
+	}
+
+	return opt
+}
+
+func passphraseOption(t *testing.T, passphrase string) *pskc.Option {
+	t.Helper()
+	opt := pskc.NewOption()
+	if err := opt.SetPassphrase("My Password 1", []byte(passphrase)); err != nil {
+		t.Fatal(err)
+	}
+	_ = opt.SetPBKDF2Iterations(1000)
+
+	return opt
+}
+
+func TestParse(t *testing.T) {
+	c, err := parseFile(t, "hotp.xml", pskc.NewOption())
+	if err != nil {
+		t.Fatalf("Parse()=_, %#v; want nil", err)
+	}
+
+	want := &pskc.Container{
+		ID: "exampleID1",
+		KeyPackages: []*pskc.KeyPackage{{
+			Manufacturer: "Manufacturer",
+			SerialNo:     "987654321",
+			KeyID:        "12345678",
+			Algorithm:    pskc.AlgorithmHOTP,
+			Issuer:       "Issuer",
+			UserID:       "DC=example-bank,DC=net",
+			Digits:       8,
+			Encoding:     "DECIMAL",
+			Secret:       []byte(testSecret),
+		}},
+	}
+	if !reflect.DeepEqual(c, want) {
+		t.Errorf("Parse()=%+v; want %+v", c.KeyPackages[0], want.KeyPackages[0])
+	}
+}
+
+func TestParse_TOTP(t *testing.T) {
+	f, _ := os.Open("testdata/totp.xml")
+	defer f.Close()
+	c, err := pskc.Parse(f)
+	if err != nil {
+		t.Fatalf("Parse()=_, %#v; want nil", err)
+	}
+
+	kp := c.KeyPackages[0]
+	if kp.Algorithm != pskc.AlgorithmTOTP || kp.Suite != "HMAC-SHA256" || kp.TimeInterval != 30 || kp.Model != "Token" || kp.FriendlyName != "Token of Alice" {
+		t.Errorf("Parse() parses %+v", kp)
+	}
+	if got := string(kp.Secret); got != testSecret256 {
+		t.Errorf("Parse() parses secret %q", got)
+	}
+}
+
+func TestParseWithOption_PreSharedKey(t *testing.T) {
+	c, err := parseFile(t, "pre_shared_key.xml", preSharedKeyOption(t, "12345678901234567890123456789012"))
+	if err != nil {
+		t.Fatalf("ParseWithOption()=_, %#v; want nil", err)
+	}
+
+	kp := c.KeyPackages[0]
+	if got := string(kp.Secret); got != testSecret {
+		t.Errorf("ParseWithOption() decrypts secret %q; want %q", got, testSecret)
+	}
+
+	opt, err := kp.HOTPOption()
+	if err != nil {
+		t.Fatalf("HOTPOption()=_, %#v; want nil", err)
+	}
+	if got, _ := hotp.GeneratePasscodeWithOption(kp.Base32Secret(), kp.Counter, opt); got != "84755224" {
+		t.Errorf("GeneratePasscodeWithOption()=%s; want 84755224", got)
+	}
+}
+
+func TestParseWithOption_Passphrase(t *testing.T) {
+	c, err := parseFile(t, "passphrase.xml", passphraseOption(t, "qwerty"))
+	if err != nil {
+		t.Fatalf("ParseWithOption()=_, %#v; want nil", err)
+	}
+
+	kp := c.KeyPackages[0]
+	if got := string(kp.Secret); got != testSecret {
+		t.Errorf("ParseWithOption() decrypts secret %q; want %q", got, testSecret)
+	}
+	if kp.Manufacturer != "TokenVendorAcme" || kp.Issuer != "Example-Issuer" || kp.KeyID != "123456" {
+		t.Errorf("ParseWithOption() parses %+v", kp)
+	}
+}
+
+func TestParseWithOption_Error(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		opt  *pskc.Option
+		want error
+	}{
+		{name: "no pre-shared key", file: "pre_shared_key.xml", opt: pskc.NewOption(), want: pskc.ErrDecryptionKeyRequired},
+		{name: "passphrase for pre-shared key", file: "pre_shared_key.xml", opt: passphraseOption(t, "qwerty"), want: pskc.ErrDecryptionKeyRequired},
+		{name: "no passphrase", file: "passphrase.xml", opt: pskc.NewOption(), want: pskc.ErrDecryptionKeyRequired},
+		{name: "wrong passphrase", file: "passphrase.xml", opt: passphraseOption(t, "password"), want: pskc.ErrDecryptionFailed},
+		{name: "wrong pre-shared key", file: "pre_shared_key.xml", opt: preSharedKeyOption(t, "00000000000000000000000000000000"), want: pskc.ErrDecryptionFailed},
+		{name: "nil option", file: "hotp.xml", opt: nil, want: pskc.ErrPSKCOptionIsNil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseFile(t, tt.file, tt.opt)
+			if !errors.Is(err, tt.want) && !(tt.want == pskc.ErrDecryptionFailed && errors.Is(err, pskc.ErrInvalidMAC)) {
+				t.Errorf("ParseWithOption()=_, %#v; want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseWithOption_InvalidMAC(t *testing.T) {
+	b, _ := ioutil.ReadFile("testdata/pre_shared_key.xml")
+	b = bytes.Replace(b, []byte("Su+NvtQfmvfJzF6bmQiJqoLRExc="), []byte("AAAAAAAAAAAAAAAAAAAAAAAAAAA="), 1)
+
+	_, err := pskc.ParseWithOption(bytes.NewReader(b), preSharedKeyOption(t, "12345678901234567890123456789012"))
+	if !errors.Is(err, pskc.ErrInvalidMAC) {
+		t.Errorf("ParseWithOption()=_, %#v; want %v", err, pskc.ErrInvalidMAC)
+	}
+}
+
+func TestParse_InvalidContainer(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+	}{
+		{name: "not xml", doc: "{}"},
+		{name: "other namespace", doc: `<KeyContainer xmlns="urn:example" Version="1.0"/>`},
+		{name: "unsupported version", doc: `<KeyContainer xmlns="urn:ietf:params:xml:ns:keyprov:pskc" Version="2.0"/>`},
+		{name: "no secret", doc: `<KeyContainer xmlns="urn:ietf:params:xml:ns:keyprov:pskc" Version="1.0"><KeyPackage><Key Id="1" Algorithm="urn:ietf:params:xml:ns:keyprov:pskc:hotp"/></KeyPackage></KeyContainer>`},
+		{name: "negative key length", doc: pbkdf2Container("1000", "-5")},
+		{name: "invalid key length", doc: pbkdf2Container("1000", "20")},
+		{name: "too many iterations", doc: pbkdf2Container("2000000000", "16")},
+		{name: "invalid counter", doc: `<KeyContainer xmlns="urn:ietf:params:xml:ns:keyprov:pskc" Version="1.0"><KeyPackage><Key Id="1" Algorithm="urn:ietf:params:xml:ns:keyprov:pskc:hotp"><Data><Secret><PlainValue>MTIz</PlainValue></Secret><Counter><PlainValue>-1</PlainValue></Counter></Data></Key></KeyPackage></KeyContainer>`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := pskc.ParseWithOption(strings.NewReader(tt.doc), passphraseOption(t, "qwerty"))
+			if !errors.Is(err, pskc.ErrInvalidContainer) {
+				t.Errorf("ParseWithOption()=_, %#v; want %v", err, pskc.ErrInvalidContainer)
+			}
+		})
+	}
+}
+
+// pbkdf2Container returns the key container of passphrase.xml with the parameters of PBKDF2
+func pbkdf2Container(iterations, keyLength string) string {
+	b, _ := ioutil.ReadFile("testdata/passphrase.xml")
+	s := strings.Replace(string(b), "<IterationCount>1000</IterationCount>", "<IterationCount>"+iterations+"</IterationCount>", 1)
+	return strings.Replace(s, "<KeyLength>16</KeyLength>", "<KeyLength>"+keyLength+"</KeyLength>", 1)
+}
+
+func testContainer() *pskc.Container {
+	return &pskc.Container{
+		ID: "container",
+		KeyPackages: []*pskc.KeyPackage{
+			{
+				Manufacturer: "Manufacturer",
+				SerialNo:     "987654321",
+				KeyID:        "12345678",
+				Algorithm:    pskc.AlgorithmHOTP,
+				Issuer:       "Issuer",
+				Digits:       8,
+				Encoding:     "DECIMAL",
+				Secret:       []byte(testSecret),
+				Counter:      10,
+			},
+			{
+				KeyID:        "87654321",
+				Algorithm:    pskc.AlgorithmTOTP,
+				Issuer:       "Issuer",
+				UserID:       "alice@example.com",
+				Suite:        "HMAC-SHA256",
+				Digits:       6,
+				Encoding:     "DECIMAL",
+				Secret:       []byte(testSecret256),
+				TimeInterval: 60,
+			},
+		},
+	}
+}
+
+func TestWrite(t *testing.T) {
+	var b bytes.Buffer
+	if err := pskc.Write(&b, testContainer()); err != nil {
+		t.Fatalf("Write()=%#v; want nil", err)
+	}
+	if strings.Contains(b.String(), "EncryptedValue") {
+		t.Errorf("Write() encrypts the secrets:\n%s", b.String())
+	}
+
+	c, err := pskc.Parse(&b)
+	if err != nil {
+		t.Fatalf("Parse()=_, %#v; want nil", err)
+	}
+	if want := testContainer(); !reflect.DeepEqual(c, want) {
+		t.Errorf("Parse() parses %+v; want %+v", c, want)
+	}
+}
+
+func TestWriteWithOption(t *testing.T) {
+	tests := []struct {
+		name string
+		opt  *pskc.Option
+	}{
+		{name: "pre-shared key", opt: preSharedKeyOption(t, "12345678901234567890123456789012")},
+		{name: "pre-shared key of aes-256", opt: preSharedKeyOption(t, "1234567890123456789012345678901212345678901234567890123456789012")},
+		{name: "passphrase", opt: passphraseOption(t, "qwerty")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b bytes.Buffer
+			if err := pskc.WriteWithOption(&b, testContainer(), tt.opt); err != nil {
+				t.Fatalf("WriteWithOption()=%#v; want nil", err)
+			}
+			if strings.Contains(b.String(), "PlainValue>MTIz") {
+				t.Errorf("WriteWithOption() doesn't encrypt the secrets:\n%s", b.String())
+			}
+
+			doc := b.Bytes()
+			c, err := pskc.ParseWithOption(bytes.NewReader(doc), tt.opt)
+			if err != nil {
+				t.Fatalf("ParseWithOption()=_, %#v; want nil", err)
+			}
+			if want := testContainer(); !reflect.DeepEqual(c, want) {
+				t.Errorf("ParseWithOption() parses %+v; want %+v", c, want)
+			}
+
+			if _, err := pskc.Parse(bytes.NewReader(doc)); !errors.Is(err, pskc.ErrDecryptionKeyRequired) {
+				t.Errorf("Parse()=_, %#v; want %v", err, pskc.ErrDecryptionKeyRequired)
+			}
+		})
+	}
+}
+
+func TestWriteWithOption_Error(t *testing.T) {
+	var b bytes.Buffer
+	if err := pskc.WriteWithOption(&b, nil, pskc.NewOption()); err != pskc.ErrContainerIsNil {
+		t.Errorf("WriteWithOption(nil)=%#v; want %v", err, pskc.ErrContainerIsNil)
+	}
+	if err := pskc.WriteWithOption(&b, testContainer(), nil); err != pskc.ErrPSKCOptionIsNil {
+		t.Errorf("WriteWithOption(_, nil)=%#v; want %v", err, pskc.ErrPSKCOptionIsNil)
+	}
+
+	c := testContainer()
+	c.KeyPackages = append(c.KeyPackages, nil)
+	if err := pskc.Write(&b, c); !errors.Is(err, pskc.ErrKeyPackageIsNil) {
+		t.Errorf("Write()=%#v; want %v", err, pskc.ErrKeyPackageIsNil)
+	}
+
+	c = testContainer()
+	c.KeyPackages[0].Secret = nil
+	if err := pskc.Write(&b, c); err == nil {
+		t.Error("Write() with empty secret=nil; want error")
+	}
+}
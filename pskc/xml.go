@@ -0,0 +1,114 @@
+package pskc
+
+import "encoding/xml"
+
+// The namespaces of PSKC and the specifications that it refers to
+const (
+	namespace         = "urn:ietf:params:xml:ns:keyprov:pskc"
+	namespaceDS       = "http://www.w3.org/2000/09/xmldsig#"
+	namespaceXMLEnc   = "http://www.w3.org/2001/04/xmlenc#"
+	namespaceXMLEnc11 = "http://www.w3.org/2009/xmlenc11#"
+	namespacePKCS5    = "http://www.rsasecurity.com/rsalabs/pkcs/schemas/pkcs-5v2-0#"
+)
+
+// The algorithm identifiers of encryption, MAC and key derivation
+const (
+	algorithmAES128CBC  = namespaceXMLEnc + "aes128-cbc"
+	algorithmAES192CBC  = namespaceXMLEnc + "aes192-cbc"
+	algorithmAES256CBC  = namespaceXMLEnc + "aes256-cbc"
+	algorithmHMACSHA1   = namespaceDS + "hmac-sha1"
+	algorithmHMACSHA256 = "http://www.w3.org/2001/04/xmldsig-more#hmac-sha256"
+	algorithmPBKDF2     = namespacePKCS5 + "pbkdf2"
+)
+
+// The XML elements of PSKC
+// The elements of PSKC have no namespace in the tags, so they match any namespace when the document is parsed
+// and inherit the namespace of the parent when the document is written
+// See: https://tools.ietf.org/html/rfc6030#section-11
+type xmlKeyContainer struct {
+	XMLName       xml.Name          `xml:"urn:ietf:params:xml:ns:keyprov:pskc KeyContainer"`
+	Version       string            `xml:"Version,attr"`
+	ID            string            `xml:"Id,attr,omitempty"`
+	EncryptionKey *xmlEncryptionKey `xml:"EncryptionKey"`
+	MACMethod     *xmlMACMethod     `xml:"MACMethod"`
+	KeyPackages   []*xmlKeyPackage  `xml:"KeyPackage"`
+}
+
+type xmlEncryptionKey struct {
+	KeyName    string         `xml:"http://www.w3.org/2000/09/xmldsig# KeyName,omitempty"`
+	DerivedKey *xmlDerivedKey `xml:"http://www.w3.org/2009/xmlenc11# DerivedKey"`
+}
+
+type xmlDerivedKey struct {
+	KeyDerivationMethod struct {
+		Algorithm string           `xml:"Algorithm,attr"`
+		Params    *xmlPBKDF2Params `xml:"http://www.rsasecurity.com/rsalabs/pkcs/schemas/pkcs-5v2-0# PBKDF2-params"`
+	} `xml:"http://www.w3.org/2009/xmlenc11# KeyDerivationMethod"`
+	MasterKeyName string `xml:"http://www.w3.org/2009/xmlenc11# MasterKeyName,omitempty"`
+}
+
+type xmlPBKDF2Params struct {
+	Salt struct {
+		Specified string `xml:"Specified"`
+	} `xml:"Salt"`
+	IterationCount int `xml:"IterationCount"`
+	KeyLength      int `xml:"KeyLength"`
+	PRF            *struct {
+		Algorithm string `xml:"Algorithm,attr"`
+	} `xml:"PRF"`
+}
+
+type xmlMACMethod struct {
+	Algorithm string             `xml:"Algorithm,attr"`
+	MACKey    *xmlEncryptedValue `xml:"MACKey"`
+}
+
+type xmlEncryptedValue struct {
+	EncryptionMethod struct {
+		Algorithm string `xml:"Algorithm,attr"`
+	} `xml:"http://www.w3.org/2001/04/xmlenc# EncryptionMethod"`
+	CipherData struct {
+		CipherValue string `xml:"CipherValue"`
+	} `xml:"http://www.w3.org/2001/04/xmlenc# CipherData"`
+}
+
+type xmlKeyPackage struct {
+	DeviceInfo *xmlDeviceInfo `xml:"DeviceInfo"`
+	Key        *xmlKey        `xml:"Key"`
+}
+
+type xmlDeviceInfo struct {
+	Manufacturer string `xml:"Manufacturer,omitempty"`
+	SerialNo     string `xml:"SerialNo,omitempty"`
+	Model        string `xml:"Model,omitempty"`
+}
+
+type xmlKey struct {
+	ID                  string                  `xml:"Id,attr"`
+	Algorithm           string                  `xml:"Algorithm,attr"`
+	Issuer              string                  `xml:"Issuer,omitempty"`
+	AlgorithmParameters *xmlAlgorithmParameters `xml:"AlgorithmParameters"`
+	FriendlyName        string                  `xml:"FriendlyName,omitempty"`
+	Data                *xmlData                `xml:"Data"`
+	UserID              string                  `xml:"UserId,omitempty"`
+}
+
+type xmlAlgorithmParameters struct {
+	Suite          string `xml:"Suite,omitempty"`
+	ResponseFormat *struct {
+		Length   int    `xml:"Length,attr"`
+		Encoding string `xml:"Encoding,attr"`
+	} `xml:"ResponseFormat"`
+}
+
+type xmlData struct {
+	Secret       *xmlValue `xml:"Secret"`
+	Counter      *xmlValue `xml:"Counter"`
+	TimeInterval *xmlValue `xml:"TimeInterval"`
+}
+
+type xmlValue struct {
+	PlainValue     string             `xml:"PlainValue,omitempty"`
+	EncryptedValue *xmlEncryptedValue `xml:"EncryptedValue"`
+	ValueMAC       string             `xml:"ValueMAC,omitempty"`
+}
@@ -0,0 +1,76 @@
+package recovery
+
+// Alphabet is the predefined candidate characters of recovery code
+type Alphabet int
+
+const (
+	// AlphabetAlphanumeric is the lower case, upper case letters and digits
+	// This is the default letters of recovery code
+	AlphabetAlphanumeric Alphabet = iota
+	// AlphabetCrockfordBase32 is the Crockford's Base32 alphabet
+	// This excludes I, L, O and U, and accepts I and L as 1 and O as 0 when verifies
+	// See: https://www.crockford.com/base32.html
+	AlphabetCrockfordBase32
+	// AlphabetNoLookalike is the lower case letters and digits without lookalike characters
+	// This excludes 0, 1, i, l and o which are misread as each other
+	AlphabetNoLookalike
+	// AlphabetDigits is the digits
+	// This accepts O as 0 and I and L as 1 when verifies
+	AlphabetDigits
+	// AlphabetHex is the lower case hexadecimal digits
+	// This accepts O as 0 and I and L as 1 when verifies
+	AlphabetHex
+)
+
+// alphabetSpec is the definition of an alphabet
+type alphabetSpec struct {
+	// letters is the candidate characters
+	letters string
+	// caseInsensitive accepts the code typed in the other case
+	caseInsensitive bool
+	// substitutions maps misread characters to the characters of letters
+	substitutions map[rune]rune
+}
+
+var misreadDigits = map[rune]rune{
+	'O': '0', 'o': '0',
+	'I': '1', 'i': '1',
+	'L': '1', 'l': '1',
+}
+
+var alphabetSpecs = map[Alphabet]alphabetSpec{
+	AlphabetAlphanumeric: {
+		letters: defaultLetters,
+	},
+	AlphabetCrockfordBase32: {
+		letters:         "0123456789ABCDEFGHJKMNPQRSTVWXYZ",
+		caseInsensitive: true,
+		substitutions:   misreadDigits,
+	},
+	AlphabetNoLookalike: {
+		letters:         "23456789abcdefghjkmnpqrstuvwxyz",
+		caseInsensitive: true,
+	},
+	AlphabetDigits: {
+		letters:       "0123456789",
+		substitutions: misreadDigits,
+	},
+	AlphabetHex: {
+		letters:         "0123456789abcdef",
+		caseInsensitive: true,
+		substitutions:   misreadDigits,
+	},
+}
+
+func (a Alphabet) enabled() bool {
+	return a >= AlphabetAlphanumeric && a <= AlphabetHex
+}
+
+func (a Alphabet) spec() alphabetSpec {
+	spec, ok := alphabetSpecs[a]
+	if !ok {
+		panic("invalid alphabet")
+	}
+
+	return spec
+}
@@ -0,0 +1,137 @@
+package recovery_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/butterv/one-time-password/recovery"
+)
+
+func TestOption_SetAlphabet(t *testing.T) {
+	tests := []struct {
+		in                  recovery.Alphabet
+		wantLetters         string
+		wantCaseInsensitive bool
+	}{
+		{in: recovery.AlphabetAlphanumeric, wantLetters: "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789", wantCaseInsensitive: false},
+		{in: recovery.AlphabetCrockfordBase32, wantLetters: "0123456789ABCDEFGHJKMNPQRSTVWXYZ", wantCaseInsensitive: true},
+		{in: recovery.AlphabetNoLookalike, wantLetters: "23456789abcdefghjkmnpqrstuvwxyz", wantCaseInsensitive: true},
+		{in: recovery.AlphabetDigits, wantLetters: "0123456789", wantCaseInsensitive: false},
+		{in: recovery.AlphabetHex, wantLetters: "0123456789abcdef", wantCaseInsensitive: true},
+	}
+
+	for _, tt := range tests {
+		o := recovery.NewOption()
+		err := o.SetAlphabet(tt.in)
+		if err != nil {
+			t.Fatalf("SetAlphabet(%d)=%#v; want nil, receiver %#v", tt.in, err, o)
+		}
+		if got := o.Letters(); got != tt.wantLetters {
+			t.Errorf("letters: got %s, want %s, receiver %#v", got, tt.wantLetters, o)
+		}
+		if got := o.CaseInsensitive(); got != tt.wantCaseInsensitive {
+			t.Errorf("caseInsensitive: got %v, want %v, receiver %#v", got, tt.wantCaseInsensitive, o)
+		}
+	}
+}
+
+func TestOption_SetAlphabet_ErrOptionIsNil(t *testing.T) {
+	wantErr := recovery.ErrRecoveryCodeOptionIsNil
+
+	a := recovery.AlphabetHex
+	var o *recovery.Option
+	err := o.SetAlphabet(a)
+	if err == nil {
+		t.Fatalf("SetAlphabet(%d)=nil; want %v, receiver nil", a, wantErr)
+	}
+	if err.Error() != wantErr.Error() {
+		t.Errorf("SetAlphabet(%d)=%#v; want %v, receiver nil", a, err, wantErr)
+	}
+}
+
+func TestOption_SetAlphabet_InvalidAlphabet(t *testing.T) {
+	wantErr := errors.New("invalid alphabet. please pass any of 0 to 4")
+
+	a := recovery.Alphabet(5)
+	o := recovery.NewOption()
+	err := o.SetAlphabet(a)
+	if err == nil {
+		t.Fatalf("SetAlphabet(%d)=nil; want %v, receiver %#v", a, wantErr, o)
+	}
+	if err.Error() != wantErr.Error() {
+		t.Errorf("SetAlphabet(%d)=%#v; want %v, receiver %#v", a, err, wantErr, o)
+	}
+}
+
+func TestNormalizeWithOption_Alphabet(t *testing.T) {
+	tests := []struct {
+		alphabet recovery.Alphabet
+		in       string
+		want     string
+	}{
+		{alphabet: recovery.AlphabetCrockfordBase32, in: "oIlo-abcd", want: "0110ABCD"},
+		{alphabet: recovery.AlphabetNoLookalike, in: "ABCD 2345", want: "abcd2345"},
+		{alphabet: recovery.AlphabetDigits, in: "12O4 5l78", want: "12045178"},
+		{alphabet: recovery.AlphabetHex, in: "DEAD-BEEF-O1", want: "deadbeef01"},
+		{alphabet: recovery.AlphabetAlphanumeric, in: "O0Il", want: "O0Il"},
+	}
+
+	for _, tt := range tests {
+		o := recovery.NewOption()
+		_ = o.SetAlphabet(tt.alphabet)
+		got, err := recovery.NormalizeWithOption(tt.in, o)
+		if err != nil {
+			t.Fatalf("NormalizeWithOption(%q, %d)=_, %#v; want nil", tt.in, tt.alphabet, err)
+		}
+		if got != tt.want {
+			t.Errorf("NormalizeWithOption(%q, %d)=%s, _; want %s", tt.in, tt.alphabet, got, tt.want)
+		}
+	}
+}
+
+func TestGenerateRecoveryCodesWithOption_Alphabet(t *testing.T) {
+	o := recovery.NewOption()
+	_ = o.SetAlphabet(recovery.AlphabetCrockfordBase32)
+
+	codes, err := recovery.GenerateRecoveryCodesWithOption(o)
+	if err != nil {
+		t.Fatalf("GenerateRecoveryCodesWithOption(%v)=_, %#v; want nil", o, err)
+	}
+	for _, code := range codes {
+		for _, r := range code {
+			if !strings.ContainsRune(o.Letters(), r) {
+				t.Errorf("code %s has %q; want only letters of %s", code, r, o.Letters())
+			}
+		}
+	}
+}
+
+func TestGenerateRecoveryCodesWithOption_NonASCIILetters(t *testing.T) {
+	letters := "αβγδεζηθ"
+	for _, f := range []recovery.Format{recovery.FormatSplitByHyphen, recovery.FormatSplitBySpace} {
+		o := recovery.NewOption()
+		_ = o.SetLetters(letters)
+		_ = o.SetLength(7)
+		_ = o.SetFormat(f)
+
+		codes, err := recovery.GenerateRecoveryCodesWithOption(o)
+		if err != nil {
+			t.Fatalf("GenerateRecoveryCodesWithOption(%v)=_, %#v; want nil", o, err)
+		}
+		for _, code := range codes {
+			if !utf8.ValidString(code) {
+				t.Errorf("code %q is invalid UTF-8", code)
+			}
+			if got := utf8.RuneCountInString(code); got != 8 {
+				t.Errorf("code %s has %d characters; want 8", code, got)
+			}
+			for i, r := range []rune(code) {
+				if i != 4 && !strings.ContainsRune(letters, r) {
+					t.Errorf("code %s has %q; want only letters of %s", code, r, letters)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package recovery
+
+import (
+	"errors"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrInvalidFormat is an error when the recovery code doesn't have the format of the option
+// This means the user mistyped the code rather than typed a wrong code
+var ErrInvalidFormat = errors.New("recovery code has invalid format")
+
+// ErrChecksumMismatch is an error when the check character of the recovery code doesn't match
+// This means the user mistyped the code rather than typed a wrong code
+var ErrChecksumMismatch = errors.New("recovery code has mismatched checksum")
+
+// ValidateFormat validates the format of a recovery code typed by the user with using default value of option
+func ValidateFormat(code string) error {
+	opt := NewOption()
+	return ValidateFormatWithOption(code, opt)
+}
+
+// ValidateFormatWithOption validates the format of a recovery code typed by the user by passing option
+// The option must be the same as the one used for generating recovery codes
+// This detects transcription errors without hashing or storage,
+// and the code that passes this may still be a wrong code
+func ValidateFormatWithOption(code string, opt *Option) error {
+	if opt == nil {
+		return ErrRecoveryCodeOptionIsNil
+	}
+
+	normalized, err := NormalizeWithOption(code, opt)
+	if err != nil {
+		return err
+	}
+
+	if opt.passphrase {
+		count, ok := countWords(strings.Split(normalized, opt.wordSeparator))
+		if !ok || count != opt.words() {
+			return ErrInvalidFormat
+		}
+		return nil
+	}
+
+	if uint(utf8.RuneCountInString(normalized)) != opt.codeLength() {
+		return ErrInvalidFormat
+	}
+	for _, r := range normalized {
+		if !strings.ContainsRune(opt.letters, r) {
+			return ErrInvalidFormat
+		}
+	}
+	if opt.checksum && !luhnModNValid([]rune(opt.letters), normalized) {
+		return ErrChecksumMismatch
+	}
+
+	return nil
+}
+
+// countWords counts the words of the wordlist in the tokens
+// A word that has a hyphen like `yo-yo` is split into two tokens by normalization, so they are counted as one word
+// This returns false when any token isn't a word of the wordlist
+func countWords(tokens []string) (uint, bool) {
+	words := make(map[string]bool, len(effShortWordList))
+	for _, w := range effShortWordList {
+		words[w] = true
+	}
+
+	var count uint
+	for i := 0; i < len(tokens); i++ {
+		if i+1 < len(tokens) && words[tokens[i]+"-"+tokens[i+1]] {
+			i++
+		} else if !words[tokens[i]] {
+			return 0, false
+		}
+		count++
+	}
+
+	return count, true
+}
+
+// luhnModNCheckCharacter computes the check character of the code with the Luhn mod N algorithm
+// See: https://en.wikipedia.org/wiki/Luhn_mod_N_algorithm
+func luhnModNCheckCharacter(letters []rune, code string) rune {
+	n := len(letters)
+	sum := luhnModNSum(letters, []rune(code), 2)
+	return letters[(n-sum%n)%n]
+}
+
+// luhnModNValid reports whether the last character of the code is the check character
+func luhnModNValid(letters []rune, code string) bool {
+	return luhnModNSum(letters, []rune(code), 1)%len(letters) == 0
+}
+
+// luhnModNSum sums the code points of the characters from the right doubling every second one
+func luhnModNSum(letters []rune, code []rune, factor int) int {
+	n := len(letters)
+	index := make(map[rune]int, n)
+	for i, r := range letters {
+		index[r] = i
+	}
+
+	sum := 0
+	for i := len(code) - 1; i >= 0; i-- {
+		addend := factor * index[code[i]]
+		if factor == 2 {
+			factor = 1
+		} else {
+			factor = 2
+		}
+		sum += addend/n + addend%n
+	}
+
+	return sum
+}
@@ -0,0 +1,121 @@
+package recovery_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/butterv/one-time-password/recovery"
+)
+
+func TestGenerateRecoveryCodesWithOption_Checksum(t *testing.T) {
+	o := recovery.NewOption()
+	_ = o.SetChecksum(true)
+
+	codes, err := recovery.GenerateRecoveryCodesWithOption(o)
+	if err != nil {
+		t.Fatalf("GenerateRecoveryCodesWithOption(%v)=_, %#v; want nil", o, err)
+	}
+	for _, code := range codes {
+		if len(code) != 9 {
+			t.Errorf("code %s has %d characters; want 9", code, len(code))
+		}
+		if err := recovery.ValidateFormatWithOption(code, o); err != nil {
+			t.Errorf("ValidateFormatWithOption(%s, %v)=%#v; want nil", code, o, err)
+		}
+	}
+}
+
+func TestGenerateRecoveryCodesWithOption_ChecksumWithPassphrase(t *testing.T) {
+	o := recovery.NewOption()
+	_ = o.SetChecksum(true)
+	_ = o.SetPassphrase(true)
+
+	_, err := recovery.GenerateRecoveryCodesWithOption(o)
+	if err == nil {
+		t.Errorf("GenerateRecoveryCodesWithOption(%v)=_, nil; want error", o)
+	}
+}
+
+func TestValidateFormatWithOption(t *testing.T) {
+	o := recovery.NewOption()
+	_ = o.SetAlphabet(recovery.AlphabetCrockfordBase32)
+	_ = o.SetFormat(recovery.FormatSplitByHyphen)
+	_ = o.SetChecksum(true)
+
+	// 01234567 with the check character M computed by Luhn mod 32
+	tests := []struct {
+		in   string
+		want error
+	}{
+		{in: "01234-567M", want: nil},
+		{in: "o1234 567m", want: nil},
+		{in: "01234-567N", want: recovery.ErrChecksumMismatch},
+		{in: "01234-657M", want: recovery.ErrChecksumMismatch},
+		{in: "01234-567", want: recovery.ErrInvalidFormat},
+		{in: "01234-567U", want: recovery.ErrInvalidFormat},
+	}
+
+	for _, tt := range tests {
+		got := recovery.ValidateFormatWithOption(tt.in, o)
+		if got != tt.want {
+			t.Errorf("ValidateFormatWithOption(%s, %v)=%#v; want %v", tt.in, o, got, tt.want)
+		}
+	}
+}
+
+func TestValidateFormat(t *testing.T) {
+	tests := []struct {
+		in   string
+		want error
+	}{
+		{in: "abcd1234", want: nil},
+		{in: "abcd-1234", want: nil},
+		{in: "abcd123", want: recovery.ErrInvalidFormat},
+		{in: "abcd123!", want: recovery.ErrInvalidFormat},
+	}
+
+	for _, tt := range tests {
+		got := recovery.ValidateFormat(tt.in)
+		if got != tt.want {
+			t.Errorf("ValidateFormat(%s)=%#v; want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestValidateFormatWithOption_Passphrase(t *testing.T) {
+	o := recovery.NewOption()
+	_ = o.SetPassphrase(true)
+	_ = o.SetWordCount(3)
+
+	tests := []struct {
+		in   string
+		want error
+	}{
+		{in: "abiding-blimp-yo-yo", want: nil},
+		{in: "abiding blimp zucchini", want: nil},
+		{in: "abiding blimp", want: recovery.ErrInvalidFormat},
+		{in: "abiding blimp zuchini", want: recovery.ErrInvalidFormat},
+	}
+
+	for _, tt := range tests {
+		got := recovery.ValidateFormatWithOption(tt.in, o)
+		if got != tt.want {
+			t.Errorf("ValidateFormatWithOption(%s, %v)=%#v; want %v", tt.in, o, got, tt.want)
+		}
+	}
+}
+
+func TestManager_Redeem_ChecksumMismatch(t *testing.T) {
+	ctx := context.Background()
+	o := recovery.NewOption()
+	_ = o.SetAlphabet(recovery.AlphabetDigits)
+	_ = o.SetChecksum(true)
+	m, _ := recovery.NewManager(recovery.NewMemoryStore(), o, fastHashOption(t, recovery.KDFPBKDF2))
+
+	codes, _ := m.Issue(ctx, "user")
+	typed := []byte(codes[0])
+	typed[0] = '0' + (typed[0]-'0'+1)%10
+	if err := m.Redeem(ctx, "user", string(typed)); err != recovery.ErrChecksumMismatch {
+		t.Errorf("Redeem(user, %s)=%#v; want %v, issued %s", typed, err, recovery.ErrChecksumMismatch, codes[0])
+	}
+}
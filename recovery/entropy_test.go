@@ -0,0 +1,98 @@
+package recovery_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/butterv/one-time-password/recovery"
+)
+
+func TestOption_SetEntropy(t *testing.T) {
+	tests := []struct {
+		name     string
+		alphabet recovery.Alphabet
+		bits     float64
+		want     uint
+	}{
+		{name: "crockford", alphabet: recovery.AlphabetCrockfordBase32, bits: 80, want: 16},
+		{name: "hex", alphabet: recovery.AlphabetHex, bits: 80, want: 20},
+		{name: "alphanumeric", alphabet: recovery.AlphabetAlphanumeric, bits: 80, want: 14},
+		{name: "digits", alphabet: recovery.AlphabetDigits, bits: 64, want: 20},
+		{name: "maximum", alphabet: recovery.AlphabetHex, bits: 256, want: 64},
+	}
+
+	for _, tt := range tests {
+		o := recovery.NewOption()
+		_ = o.SetAlphabet(tt.alphabet)
+		if err := o.SetEntropy(tt.bits); err != nil {
+			t.Fatalf("%s: SetEntropy(%f)=%#v; want nil", tt.name, tt.bits, err)
+		}
+		if got := o.Length(); got != tt.want {
+			t.Errorf("%s: length: got %d, want %d", tt.name, got, tt.want)
+		}
+		if got := o.Entropy(); got < tt.bits {
+			t.Errorf("%s: Entropy()=%f; want %f or greater", tt.name, got, tt.bits)
+		}
+	}
+}
+
+func TestOption_SetEntropy_DerivedAgain(t *testing.T) {
+	o := recovery.NewOption()
+	_ = o.SetEntropy(80)
+	_ = o.SetAlphabet(recovery.AlphabetHex)
+	if got := o.Length(); got != 20 {
+		t.Errorf("length after SetAlphabet: got %d, want 20", got)
+	}
+
+	_ = o.SetLength(8)
+	_ = o.SetAlphabet(recovery.AlphabetCrockfordBase32)
+	if got := o.Length(); got != 8 {
+		t.Errorf("length after SetLength: got %d, want 8", got)
+	}
+}
+
+func TestOption_SetEntropy_Invalid(t *testing.T) {
+	for _, bits := range []float64{0, -1, 256.5, 1e300, math.NaN(), math.Inf(1)} {
+		o := recovery.NewOption()
+		if err := o.SetEntropy(bits); err == nil {
+			t.Errorf("SetEntropy(%f)=nil; want error", bits)
+		}
+	}
+
+	o := recovery.NewOption()
+	_ = o.SetLetters("a")
+	if err := o.SetEntropy(80); err == nil {
+		t.Errorf("SetEntropy(80)=nil; want error, letters a")
+	}
+
+	var nilOpt *recovery.Option
+	if err := nilOpt.SetEntropy(80); err != recovery.ErrRecoveryCodeOptionIsNil {
+		t.Errorf("SetEntropy(80)=%#v; want %v", err, recovery.ErrRecoveryCodeOptionIsNil)
+	}
+}
+
+func TestGenerateRecoveryCodesWithOption_MinEntropy(t *testing.T) {
+	o := recovery.NewOption()
+	_ = o.SetAlphabet(recovery.AlphabetDigits)
+	if err := o.SetMinEntropy(64); err != nil {
+		t.Fatalf("SetMinEntropy(64)=%#v; want nil", err)
+	}
+
+	if _, err := recovery.GenerateRecoveryCodesWithOption(o); err == nil {
+		t.Errorf("GenerateRecoveryCodesWithOption(%v)=_, nil; want error, entropy %f", o, o.Entropy())
+	}
+
+	_ = o.SetEntropy(64)
+	if _, err := recovery.GenerateRecoveryCodesWithOption(o); err != nil {
+		t.Errorf("GenerateRecoveryCodesWithOption(%v)=_, %#v; want nil, entropy %f", o, err, o.Entropy())
+	}
+}
+
+func TestOption_SetMinEntropy_Invalid(t *testing.T) {
+	for _, bits := range []float64{-1, math.NaN(), math.Inf(1)} {
+		o := recovery.NewOption()
+		if err := o.SetMinEntropy(bits); err == nil {
+			t.Errorf("SetMinEntropy(%f)=nil; want error", bits)
+		}
+	}
+}
@@ -1,5 +1,10 @@
 package recovery
 
+import (
+	crand "crypto/rand"
+	"io"
+)
+
 var ExportFormatEnable = Format.enabled
 
 var ExportFormatApply = Format.apply
@@ -36,11 +41,101 @@ func (opt *Option) Format() Format {
 	return opt.format
 }
 
+func (opt *Option) CaseInsensitive() bool {
+	if opt == nil {
+		return false
+	}
+
+	return opt.caseInsensitive
+}
+
+func (opt *Option) Passphrase() bool {
+	if opt == nil {
+		return false
+	}
+
+	return opt.passphrase
+}
+
+func (opt *Option) WordCount() uint {
+	if opt == nil {
+		return 0
+	}
+
+	return opt.wordCount
+}
+
+func (opt *Option) WordSeparator() string {
+	if opt == nil {
+		return ""
+	}
+
+	return opt.wordSeparator
+}
+
+func (opt *Option) GroupSize() uint {
+	if opt == nil {
+		return 0
+	}
+
+	return opt.groupSize
+}
+
+func (opt *Option) GroupSeparator() string {
+	if opt == nil {
+		return ""
+	}
+
+	return opt.groupSeparator
+}
+
+func (opt *Option) Prefix() string {
+	if opt == nil {
+		return ""
+	}
+
+	return opt.prefix
+}
+
+var ExportOptionWords = (*Option).words
+
+var ExportWordList = effShortWordList
+
 func DefaultOption() *Option {
 	return &Option{
-		letters: "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789",
-		length:  8,
-		count:   8,
-		format:  0,
+		letters:         "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789",
+		length:          8,
+		count:           8,
+		format:          0,
+		caseInsensitive: false,
+		wordSeparator:   "-",
+	}
+}
+
+func (opt *HashOption) KDF() KDF {
+	if opt == nil {
+		return 0
 	}
+
+	return opt.kdf
+}
+
+func DefaultHashOption() *HashOption {
+	return &HashOption{
+		kdf:              0,
+		saltSize:         16,
+		bcryptCost:       10,
+		scryptN:          32768,
+		scryptR:          8,
+		scryptP:          1,
+		argon2Time:       1,
+		argon2Memory:     65536,
+		argon2Threads:    4,
+		pbkdf2Iterations: 600000,
+		rand:             crand.Reader,
+	}
+}
+
+func ExportRandIntn(r io.Reader, n int) (int, error) {
+	return newRandSource(r).intn(n)
 }
@@ -0,0 +1,217 @@
+package recovery_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/butterv/one-time-password/recovery"
+)
+
+func TestOption_SetGrouping(t *testing.T) {
+	tests := []struct {
+		size      uint
+		separator string
+		wantErr   bool
+	}{
+		{size: 4, separator: "-", wantErr: false},
+		{size: 3, separator: " / ", wantErr: false},
+		{size: 0, separator: "-", wantErr: true},
+		{size: 4, separator: "", wantErr: true},
+		{size: 4, separator: "a", wantErr: false},
+	}
+
+	for _, tt := range tests {
+		o := recovery.NewOption()
+		err := o.SetGrouping(tt.size, tt.separator)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("SetGrouping(%d, %q)=%#v; want error %t", tt.size, tt.separator, err, tt.wantErr)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if o.GroupSize() != tt.size || o.GroupSeparator() != tt.separator {
+			t.Errorf("SetGrouping(%d, %q) sets %d, %q", tt.size, tt.separator, o.GroupSize(), o.GroupSeparator())
+		}
+	}
+}
+
+func TestOption_SetGrouping_OptionIsNil(t *testing.T) {
+	var o *recovery.Option
+	if err := o.SetGrouping(4, "-"); err != recovery.ErrRecoveryCodeOptionIsNil {
+		t.Errorf("SetGrouping(4, -)=%#v; want %v", err, recovery.ErrRecoveryCodeOptionIsNil)
+	}
+}
+
+func TestGenerateRecoveryCodesWithOption_GroupSeparatorInLetters(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(o *recovery.Option) error
+	}{
+		{
+			name:  "separator of letters",
+			setup: func(o *recovery.Option) error { return o.SetGrouping(4, "a") },
+		},
+		{
+			name: "letters set after grouping",
+			setup: func(o *recovery.Option) error {
+				if err := o.SetGrouping(4, "."); err != nil {
+					return err
+				}
+				return o.SetLetters("abcd.")
+			},
+		},
+		{
+			name: "alphabet set after grouping",
+			setup: func(o *recovery.Option) error {
+				if err := o.SetGrouping(4, "o"); err != nil {
+					return err
+				}
+				// the crockford alphabet reads o as 0
+				return o.SetAlphabet(recovery.AlphabetCrockfordBase32)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		o := recovery.NewOption()
+		if err := tt.setup(o); err != nil {
+			t.Fatalf("%s: setup()=%#v; want nil", tt.name, err)
+		}
+		if _, err := recovery.GenerateRecoveryCodesWithOption(o); err == nil {
+			t.Errorf("%s: GenerateRecoveryCodesWithOption()=nil; want error", tt.name)
+		}
+		if _, err := recovery.NewManager(recovery.NewMemoryStore(), o, recovery.NewHashOption()); err == nil {
+			t.Errorf("%s: NewManager()=nil; want error", tt.name)
+		}
+	}
+}
+
+func TestOption_SetPrefix(t *testing.T) {
+	tests := []struct {
+		in      string
+		wantErr bool
+	}{
+		{in: "ACME", wantErr: false},
+		{in: "acme2", wantErr: false},
+		{in: "", wantErr: true},
+		{in: "AC-ME", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		o := recovery.NewOption()
+		err := o.SetPrefix(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("SetPrefix(%q)=%#v; want error %t", tt.in, err, tt.wantErr)
+			continue
+		}
+		if err == nil && o.Prefix() != tt.in {
+			t.Errorf("SetPrefix(%q) sets %q", tt.in, o.Prefix())
+		}
+	}
+}
+
+func TestGenerateRecoveryCodesWithOption_Grouping(t *testing.T) {
+	o := recovery.NewOption()
+	_ = o.SetAlphabet(recovery.AlphabetCrockfordBase32)
+	_ = o.SetLength(16)
+	_ = o.SetGrouping(4, "-")
+	_ = o.SetPrefix("ACME")
+
+	codes, err := recovery.GenerateRecoveryCodesWithOption(o)
+	if err != nil {
+		t.Fatalf("GenerateRecoveryCodesWithOption(%v)=_, %#v; want nil", o, err)
+	}
+	for _, code := range codes {
+		groups := strings.Split(code, "-")
+		if len(groups) != 5 || groups[0] != "ACME" {
+			t.Errorf("code %s isn't formatted as ACME-XXXX-XXXX-XXXX-XXXX", code)
+			continue
+		}
+		for _, g := range groups[1:] {
+			if len(g) != 4 {
+				t.Errorf("code %s has a group %s; want 4 characters", code, g)
+			}
+		}
+		if err := recovery.ValidateFormatWithOption(code, o); err != nil {
+			t.Errorf("ValidateFormatWithOption(%s, %v)=%#v; want nil", code, o, err)
+		}
+	}
+}
+
+func TestGenerateRecoveryCodesWithOption_GroupingUneven(t *testing.T) {
+	o := recovery.NewOption()
+	_ = o.SetLength(10)
+	_ = o.SetGrouping(3, " ")
+
+	codes, _ := recovery.GenerateRecoveryCodesWithOption(o)
+	for _, code := range codes {
+		groups := strings.Split(code, " ")
+		if len(groups) != 4 || len(groups[3]) != 1 {
+			t.Errorf("code %s isn't formatted as XXX XXX XXX X", code)
+		}
+	}
+}
+
+func TestGenerateRecoveryCodesWithOption_PrefixWithPassphrase(t *testing.T) {
+	o := recovery.NewOption()
+	_ = o.SetPassphrase(true)
+	_ = o.SetWordCount(3)
+	_ = o.SetPrefix("acme")
+
+	codes, _ := recovery.GenerateRecoveryCodesWithOption(o)
+	for _, code := range codes {
+		if !strings.HasPrefix(code, "acme-") {
+			t.Errorf("code %s doesn't begin with acme-", code)
+		}
+		if err := recovery.ValidateFormatWithOption(code, o); err != nil {
+			t.Errorf("ValidateFormatWithOption(%s, %v)=%#v; want nil", code, o, err)
+		}
+	}
+}
+
+func TestNormalizeWithOption_Grouping(t *testing.T) {
+	o := recovery.NewOption()
+	_ = o.SetAlphabet(recovery.AlphabetCrockfordBase32)
+	_ = o.SetGrouping(2, "/")
+	_ = o.SetPrefix("ACME")
+
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "ACME/01/23/45/67", want: "01234567"},
+		{in: "acme 01 23 45 67", want: "01234567"},
+		{in: "01/23/45/67", want: "01234567"},
+		// the code that begins with the same characters as the prefix is kept
+		{in: "AC/ME/45/67", want: "ACME4567"},
+	}
+
+	for _, tt := range tests {
+		got, err := recovery.NormalizeWithOption(tt.in, o)
+		if err != nil {
+			t.Fatalf("NormalizeWithOption(%s, %v)=_, %#v; want nil", tt.in, o, err)
+		}
+		if got != tt.want {
+			t.Errorf("NormalizeWithOption(%s, %v)=%s; want %s", tt.in, o, got, tt.want)
+		}
+	}
+}
+
+func TestManager_Redeem_Grouping(t *testing.T) {
+	ctx := context.Background()
+	o := recovery.NewOption()
+	_ = o.SetGrouping(4, ".")
+	_ = o.SetPrefix("ACME")
+	m, _ := recovery.NewManager(recovery.NewMemoryStore(), o, fastHashOption(t, recovery.KDFPBKDF2))
+
+	codes, _ := m.Issue(ctx, "user")
+	typed := strings.TrimPrefix(codes[0], "ACME.")
+	if err := m.Redeem(ctx, "user", typed); err != nil {
+		t.Errorf("Redeem(user, %s)=%#v; want nil", typed, err)
+	}
+	if err := m.Redeem(ctx, "user", codes[0]); err != recovery.ErrRecoveryCodeConsumed {
+		t.Errorf("Redeem(user, %s)=%#v; want %v", codes[0], err, recovery.ErrRecoveryCodeConsumed)
+	}
+}
@@ -0,0 +1,436 @@
+package recovery
+
+import (
+	crand "crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	defaultSaltSize = 16
+	hashKeySize     = 32
+
+	defaultBcryptCost       = bcrypt.DefaultCost
+	defaultScryptN          = 1 << 15
+	defaultScryptR          = 8
+	defaultScryptP          = 1
+	defaultArgon2Time       = 1
+	defaultArgon2Memory     = 64 * 1024
+	defaultArgon2Threads    = 4
+	defaultPBKDF2Iterations = 600000
+)
+
+// ErrHashOptionIsNil is an error when the hash option is nil
+var ErrHashOptionIsNil = errors.New("hash option is nil")
+
+// ErrCodeSetIsNil is an error when the code set is nil
+var ErrCodeSetIsNil = errors.New("code set is nil")
+
+// ErrInvalidHash is an error when the hashed recovery code is malformed
+var ErrInvalidHash = errors.New("invalid hash of recovery code")
+
+// KDF is the key derivation function to hash recovery codes
+type KDF int
+
+const (
+	// KDFArgon2id is the key derivation function of Argon2id
+	// See: https://tools.ietf.org/html/rfc9106
+	KDFArgon2id KDF = iota
+	// KDFBcrypt is the key derivation function of bcrypt
+	KDFBcrypt
+	// KDFScrypt is the key derivation function of scrypt
+	// See: https://tools.ietf.org/html/rfc7914
+	KDFScrypt
+	// KDFPBKDF2 is the key derivation function of PBKDF2 with HMAC-SHA256
+	// See: https://tools.ietf.org/html/rfc8018#section-5.2
+	KDFPBKDF2
+)
+
+func (k KDF) enabled() bool {
+	return k >= KDFArgon2id && k <= KDFPBKDF2
+}
+
+// HashOption is used when hashes recovery codes
+type HashOption struct {
+	// kdf is the key derivation function
+	// The default value is Argon2id
+	kdf KDF
+	// saltSize is the size of the salt generated for each code
+	// The default value is 16 bytes
+	saltSize uint
+	// bcryptCost is the cost of bcrypt
+	bcryptCost int
+	// scryptN, scryptR and scryptP are the parameters of scrypt
+	scryptN, scryptR, scryptP int
+	// argon2Time, argon2Memory and argon2Threads are the parameters of Argon2id
+	argon2Time, argon2Memory uint32
+	argon2Threads            uint8
+	// pbkdf2Iterations is the iteration count of PBKDF2
+	pbkdf2Iterations int
+	// rand is the reader to use for generating salt
+	// The default value is reader of crypto/rand
+	rand io.Reader
+}
+
+// SetKDF sets the key derivation function
+func (opt *HashOption) SetKDF(k KDF) error {
+	if opt == nil {
+		return ErrHashOptionIsNil
+	}
+	if !k.enabled() {
+		return fmt.Errorf("invalid kdf. please pass any of %d to %d", KDFArgon2id, KDFPBKDF2)
+	}
+
+	opt.kdf = k
+	return nil
+}
+
+// SetSaltSize sets the size of the salt generated for each code
+func (opt *HashOption) SetSaltSize(size uint) error {
+	if opt == nil {
+		return ErrHashOptionIsNil
+	}
+	if size < 8 {
+		return errors.New("invalid saltSize. please pass 8 or greater")
+	}
+
+	opt.saltSize = size
+	return nil
+}
+
+// SetBcryptCost sets the cost of bcrypt
+func (opt *HashOption) SetBcryptCost(cost int) error {
+	if opt == nil {
+		return ErrHashOptionIsNil
+	}
+	if cost < bcrypt.MinCost || cost > bcrypt.MaxCost {
+		return fmt.Errorf("invalid bcrypt cost. please pass any of %d to %d", bcrypt.MinCost, bcrypt.MaxCost)
+	}
+
+	opt.bcryptCost = cost
+	return nil
+}
+
+// SetScryptParams sets the CPU/memory cost N, the block size r and the parallelization p of scrypt
+func (opt *HashOption) SetScryptParams(n, r, p int) error {
+	if opt == nil {
+		return ErrHashOptionIsNil
+	}
+	if n <= 1 || n&(n-1) != 0 {
+		return errors.New("invalid scrypt N. please pass a power of 2 greater than 1")
+	}
+	if r <= 0 || p <= 0 {
+		return errors.New("invalid scrypt r or p. please pass greater than 0")
+	}
+
+	opt.scryptN, opt.scryptR, opt.scryptP = n, r, p
+	return nil
+}
+
+// SetArgon2Params sets the time, the memory in KiB and the threads of Argon2id
+func (opt *HashOption) SetArgon2Params(time, memory uint32, threads uint8) error {
+	if opt == nil {
+		return ErrHashOptionIsNil
+	}
+	if time == 0 || memory == 0 || threads == 0 {
+		return errors.New("invalid argon2 params. please pass greater than 0")
+	}
+
+	opt.argon2Time, opt.argon2Memory, opt.argon2Threads = time, memory, threads
+	return nil
+}
+
+// SetPBKDF2Iterations sets the iteration count of PBKDF2
+func (opt *HashOption) SetPBKDF2Iterations(iterations int) error {
+	if opt == nil {
+		return ErrHashOptionIsNil
+	}
+	if iterations <= 0 {
+		return errors.New("invalid pbkdf2 iterations. please pass greater than 0")
+	}
+
+	opt.pbkdf2Iterations = iterations
+	return nil
+}
+
+// NewHashOption generates a hash option with default values
+func NewHashOption() *HashOption {
+	return &HashOption{
+		kdf:              KDFArgon2id,
+		saltSize:         defaultSaltSize,
+		bcryptCost:       defaultBcryptCost,
+		scryptN:          defaultScryptN,
+		scryptR:          defaultScryptR,
+		scryptP:          defaultScryptP,
+		argon2Time:       defaultArgon2Time,
+		argon2Memory:     defaultArgon2Memory,
+		argon2Threads:    defaultArgon2Threads,
+		pbkdf2Iterations: defaultPBKDF2Iterations,
+		rand:             crand.Reader,
+	}
+}
+
+// hash hashes the code with a new salt
+// The hash is encoded in the PHC string format except bcrypt which has its own format
+func (opt *HashOption) hash(code string) (string, error) {
+	if opt.kdf == KDFBcrypt {
+		h, err := bcrypt.GenerateFromPassword([]byte(code), opt.bcryptCost)
+		if err != nil {
+			return "", err
+		}
+		return string(h), nil
+	}
+
+	salt := make([]byte, opt.saltSize)
+	_, err := io.ReadFull(opt.rand, salt)
+	if err != nil {
+		return "", err
+	}
+
+	var id, params string
+	var key []byte
+	switch opt.kdf {
+	case KDFArgon2id:
+		id = "argon2id"
+		params = fmt.Sprintf("v=%d$m=%d,t=%d,p=%d", argon2.Version, opt.argon2Memory, opt.argon2Time, opt.argon2Threads)
+		key = argon2.IDKey([]byte(code), salt, opt.argon2Time, opt.argon2Memory, opt.argon2Threads, hashKeySize)
+	case KDFScrypt:
+		id = "scrypt"
+		params = fmt.Sprintf("n=%d,r=%d,p=%d", opt.scryptN, opt.scryptR, opt.scryptP)
+		key, err = scrypt.Key([]byte(code), salt, opt.scryptN, opt.scryptR, opt.scryptP, hashKeySize)
+		if err != nil {
+			return "", err
+		}
+	case KDFPBKDF2:
+		id = "pbkdf2-sha256"
+		params = fmt.Sprintf("i=%d", opt.pbkdf2Iterations)
+		key = pbkdf2.Key([]byte(code), salt, opt.pbkdf2Iterations, hashKeySize, sha256.New)
+	default:
+		panic("invalid kdf")
+	}
+
+	return fmt.Sprintf("$%s$%s$%s$%s", id, params,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// verifyHash reports whether the code matches the encoded hash
+func verifyHash(encoded, code string) (bool, error) {
+	if isBcryptHash(encoded) {
+		err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(code))
+		if err == bcrypt.ErrMismatchedHashAndPassword {
+			return false, nil
+		}
+		if err != nil {
+			return false, ErrInvalidHash
+		}
+		return true, nil
+	}
+
+	derive, want, err := parseHash(encoded)
+	if err != nil {
+		return false, err
+	}
+
+	return subtle.ConstantTimeCompare(derive(code), want) == 1, nil
+}
+
+// validateHash validates the format of the encoded hash without deriving a key
+func validateHash(encoded string) error {
+	if isBcryptHash(encoded) {
+		if _, err := bcrypt.Cost([]byte(encoded)); err != nil {
+			return ErrInvalidHash
+		}
+		return nil
+	}
+
+	_, _, err := parseHash(encoded)
+	return err
+}
+
+func isBcryptHash(encoded string) bool {
+	return strings.HasPrefix(encoded, "$2")
+}
+
+// parseHash parses the hash in the PHC string format
+// This returns the function that derives a key from the code with the same parameters and the expected key
+func parseHash(encoded string) (func(code string) []byte, []byte, error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) < 5 || parts[0] != "" {
+		return nil, nil, ErrInvalidHash
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[len(parts)-2])
+	if err != nil {
+		return nil, nil, ErrInvalidHash
+	}
+	want, err := base64.RawStdEncoding.DecodeString(parts[len(parts)-1])
+	if err != nil || len(want) == 0 {
+		return nil, nil, ErrInvalidHash
+	}
+
+	switch {
+	case parts[1] == "argon2id" && len(parts) == 6:
+		var version int
+		var memory, time uint32
+		var threads uint8
+		if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+			return nil, nil, ErrInvalidHash
+		}
+		if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &time, &threads); err != nil || memory == 0 || time == 0 || threads == 0 {
+			return nil, nil, ErrInvalidHash
+		}
+		return func(code string) []byte {
+			return argon2.IDKey([]byte(code), salt, time, memory, threads, uint32(len(want)))
+		}, want, nil
+	case parts[1] == "scrypt" && len(parts) == 5:
+		var n, r, p int
+		if _, err := fmt.Sscanf(parts[2], "n=%d,r=%d,p=%d", &n, &r, &p); err != nil {
+			return nil, nil, ErrInvalidHash
+		}
+		if n <= 1 || n&(n-1) != 0 || r <= 0 || p <= 0 || uint64(r)*uint64(p) >= 1<<30 {
+			return nil, nil, ErrInvalidHash
+		}
+		return func(code string) []byte {
+			key, _ := scrypt.Key([]byte(code), salt, n, r, p, len(want))
+			return key
+		}, want, nil
+	case parts[1] == "pbkdf2-sha256" && len(parts) == 5:
+		var iterations int
+		if _, err := fmt.Sscanf(parts[2], "i=%d", &iterations); err != nil || iterations <= 0 {
+			return nil, nil, ErrInvalidHash
+		}
+		return func(code string) []byte {
+			return pbkdf2.Key([]byte(code), salt, iterations, len(want), sha256.New)
+		}, want, nil
+	}
+
+	return nil, nil, ErrInvalidHash
+}
+
+// CodeSet is a set of hashed recovery codes
+type CodeSet struct {
+	hashes []string
+}
+
+// HashRecoveryCodes hashes recovery codes with using default value of hash option
+func HashRecoveryCodes(codes []string) (*CodeSet, error) {
+	return HashRecoveryCodesWithOption(codes, NewHashOption())
+}
+
+// HashRecoveryCodesWithOption hashes recovery codes by passing hash option
+// Each code is hashed with its own salt
+func HashRecoveryCodesWithOption(codes []string, opt *HashOption) (*CodeSet, error) {
+	if opt == nil {
+		return nil, ErrHashOptionIsNil
+	}
+	if len(codes) == 0 {
+		return nil, errors.New("codes is empty")
+	}
+
+	hashes := make([]string, 0, len(codes))
+	for _, code := range codes {
+		h, err := opt.hash(code)
+		if err != nil {
+			return nil, err
+		}
+		hashes = append(hashes, h)
+	}
+
+	return &CodeSet{hashes: hashes}, nil
+}
+
+// NewCodeSet generates a code set from stored hashes
+func NewCodeSet(hashes []string) (*CodeSet, error) {
+	for _, h := range hashes {
+		if err := validateHash(h); err != nil {
+			return nil, err
+		}
+	}
+
+	hs := make([]string, len(hashes))
+	copy(hs, hashes)
+	return &CodeSet{hashes: hs}, nil
+}
+
+// Hashes returns the hashed recovery codes
+func (s *CodeSet) Hashes() []string {
+	if s == nil {
+		return nil
+	}
+
+	hs := make([]string, len(s.hashes))
+	copy(hs, s.hashes)
+	return hs
+}
+
+// Len returns the number of hashed recovery codes
+func (s *CodeSet) Len() int {
+	if s == nil {
+		return 0
+	}
+
+	return len(s.hashes)
+}
+
+// MarshalJSON returns the storable form of the code set
+func (s *CodeSet) MarshalJSON() ([]byte, error) {
+	if s == nil {
+		return nil, ErrCodeSetIsNil
+	}
+
+	return json.Marshal(s.hashes)
+}
+
+// UnmarshalJSON parses the storable form of the code set
+func (s *CodeSet) UnmarshalJSON(b []byte) error {
+	if s == nil {
+		return ErrCodeSetIsNil
+	}
+
+	var hashes []string
+	err := json.Unmarshal(b, &hashes)
+	if err != nil {
+		return err
+	}
+
+	cs, err := NewCodeSet(hashes)
+	if err != nil {
+		return err
+	}
+
+	s.hashes = cs.hashes
+	return nil
+}
+
+// Verify verifies the code against every hashed recovery code
+// This returns the index of the matched code
+// Every hash is checked even after a match, so the time doesn't reveal which code matched
+func (s *CodeSet) Verify(code string) (int, bool, error) {
+	if s == nil {
+		return -1, false, ErrCodeSetIsNil
+	}
+
+	index := -1
+	for i, h := range s.hashes {
+		ok, err := verifyHash(h, code)
+		if err != nil {
+			return -1, false, err
+		}
+		if ok && index < 0 {
+			index = i
+		}
+	}
+
+	return index, index >= 0, nil
+}
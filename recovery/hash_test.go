@@ -0,0 +1,149 @@
+package recovery_test
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/butterv/one-time-password/recovery"
+)
+
+// fastHashOption returns a hash option with the cheapest parameters to keep tests fast
+func fastHashOption(t *testing.T, kdf recovery.KDF) *recovery.HashOption {
+	t.Helper()
+
+	o := recovery.NewHashOption()
+	_ = o.SetKDF(kdf)
+	_ = o.SetBcryptCost(4)
+	_ = o.SetScryptParams(16, 1, 1)
+	_ = o.SetArgon2Params(1, 64, 1)
+	_ = o.SetPBKDF2Iterations(1)
+	return o
+}
+
+func TestHashOption_SetKDF(t *testing.T) {
+	want := recovery.KDFScrypt
+
+	kdf := recovery.KDFScrypt
+	o := &recovery.HashOption{}
+	err := o.SetKDF(kdf)
+	if err != nil {
+		t.Fatalf("SetKDF(%d)=%#v; want nil, receiver %#v", kdf, err, o)
+	}
+	if got := o.KDF(); got != want {
+		t.Errorf("kdf: got %d, want %d, receiver %#v", got, want, o)
+	}
+}
+
+func TestHashOption_SetKDF_ErrHashOptionIsNil(t *testing.T) {
+	wantErr := recovery.ErrHashOptionIsNil
+
+	kdf := recovery.KDFScrypt
+	var o *recovery.HashOption
+	err := o.SetKDF(kdf)
+	if err == nil {
+		t.Fatalf("SetKDF(%d)=nil; want %v, receiver nil", kdf, wantErr)
+	}
+	if err.Error() != wantErr.Error() {
+		t.Errorf("SetKDF(%d)=%#v; want %v, receiver nil", kdf, err, wantErr)
+	}
+}
+
+func TestHashOption_SetKDF_InvalidKDF(t *testing.T) {
+	wantErr := errors.New("invalid kdf. please pass any of 0 to 3")
+
+	kdf := recovery.KDF(4)
+	o := &recovery.HashOption{}
+	err := o.SetKDF(kdf)
+	if err == nil {
+		t.Fatalf("SetKDF(%d)=nil; want %v, receiver %#v", kdf, wantErr, o)
+	}
+	if err.Error() != wantErr.Error() {
+		t.Errorf("SetKDF(%d)=%#v; want %v, receiver %#v", kdf, err, wantErr, o)
+	}
+}
+
+func TestNewHashOption(t *testing.T) {
+	want := recovery.DefaultHashOption()
+
+	got := recovery.NewHashOption()
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("NewHashOption()=%#v; want %v", got, want)
+	}
+}
+
+func TestCodeSet_Verify(t *testing.T) {
+	tests := []struct {
+		kdf    recovery.KDF
+		prefix string
+	}{
+		{kdf: recovery.KDFArgon2id, prefix: "$argon2id$v=19$m=64,t=1,p=1$"},
+		{kdf: recovery.KDFBcrypt, prefix: "$2a$04$"},
+		{kdf: recovery.KDFScrypt, prefix: "$scrypt$n=16,r=1,p=1$"},
+		{kdf: recovery.KDFPBKDF2, prefix: "$pbkdf2-sha256$i=1$"},
+	}
+
+	codes := []string{"abcd1234", "efgh5678"}
+	for _, tt := range tests {
+		s, err := recovery.HashRecoveryCodesWithOption(codes, fastHashOption(t, tt.kdf))
+		if err != nil {
+			t.Fatalf("HashRecoveryCodesWithOption(%v, %d)=_, %#v; want nil", codes, tt.kdf, err)
+		}
+		for _, h := range s.Hashes() {
+			if !strings.HasPrefix(h, tt.prefix) {
+				t.Errorf("hash %s; want prefix %s", h, tt.prefix)
+			}
+		}
+
+		i, ok, err := s.Verify("efgh5678")
+		if err != nil {
+			t.Fatalf("Verify(efgh5678)=_, _, %#v; want nil, kdf %d", err, tt.kdf)
+		}
+		if !ok || i != 1 {
+			t.Errorf("Verify(efgh5678)=%d, %v, _; want 1, true, kdf %d", i, ok, tt.kdf)
+		}
+
+		i, ok, err = s.Verify("wrong")
+		if err != nil {
+			t.Fatalf("Verify(wrong)=_, _, %#v; want nil, kdf %d", err, tt.kdf)
+		}
+		if ok || i != -1 {
+			t.Errorf("Verify(wrong)=%d, %v, _; want -1, false, kdf %d", i, ok, tt.kdf)
+		}
+	}
+}
+
+func TestCodeSet_JSON(t *testing.T) {
+	codes := []string{"abcd1234", "efgh5678"}
+	s, _ := recovery.HashRecoveryCodesWithOption(codes, fastHashOption(t, recovery.KDFPBKDF2))
+
+	b, err := json.Marshal(s)
+	if err != nil {
+		t.Fatalf("json.Marshal(%v)=_, %#v; want nil", s, err)
+	}
+
+	var got recovery.CodeSet
+	err = json.Unmarshal(b, &got)
+	if err != nil {
+		t.Fatalf("json.Unmarshal(%s)=%#v; want nil", b, err)
+	}
+	if !reflect.DeepEqual(got.Hashes(), s.Hashes()) {
+		t.Errorf("Hashes()=%v; want %v", got.Hashes(), s.Hashes())
+	}
+	if _, ok, _ := got.Verify("abcd1234"); !ok {
+		t.Errorf("Verify(abcd1234)=_, false, _; want true")
+	}
+}
+
+func TestNewCodeSet_InvalidHash(t *testing.T) {
+	wantErr := recovery.ErrInvalidHash
+
+	for _, h := range []string{"plain", "$md5$x$y$z", "$pbkdf2-sha256$i=0$AAAA$AAAA", "$2a$99$invalid"} {
+		_, err := recovery.NewCodeSet([]string{h})
+		if err != wantErr {
+			t.Errorf("NewCodeSet([%s])=_, %#v; want %v", h, err, wantErr)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package recovery
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrManagerIsNil is an error when the recovery code manager is nil
+var ErrManagerIsNil = errors.New("recovery code manager is nil")
+
+// ErrInvalidRecoveryCode is an error when the recovery code doesn't match any code of the user
+var ErrInvalidRecoveryCode = errors.New("invalid recovery code")
+
+// ErrRecoveryCodeConsumed is an error when the recovery code has already been consumed
+var ErrRecoveryCodeConsumed = errors.New("recovery code has already been consumed")
+
+// Manager issues recovery codes and consumes each of them only once
+type Manager struct {
+	// store persists hashed recovery codes
+	store Store
+	// opt is used when generates recovery codes
+	opt *Option
+	// hashOpt is used when hashes recovery codes
+	hashOpt *HashOption
+}
+
+// NewManager generates a recovery code manager by passing store and options
+func NewManager(store Store, opt *Option, hashOpt *HashOption) (*Manager, error) {
+	if store == nil {
+		return nil, errors.New("store is nil")
+	}
+	if opt == nil {
+		return nil, ErrRecoveryCodeOptionIsNil
+	}
+	if hashOpt == nil {
+		return nil, ErrHashOptionIsNil
+	}
+	if err := opt.validate(); err != nil {
+		return nil, err
+	}
+
+	return &Manager{
+		store:   store,
+		opt:     opt,
+		hashOpt: hashOpt,
+	}, nil
+}
+
+// Issue generates new recovery codes of the user and stores their hashes
+// The codes issued before are discarded
+// The returned plaintext codes must be shown to the user only once
+func (m *Manager) Issue(ctx context.Context, userID string) ([]string, error) {
+	if m == nil {
+		return nil, ErrManagerIsNil
+	}
+
+	codes, err := GenerateRecoveryCodesWithOption(m.opt)
+	if err != nil {
+		return nil, err
+	}
+
+	// the codes are hashed without format, so the code typed in any format can be verified
+	normalized := make([]string, 0, len(codes))
+	for _, code := range codes {
+		n, err := NormalizeWithOption(code, m.opt)
+		if err != nil {
+			return nil, err
+		}
+		normalized = append(normalized, n)
+	}
+
+	set, err := HashRecoveryCodesWithOption(normalized, m.hashOpt)
+	if err != nil {
+		return nil, err
+	}
+
+	err = m.store.Save(ctx, userID, set.Hashes())
+	if err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// Redeem verifies the recovery code of the user and consumes it
+// The code is normalized by the option, so the code typed with or without separators is accepted
+// This returns ErrInvalidFormat or ErrChecksumMismatch when the code is mistyped,
+// ErrRecoveryCodeConsumed when the code has already been consumed,
+// and ErrInvalidRecoveryCode when the code doesn't match any code
+// Concurrent requests with the same code are consumed only once by the store
+func (m *Manager) Redeem(ctx context.Context, userID, code string) error {
+	if m == nil {
+		return ErrManagerIsNil
+	}
+
+	// a mistyped code is rejected before hitting the store
+	err := ValidateFormatWithOption(code, m.opt)
+	if err != nil {
+		return err
+	}
+
+	stored, err := m.store.List(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	hashes := make([]string, 0, len(stored))
+	for _, c := range stored {
+		hashes = append(hashes, c.Hash)
+	}
+	set, err := NewCodeSet(hashes)
+	if err != nil {
+		return err
+	}
+
+	normalized, err := NormalizeWithOption(code, m.opt)
+	if err != nil {
+		return err
+	}
+
+	i, ok, err := set.Verify(normalized)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrInvalidRecoveryCode
+	}
+	if stored[i].Consumed {
+		return ErrRecoveryCodeConsumed
+	}
+
+	return m.store.Consume(ctx, userID, stored[i].Hash)
+}
+
+// Remaining returns the number of recovery codes of the user that are not consumed
+func (m *Manager) Remaining(ctx context.Context, userID string) (int, error) {
+	if m == nil {
+		return 0, ErrManagerIsNil
+	}
+
+	stored, err := m.store.List(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	remaining := 0
+	for _, c := range stored {
+		if !c.Consumed {
+			remaining++
+		}
+	}
+
+	return remaining, nil
+}
@@ -0,0 +1,97 @@
+package recovery_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/butterv/one-time-password/recovery"
+)
+
+func newManager(t *testing.T, store recovery.Store) *recovery.Manager {
+	t.Helper()
+
+	m, err := recovery.NewManager(store, recovery.NewOption(), fastHashOption(t, recovery.KDFPBKDF2))
+	if err != nil {
+		t.Fatalf("NewManager()=_, %#v; want nil", err)
+	}
+
+	return m
+}
+
+func TestManager_Redeem(t *testing.T) {
+	ctx := context.Background()
+	m := newManager(t, recovery.NewMemoryStore())
+
+	codes, err := m.Issue(ctx, "user")
+	if err != nil {
+		t.Fatalf("Issue(user)=_, %#v; want nil", err)
+	}
+	if got, _ := m.Remaining(ctx, "user"); got != len(codes) {
+		t.Errorf("Remaining(user)=%d, _; want %d", got, len(codes))
+	}
+
+	if err := m.Redeem(ctx, "user", codes[0]); err != nil {
+		t.Fatalf("Redeem(user, %s)=%#v; want nil", codes[0], err)
+	}
+	if err := m.Redeem(ctx, "user", codes[0]); err != recovery.ErrRecoveryCodeConsumed {
+		t.Errorf("Redeem(user, %s)=%#v; want %v", codes[0], err, recovery.ErrRecoveryCodeConsumed)
+	}
+	if err := m.Redeem(ctx, "user", "invalid"); err != recovery.ErrInvalidFormat {
+		t.Errorf("Redeem(user, invalid)=%#v; want %v", err, recovery.ErrInvalidFormat)
+	}
+	if err := m.Redeem(ctx, "user", "invalid0"); err != recovery.ErrInvalidRecoveryCode {
+		t.Errorf("Redeem(user, invalid0)=%#v; want %v", err, recovery.ErrInvalidRecoveryCode)
+	}
+	if err := m.Redeem(ctx, "another", codes[1]); err != recovery.ErrInvalidRecoveryCode {
+		t.Errorf("Redeem(another, %s)=%#v; want %v", codes[1], err, recovery.ErrInvalidRecoveryCode)
+	}
+	if got, _ := m.Remaining(ctx, "user"); got != len(codes)-1 {
+		t.Errorf("Remaining(user)=%d, _; want %d", got, len(codes)-1)
+	}
+}
+
+func TestManager_Issue_DiscardsOldCodes(t *testing.T) {
+	ctx := context.Background()
+	m := newManager(t, recovery.NewMemoryStore())
+
+	old, _ := m.Issue(ctx, "user")
+	_, _ = m.Issue(ctx, "user")
+
+	if err := m.Redeem(ctx, "user", old[0]); err != recovery.ErrInvalidRecoveryCode {
+		t.Errorf("Redeem(user, %s)=%#v; want %v", old[0], err, recovery.ErrInvalidRecoveryCode)
+	}
+}
+
+func TestManager_Redeem_Concurrent(t *testing.T) {
+	ctx := context.Background()
+	m := newManager(t, recovery.NewMemoryStore())
+	codes, _ := m.Issue(ctx, "user")
+
+	const n = 16
+	var wg sync.WaitGroup
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- m.Redeem(ctx, "user", codes[0])
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	succeeded := 0
+	for err := range errs {
+		switch err {
+		case nil:
+			succeeded++
+		case recovery.ErrRecoveryCodeConsumed:
+		default:
+			t.Errorf("Redeem(user, %s)=%#v; want nil or %v", codes[0], err, recovery.ErrRecoveryCodeConsumed)
+		}
+	}
+	if succeeded != 1 {
+		t.Errorf("succeeded %d times; want 1", succeeded)
+	}
+}
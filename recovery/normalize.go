@@ -0,0 +1,107 @@
+package recovery
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Normalize normalizes a recovery code typed by the user with using default value of option
+func Normalize(code string) (string, error) {
+	opt := NewOption()
+	return NormalizeWithOption(code, opt)
+}
+
+// NormalizeWithOption normalizes a recovery code typed by the user by passing option
+// The option must be the same as the one used for generating recovery codes
+// This removes whitespace and the separators that any Format inserts, folds case when the option is case insensitive,
+// and replaces misread characters that the alphabet of the option defines
+// The separators between groups and the prefix of the option are removed too
+// Characters in the letters of the option are never removed or folded
+func NormalizeWithOption(code string, opt *Option) (string, error) {
+	if opt == nil {
+		return "", ErrRecoveryCodeOptionIsNil
+	}
+	if opt.passphrase {
+		return opt.normalizePassphrase(code), nil
+	}
+
+	normalized := opt.normalizeCode(code)
+	if opt.prefix == "" {
+		return normalized, nil
+	}
+
+	// the prefix is removed only when the code is longer than expected,
+	// so the code that begins with the same characters as the prefix is kept
+	prefix := []rune(opt.normalizeCode(opt.prefix))
+	rs := []rune(normalized)
+	if uint(len(rs)) == opt.codeLength()+uint(len(prefix)) && strings.EqualFold(string(rs[:len(prefix)]), string(prefix)) {
+		return string(rs[len(prefix):]), nil
+	}
+
+	return normalized, nil
+}
+
+func (opt *Option) normalizeCode(code string) string {
+	var b strings.Builder
+	for _, r := range code {
+		if strings.ContainsRune(opt.letters, r) {
+			b.WriteRune(r)
+			continue
+		}
+		if unicode.IsSpace(r) || opt.isSeparator(r) {
+			continue
+		}
+		c, _ := opt.canonical(r)
+		b.WriteRune(c)
+	}
+
+	return b.String()
+}
+
+// normalizePassphrase splits the passphrase into lower case words and joins them with the separator
+// A word that has a hyphen like `yo-yo` is split too, which is consistent between generation and verification
+func (opt *Option) normalizePassphrase(code string) string {
+	words := strings.FieldsFunc(strings.ToLower(code), func(r rune) bool {
+		return unicode.IsSpace(r) || opt.isSeparator(r) || strings.ContainsRune(opt.wordSeparator, r)
+	})
+	if opt.prefix != "" && len(words) > 0 && words[0] == strings.ToLower(opt.prefix) {
+		words = words[1:]
+	}
+
+	return strings.Join(words, opt.wordSeparator)
+}
+
+// isSeparator reports whether any Format or the grouping of the option inserts the character as a separator
+func (opt *Option) isSeparator(r rune) bool {
+	if opt.groupSeparator != "" && strings.ContainsRune(opt.groupSeparator, r) {
+		return true
+	}
+	for f := FormatNormal; f.enabled(); f++ {
+		if sep := f.separator(); sep != "" && strings.ContainsRune(sep, r) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// canonical returns the character of letters that the typed character represents
+func (opt *Option) canonical(r rune) (rune, bool) {
+	candidates := []rune{r}
+	if opt.caseInsensitive {
+		candidates = append(candidates, unicode.ToUpper(r), unicode.ToLower(r))
+	}
+
+	for _, c := range candidates {
+		if strings.ContainsRune(opt.letters, c) {
+			return c, true
+		}
+	}
+	for _, c := range candidates {
+		if s, ok := opt.substitutions[c]; ok && strings.ContainsRune(opt.letters, s) {
+			return s, true
+		}
+	}
+
+	return r, false
+}
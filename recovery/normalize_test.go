@@ -0,0 +1,113 @@
+package recovery_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/butterv/one-time-password/recovery"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "abcd1234", want: "abcd1234"},
+		{in: "abcd-1234", want: "abcd1234"},
+		{in: "abcd 1234", want: "abcd1234"},
+		{in: "  abcd\t-1234\n", want: "abcd1234"},
+		{in: "ABCD1234", want: "ABCD1234"},
+	}
+
+	for _, tt := range tests {
+		got, err := recovery.Normalize(tt.in)
+		if err != nil {
+			t.Fatalf("Normalize(%q)=_, %#v; want nil", tt.in, err)
+		}
+		if got != tt.want {
+			t.Errorf("Normalize(%q)=%s, _; want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeWithOption_CaseInsensitive(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "ABCD-1234", want: "ABCD1234"},
+		{in: "abcd 1234", want: "ABCD1234"},
+		{in: "aBcD1234", want: "ABCD1234"},
+	}
+
+	o := recovery.NewOption()
+	_ = o.SetLetters("ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789")
+	_ = o.SetCaseInsensitive(true)
+	for _, tt := range tests {
+		got, err := recovery.NormalizeWithOption(tt.in, o)
+		if err != nil {
+			t.Fatalf("NormalizeWithOption(%q, %v)=_, %#v; want nil", tt.in, o, err)
+		}
+		if got != tt.want {
+			t.Errorf("NormalizeWithOption(%q, %v)=%s, _; want %s", tt.in, o, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeWithOption_SeparatorInLetters(t *testing.T) {
+	want := "ab-cd"
+
+	code := "ab-cd"
+	o := recovery.NewOption()
+	_ = o.SetLetters("abcd-")
+	got, err := recovery.NormalizeWithOption(code, o)
+	if err != nil {
+		t.Fatalf("NormalizeWithOption(%s, %v)=_, %#v; want nil", code, o, err)
+	}
+	if got != want {
+		t.Errorf("NormalizeWithOption(%s, %v)=%s, _; want %s", code, o, got, want)
+	}
+}
+
+func TestNormalizeWithOption_ErrOptionIsNil(t *testing.T) {
+	wantErr := recovery.ErrRecoveryCodeOptionIsNil
+
+	_, err := recovery.NormalizeWithOption("abcd1234", nil)
+	if err != wantErr {
+		t.Errorf("NormalizeWithOption(abcd1234, nil)=_, %#v; want %v", err, wantErr)
+	}
+}
+
+func TestOption_SetCaseInsensitive_LettersHasBothCases(t *testing.T) {
+	wantErr := errors.New("letters has both cases of a letter. please pass letters in a single case")
+
+	o := recovery.NewOption()
+	err := o.SetCaseInsensitive(true)
+	if err == nil {
+		t.Fatalf("SetCaseInsensitive(true)=nil; want %v, receiver %#v", wantErr, o)
+	}
+	if err.Error() != wantErr.Error() {
+		t.Errorf("SetCaseInsensitive(true)=%#v; want %v, receiver %#v", err, wantErr, o)
+	}
+	if o.CaseInsensitive() {
+		t.Errorf("caseInsensitive: got true, want false, receiver %#v", o)
+	}
+}
+
+func TestManager_Redeem_Formatted(t *testing.T) {
+	ctx := context.Background()
+	o := recovery.NewOption()
+	_ = o.SetLetters("abcdefghijklmnopqrstuvwxyz")
+	_ = o.SetFormat(recovery.FormatSplitByHyphen)
+	_ = o.SetCaseInsensitive(true)
+	m, _ := recovery.NewManager(recovery.NewMemoryStore(), o, fastHashOption(t, recovery.KDFPBKDF2))
+
+	codes, _ := m.Issue(ctx, "user")
+	typed, _ := recovery.NormalizeWithOption(codes[0], o)
+	typed = " " + strings.ToUpper(typed[:3]) + " " + typed[3:] + " "
+	if err := m.Redeem(ctx, "user", typed); err != nil {
+		t.Errorf("Redeem(user, %q)=%#v; want nil, issued %s", typed, err, codes[0])
+	}
+}
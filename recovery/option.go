@@ -3,14 +3,25 @@ package recovery
 import (
 	"errors"
 	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
 )
 
 const (
-	defaultLetters = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
-	defaultLength  = 8
-	defaultCount   = 8
+	defaultLetters       = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+	defaultLength        = 8
+	defaultCount         = 8
+	defaultWordSeparator = "-"
+	// maxEntropy bounds the target entropy, so the derived length stays reasonable
+	// 256 bits is beyond the security of any hash of recovery codes
+	maxEntropy = 256
 )
 
+var prefixPattern = regexp.MustCompile(`^[A-Za-z0-9]+$`)
+
 // ErrRecoveryCodeOptionIsNil is an error when the recovery code option is nil
 var ErrRecoveryCodeOptionIsNil = errors.New("recovery code option is nil")
 
@@ -33,8 +44,22 @@ func (f Format) enabled() bool {
 	return f >= FormatNormal && f <= FormatSplitBySpace
 }
 
+// separator returns the separator that the format inserts
+func (f Format) separator() string {
+	switch f {
+	case FormatSplitByHyphen:
+		return "-"
+	case FormatSplitBySpace:
+		return " "
+	}
+
+	return ""
+}
+
 func (f Format) apply(code string) string {
-	length := len(code)
+	// the code is split by characters, so a code of multi-byte letters remains valid UTF-8
+	rs := []rune(code)
+	length := len(rs)
 	if length == 1 {
 		return code
 	}
@@ -42,9 +67,9 @@ func (f Format) apply(code string) string {
 	center := length / 2
 	var bh, ah string
 	if length%2 == 0 {
-		bh, ah = code[:center], code[center:]
+		bh, ah = string(rs[:center]), string(rs[center:])
 	} else {
-		bh, ah = code[:center+1], code[center+1:]
+		bh, ah = string(rs[:center+1]), string(rs[center+1:])
 	}
 
 	format := "%s%s"
@@ -66,8 +91,39 @@ type Option struct {
 	length uint
 	// count is the count of random string
 	count uint
+	// targetEntropy is the entropy in bits that length is derived from
+	// The default value is 0, which means length is set directly
+	targetEntropy float64
+	// minEntropy is the minimum entropy in bits of a recovery code
+	// The default value is 0, which means any entropy is accepted
+	minEntropy float64
 	// format is the format of recovery code
 	format Format
+	// caseInsensitive accepts the code typed in the other case when verifies
+	// The default value is false
+	caseInsensitive bool
+	// substitutions maps misread characters to the characters of letters when verifies
+	substitutions map[rune]rune
+	// groupSize is the count of characters in a group
+	// The default value is 0, which means the code is formatted by format instead of groups
+	groupSize uint
+	// groupSeparator is the separator between groups
+	groupSeparator string
+	// prefix is the prefix identifying the issuing system
+	// The default value is empty
+	prefix string
+	// checksum appends a check character computed with the Luhn mod N algorithm
+	// The default value is false
+	checksum bool
+	// passphrase generates passphrases of words instead of random strings
+	// The default value is false
+	passphrase bool
+	// wordCount is the count of words in a passphrase
+	// The default value is 0, which means the count that has entropy equivalent to the random string
+	wordCount uint
+	// wordSeparator is the separator between words in a passphrase
+	// The default value is `-`
+	wordSeparator string
 }
 
 // SetLetters sets letters that used to generate random string
@@ -78,8 +134,40 @@ func (opt *Option) SetLetters(letters string) error {
 	if len(letters) == 0 {
 		return errors.New("letters is empty")
 	}
+	if !utf8.ValidString(letters) {
+		return errors.New("letters isn't valid UTF-8")
+	}
+	if r, ok := duplicate(letters); ok {
+		return fmt.Errorf("letters has a duplicate character %q", r)
+	}
+	if opt.caseInsensitive && hasBothCases(letters) {
+		return errors.New("letters has both cases of a letter. please disable case insensitive")
+	}
+	if opt.targetEntropy > 0 && utf8.RuneCountInString(letters) < 2 {
+		return errors.New("letters has a single character. please pass 2 or more characters to derive length from entropy")
+	}
 
 	opt.letters = letters
+	opt.substitutions = nil
+	opt.deriveLength()
+	return nil
+}
+
+// SetAlphabet sets letters of the predefined alphabet
+// This also sets case insensitivity and misread characters accepted when verifies
+func (opt *Option) SetAlphabet(a Alphabet) error {
+	if opt == nil {
+		return ErrRecoveryCodeOptionIsNil
+	}
+	if !a.enabled() {
+		return fmt.Errorf("invalid alphabet. please pass any of %d to %d", AlphabetAlphanumeric, AlphabetHex)
+	}
+
+	spec := a.spec()
+	opt.letters = spec.letters
+	opt.caseInsensitive = spec.caseInsensitive
+	opt.substitutions = spec.substitutions
+	opt.deriveLength()
 	return nil
 }
 
@@ -93,6 +181,85 @@ func (opt *Option) SetLength(length uint) error {
 	}
 
 	opt.length = length
+	opt.targetEntropy = 0
+	return nil
+}
+
+// SetEntropy sets a target entropy in bits like 80 bits
+// The length is derived from the entropy and the count of letters, and is derived again when letters is changed
+// The entropy must be 256 bits or less
+func (opt *Option) SetEntropy(bits float64) error {
+	if opt == nil {
+		return ErrRecoveryCodeOptionIsNil
+	}
+	if !(bits > 0) || bits > maxEntropy {
+		return fmt.Errorf("invalid entropy. please pass greater than 0 and %d or less", maxEntropy)
+	}
+	if utf8.RuneCountInString(opt.letters) < 2 {
+		return errors.New("letters has a single character. please pass 2 or more characters to derive length from entropy")
+	}
+
+	opt.targetEntropy = bits
+	opt.deriveLength()
+	return nil
+}
+
+// SetMinEntropy sets the minimum entropy in bits of a recovery code
+// Generating recovery codes fails when the entropy of the option is lower than the minimum
+func (opt *Option) SetMinEntropy(bits float64) error {
+	if opt == nil {
+		return ErrRecoveryCodeOptionIsNil
+	}
+	if bits < 0 || math.IsNaN(bits) || math.IsInf(bits, 1) {
+		return errors.New("invalid minEntropy. please pass 0 or greater")
+	}
+
+	opt.minEntropy = bits
+	return nil
+}
+
+// deriveLength sets the shortest length that has the target entropy
+func (opt *Option) deriveLength() {
+	if opt.targetEntropy == 0 {
+		return
+	}
+
+	bitsPerLetter := math.Log2(float64(utf8.RuneCountInString(opt.letters)))
+	opt.length = uint(math.Ceil(opt.targetEntropy / bitsPerLetter))
+}
+
+// validate returns an error when the options set separately conflict with each other
+func (opt *Option) validate() error {
+	if opt.passphrase && opt.checksum {
+		return errors.New("checksum isn't supported for passphrase")
+	}
+	if err := opt.validateGroupSeparator(); err != nil {
+		return err
+	}
+
+	return opt.validateEntropy()
+}
+
+// validateGroupSeparator returns an error when the group separator has a character that normalization reads as letters
+func (opt *Option) validateGroupSeparator() error {
+	if opt.groupSize == 0 {
+		return nil
+	}
+	for _, r := range opt.groupSeparator {
+		if _, ok := opt.canonical(r); ok {
+			return fmt.Errorf("group separator %q has a character %q of letters. please pass separator without letters", opt.groupSeparator, r)
+		}
+	}
+
+	return nil
+}
+
+// validateEntropy returns an error when the entropy of the option is lower than the minimum
+func (opt *Option) validateEntropy() error {
+	if e := opt.Entropy(); e < opt.minEntropy {
+		return fmt.Errorf("entropy %.1f bits is lower than the minimum %.1f bits", e, opt.minEntropy)
+	}
+
 	return nil
 }
 
@@ -122,12 +289,210 @@ func (opt *Option) SetFormat(format Format) error {
 	return nil
 }
 
+// SetCaseInsensitive sets whether the code typed in the other case is accepted when verifies
+// This can be enabled only when letters doesn't have both cases of a letter
+func (opt *Option) SetCaseInsensitive(caseInsensitive bool) error {
+	if opt == nil {
+		return ErrRecoveryCodeOptionIsNil
+	}
+	if caseInsensitive && hasBothCases(opt.letters) {
+		return errors.New("letters has both cases of a letter. please pass letters in a single case")
+	}
+
+	opt.caseInsensitive = caseInsensitive
+	return nil
+}
+
+// SetGrouping sets a count of characters in a group and a separator between groups like `XXXX-XXXX-XXXX-XXXX`
+// This is used instead of format
+// The separator must not have letters, which is checked when generates recovery codes because letters can be changed later
+func (opt *Option) SetGrouping(size uint, separator string) error {
+	if opt == nil {
+		return ErrRecoveryCodeOptionIsNil
+	}
+	if size == 0 {
+		return errors.New("invalid group size. please pass greater than 0")
+	}
+	if separator == "" {
+		return errors.New("invalid group separator. please pass non-empty separator")
+	}
+
+	opt.groupSize = size
+	opt.groupSeparator = separator
+	return nil
+}
+
+// SetPrefix sets a prefix identifying the issuing system like `ACME`
+// The prefix is prepended with a separator, and the code typed with or without the prefix is accepted when verifies
+func (opt *Option) SetPrefix(prefix string) error {
+	if opt == nil {
+		return ErrRecoveryCodeOptionIsNil
+	}
+	if !prefixPattern.MatchString(prefix) {
+		return errors.New("invalid prefix. please pass alphanumeric characters")
+	}
+
+	opt.prefix = prefix
+	return nil
+}
+
+// separator returns the separator between the prefix and the code
+func (opt *Option) separator() string {
+	switch {
+	case opt.passphrase:
+		return opt.wordSeparator
+	case opt.groupSize > 0:
+		return opt.groupSeparator
+	case opt.format.separator() != "":
+		return opt.format.separator()
+	}
+
+	return "-"
+}
+
+// apply formats the code by groups or format, and prepends the prefix
+func (opt *Option) apply(code string) string {
+	if !opt.passphrase {
+		if opt.groupSize > 0 {
+			code = group(code, opt.groupSize, opt.groupSeparator)
+		} else {
+			code = opt.format.apply(code)
+		}
+	}
+	if opt.prefix != "" {
+		code = opt.prefix + opt.separator() + code
+	}
+
+	return code
+}
+
+// codeLength returns the count of characters of the code without format
+func (opt *Option) codeLength() uint {
+	if opt.checksum {
+		return opt.length + 1
+	}
+
+	return opt.length
+}
+
+// group splits the code into groups of the size from the left and joins them with the separator
+func group(code string, size uint, separator string) string {
+	rs := []rune(code)
+	groups := make([]string, 0, (uint(len(rs))+size-1)/size)
+	for i := uint(0); i < uint(len(rs)); i += size {
+		end := i + size
+		if end > uint(len(rs)) {
+			end = uint(len(rs))
+		}
+		groups = append(groups, string(rs[i:end]))
+	}
+
+	return strings.Join(groups, separator)
+}
+
+// SetChecksum sets whether a check character is appended to the random string
+// The check character lets ValidateFormatWithOption detect a mistyped character and most of swapped adjacent characters
+// This isn't supported for passphrases
+func (opt *Option) SetChecksum(checksum bool) error {
+	if opt == nil {
+		return ErrRecoveryCodeOptionIsNil
+	}
+
+	opt.checksum = checksum
+	return nil
+}
+
+// SetPassphrase sets whether passphrases of words are generated instead of random strings
+// The words are picked from the EFF's short wordlist
+func (opt *Option) SetPassphrase(passphrase bool) error {
+	if opt == nil {
+		return ErrRecoveryCodeOptionIsNil
+	}
+
+	opt.passphrase = passphrase
+	return nil
+}
+
+// SetWordCount sets a count of words in a passphrase
+func (opt *Option) SetWordCount(count uint) error {
+	if opt == nil {
+		return ErrRecoveryCodeOptionIsNil
+	}
+	if count == 0 {
+		return errors.New("invalid wordCount. please pass greater than 0")
+	}
+
+	opt.wordCount = count
+	return nil
+}
+
+// SetWordSeparator sets a separator between words in a passphrase
+func (opt *Option) SetWordSeparator(separator string) error {
+	if opt == nil {
+		return ErrRecoveryCodeOptionIsNil
+	}
+	if separator == "" || strings.IndexFunc(separator, unicode.IsLetter) >= 0 {
+		return errors.New("invalid wordSeparator. please pass non-empty separator without letters")
+	}
+
+	opt.wordSeparator = separator
+	return nil
+}
+
+// words returns the count of words in a passphrase
+// When the count isn't set, this returns the count that has entropy equivalent to the random string
+func (opt *Option) words() uint {
+	if opt.wordCount > 0 {
+		return opt.wordCount
+	}
+
+	bits := float64(opt.length) * math.Log2(float64(utf8.RuneCountInString(opt.letters)))
+	return uint(math.Ceil(bits / math.Log2(float64(len(effShortWordList)))))
+}
+
+// Entropy returns the entropy of a recovery code in bits
+// A check character doesn't add entropy, so this isn't counted
+func (opt *Option) Entropy() float64 {
+	if opt == nil {
+		return 0
+	}
+	if opt.passphrase {
+		return float64(opt.words()) * math.Log2(float64(len(effShortWordList)))
+	}
+
+	return float64(opt.length) * math.Log2(float64(utf8.RuneCountInString(opt.letters)))
+}
+
+// duplicate returns the first character that appears twice in letters
+func duplicate(letters string) (rune, bool) {
+	seen := make(map[rune]struct{}, len(letters))
+	for _, r := range letters {
+		if _, ok := seen[r]; ok {
+			return r, true
+		}
+		seen[r] = struct{}{}
+	}
+
+	return 0, false
+}
+
+func hasBothCases(letters string) bool {
+	for _, r := range letters {
+		if u := unicode.ToUpper(r); u != r && strings.ContainsRune(letters, u) {
+			return true
+		}
+	}
+
+	return false
+}
+
 // NewOption generates an option for generating recovery code
 func NewOption() *Option {
 	return &Option{
-		letters: defaultLetters,
-		length:  defaultLength,
-		count:   defaultCount,
-		format:  FormatNormal,
+		letters:       defaultLetters,
+		length:        defaultLength,
+		count:         defaultCount,
+		format:        FormatNormal,
+		wordSeparator: defaultWordSeparator,
 	}
 }
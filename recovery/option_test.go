@@ -215,6 +215,24 @@ func TestFormat_Apply(t *testing.T) {
 	}
 }
 
+func TestFormat_Apply_MultiByte(t *testing.T) {
+	tests := []struct {
+		in   recovery.Format
+		code string
+		want string
+	}{
+		{in: 1, code: "ζθβδδ", want: "ζθβ-δδ"},
+		{in: 2, code: "ζθβδδα", want: "ζθβ δδα"},
+	}
+
+	for _, tt := range tests {
+		got := recovery.ExportFormatApply(tt.in, tt.code)
+		if got != tt.want {
+			t.Errorf("ExportFormatApply(%d, %s)=%v; want %v", tt.in, tt.code, got, tt.want)
+		}
+	}
+}
+
 func TestNewOption(t *testing.T) {
 	want := recovery.DefaultOption()
 
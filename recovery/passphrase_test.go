@@ -0,0 +1,161 @@
+package recovery_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/butterv/one-time-password/recovery"
+)
+
+func TestOption_SetWordCount_InvalidWordCount(t *testing.T) {
+	wantErr := errors.New("invalid wordCount. please pass greater than 0")
+
+	count := uint(0)
+	o := recovery.NewOption()
+	err := o.SetWordCount(count)
+	if err == nil {
+		t.Fatalf("SetWordCount(%d)=nil; want %v, receiver %#v", count, wantErr, o)
+	}
+	if err.Error() != wantErr.Error() {
+		t.Errorf("SetWordCount(%d)=%#v; want %v, receiver %#v", count, err, wantErr, o)
+	}
+}
+
+func TestOption_SetWordSeparator(t *testing.T) {
+	want := "."
+
+	separator := "."
+	o := recovery.NewOption()
+	err := o.SetWordSeparator(separator)
+	if err != nil {
+		t.Fatalf("SetWordSeparator(%s)=%#v; want nil, receiver %#v", separator, err, o)
+	}
+	if got := o.WordSeparator(); got != want {
+		t.Errorf("wordSeparator: got %s, want %s, receiver %#v", got, want, o)
+	}
+}
+
+func TestOption_SetWordSeparator_InvalidWordSeparator(t *testing.T) {
+	wantErr := errors.New("invalid wordSeparator. please pass non-empty separator without letters")
+
+	for _, separator := range []string{"", "x"} {
+		o := recovery.NewOption()
+		err := o.SetWordSeparator(separator)
+		if err == nil {
+			t.Fatalf("SetWordSeparator(%q)=nil; want %v, receiver %#v", separator, wantErr, o)
+		}
+		if err.Error() != wantErr.Error() {
+			t.Errorf("SetWordSeparator(%q)=%#v; want %v, receiver %#v", separator, err, wantErr, o)
+		}
+	}
+}
+
+func TestOption_Words(t *testing.T) {
+	tests := []struct {
+		letters   string
+		length    uint
+		wordCount uint
+		want      uint
+	}{
+		// 8 * log2(62) = 47.6 bits, 47.6 / log2(1296) = 4.6 words
+		{letters: "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789", length: 8, want: 5},
+		// 16 * log2(32) = 80 bits, 80 / log2(1296) = 7.7 words
+		{letters: "0123456789ABCDEFGHJKMNPQRSTVWXYZ", length: 16, want: 8},
+		{letters: "0123456789", length: 8, wordCount: 3, want: 3},
+	}
+
+	for _, tt := range tests {
+		o := recovery.NewOption()
+		_ = o.SetLetters(tt.letters)
+		_ = o.SetLength(tt.length)
+		if tt.wordCount > 0 {
+			_ = o.SetWordCount(tt.wordCount)
+		}
+		if got := recovery.ExportOptionWords(o); got != tt.want {
+			t.Errorf("ExportOptionWords(%#v)=%d; want %d", o, got, tt.want)
+		}
+	}
+}
+
+func TestWordList(t *testing.T) {
+	if got := len(recovery.ExportWordList); got != 1296 {
+		t.Errorf("len(ExportWordList)=%d; want 1296", got)
+	}
+
+	seen := map[string]bool{}
+	for _, w := range recovery.ExportWordList {
+		if seen[w] {
+			t.Errorf("word %s is duplicated", w)
+		}
+		seen[w] = true
+	}
+}
+
+func TestGenerateRecoveryCodesWithOption_Passphrase(t *testing.T) {
+	o := recovery.NewOption()
+	_ = o.SetPassphrase(true)
+	_ = o.SetWordCount(4)
+	_ = o.SetWordSeparator(" ")
+
+	codes, err := recovery.GenerateRecoveryCodesWithOption(o)
+	if err != nil {
+		t.Fatalf("GenerateRecoveryCodesWithOption(%v)=_, %#v; want nil", o, err)
+	}
+	if len(codes) != 8 {
+		t.Errorf("len(codes)=%d; want 8", len(codes))
+	}
+
+	words := map[string]bool{}
+	for _, w := range recovery.ExportWordList {
+		words[w] = true
+	}
+	for _, code := range codes {
+		ws := strings.Split(code, " ")
+		if len(ws) != 4 {
+			t.Errorf("code %q has %d words; want 4", code, len(ws))
+		}
+		for _, w := range ws {
+			if !words[w] {
+				t.Errorf("code %q has %q; want a word of the wordlist", code, w)
+			}
+		}
+	}
+}
+
+func TestNormalizeWithOption_Passphrase(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "abiding-blimp-yo-yo", want: "abiding-blimp-yo-yo"},
+		{in: "  Abiding  BLIMP yo-yo ", want: "abiding-blimp-yo-yo"},
+		{in: "abiding blimp yo yo", want: "abiding-blimp-yo-yo"},
+	}
+
+	o := recovery.NewOption()
+	_ = o.SetPassphrase(true)
+	for _, tt := range tests {
+		got, err := recovery.NormalizeWithOption(tt.in, o)
+		if err != nil {
+			t.Fatalf("NormalizeWithOption(%q, %v)=_, %#v; want nil", tt.in, o, err)
+		}
+		if got != tt.want {
+			t.Errorf("NormalizeWithOption(%q, %v)=%s, _; want %s", tt.in, o, got, tt.want)
+		}
+	}
+}
+
+func TestManager_Redeem_Passphrase(t *testing.T) {
+	ctx := context.Background()
+	o := recovery.NewOption()
+	_ = o.SetPassphrase(true)
+	m, _ := recovery.NewManager(recovery.NewMemoryStore(), o, fastHashOption(t, recovery.KDFPBKDF2))
+
+	codes, _ := m.Issue(ctx, "user")
+	typed := strings.ToUpper(strings.Replace(codes[0], "-", " ", -1))
+	if err := m.Redeem(ctx, "user", typed); err != nil {
+		t.Errorf("Redeem(user, %q)=%#v; want nil, issued %s", typed, err, codes[0])
+	}
+}
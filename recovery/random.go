@@ -0,0 +1,56 @@
+package recovery
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"math"
+)
+
+// randBufferSize is the size of the buffer of random bytes
+const randBufferSize = 256
+
+// randSource picks uniform random integers from a buffered reader of random bytes
+// This uses rejection sampling, so the result isn't biased toward small integers
+type randSource struct {
+	r *bufio.Reader
+}
+
+func newRandSource(r io.Reader) *randSource {
+	return &randSource{
+		r: bufio.NewReaderSize(r, randBufferSize),
+	}
+}
+
+// intn returns a uniform random integer in [0, n)
+// A byte is consumed for each attempt when n is 256 or less, and 4 bytes otherwise
+func (s *randSource) intn(n int) (int, error) {
+	if n <= 0 || uint64(n) > math.MaxUint32 {
+		panic("invalid argument to intn")
+	}
+
+	if n <= 256 {
+		// the largest multiple of n that is 256 or less
+		limit := 256 - 256%n
+		for {
+			b, err := s.r.ReadByte()
+			if err != nil {
+				return 0, err
+			}
+			if int(b) < limit {
+				return int(b) % n, nil
+			}
+		}
+	}
+
+	limit := (1 << 32) - (1<<32)%uint64(n)
+	var b [4]byte
+	for {
+		if _, err := io.ReadFull(s.r, b[:]); err != nil {
+			return 0, err
+		}
+		if x := uint64(binary.BigEndian.Uint32(b[:])); x < limit {
+			return int(x % uint64(n)), nil
+		}
+	}
+}
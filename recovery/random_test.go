@@ -0,0 +1,113 @@
+package recovery_test
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/butterv/one-time-password/recovery"
+)
+
+func TestRandIntn(t *testing.T) {
+	tests := []struct {
+		name string
+		in   []byte
+		n    int
+		want int
+	}{
+		{name: "byte", in: []byte{150}, n: 100, want: 50},
+		{name: "byte rejected", in: []byte{250, 200, 199}, n: 100, want: 99},
+		{name: "byte of 256", in: []byte{255}, n: 256, want: 255},
+		{name: "uint32", in: []byte{0, 0, 5, 20}, n: 1296, want: 1300 % 1296},
+		{name: "uint32 rejected", in: []byte{0xff, 0xff, 0xff, 0xff, 0, 0, 0, 7}, n: 1296, want: 7},
+	}
+
+	for _, tt := range tests {
+		got, err := recovery.ExportRandIntn(bytes.NewReader(tt.in), tt.n)
+		if err != nil {
+			t.Fatalf("%s: intn(%d)=_, %#v; want nil", tt.name, tt.n, err)
+		}
+		if got != tt.want {
+			t.Errorf("%s: intn(%d)=%d; want %d", tt.name, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestRandIntn_ShortRead(t *testing.T) {
+	_, err := recovery.ExportRandIntn(bytes.NewReader([]byte{250}), 100)
+	if err != io.EOF {
+		t.Errorf("intn(100)=_, %#v; want %v", err, io.EOF)
+	}
+}
+
+func TestOption_SetLetters_Duplicate(t *testing.T) {
+	for _, letters := range []string{"abca", "αβγα", "\xff\xfe"} {
+		o := recovery.NewOption()
+		if err := o.SetLetters(letters); err == nil {
+			t.Errorf("SetLetters(%q)=nil; want error", letters)
+		}
+	}
+}
+
+func TestGenerateRecoveryCodesWithOption_MultiByteLetters(t *testing.T) {
+	letters := "αβγδεζηθ"
+	o := recovery.NewOption()
+	_ = o.SetLetters(letters)
+	_ = o.SetLength(10)
+	_ = o.SetChecksum(true)
+
+	codes, err := recovery.GenerateRecoveryCodesWithOption(o)
+	if err != nil {
+		t.Fatalf("GenerateRecoveryCodesWithOption(%v)=_, %#v; want nil", o, err)
+	}
+	for _, code := range codes {
+		if !utf8.ValidString(code) {
+			t.Errorf("code %q isn't valid UTF-8", code)
+		}
+		if n := utf8.RuneCountInString(code); n != 11 {
+			t.Errorf("code %s has %d characters; want 11", code, n)
+		}
+		for _, r := range code {
+			if !strings.ContainsRune(letters, r) {
+				t.Errorf("code %s has %q not in letters", code, r)
+			}
+		}
+		if err := recovery.ValidateFormatWithOption(code, o); err != nil {
+			t.Errorf("ValidateFormatWithOption(%s, %v)=%#v; want nil", code, o, err)
+		}
+	}
+}
+
+func TestOption_Entropy(t *testing.T) {
+	hex := recovery.NewOption()
+	_ = hex.SetAlphabet(recovery.AlphabetHex)
+	_ = hex.SetLength(10)
+
+	greek := recovery.NewOption()
+	_ = greek.SetLetters("αβγδ")
+	_ = greek.SetChecksum(true)
+
+	passphrase := recovery.NewOption()
+	_ = passphrase.SetPassphrase(true)
+	_ = passphrase.SetWordCount(4)
+
+	tests := []struct {
+		name string
+		opt  *recovery.Option
+		want float64
+	}{
+		{name: "hex", opt: hex, want: 40},
+		{name: "multi-byte letters with checksum", opt: greek, want: 16},
+		{name: "passphrase", opt: passphrase, want: 4 * math.Log2(1296)},
+		{name: "nil", opt: nil, want: 0},
+	}
+
+	for _, tt := range tests {
+		if got := tt.opt.Entropy(); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("%s: Entropy()=%f; want %f", tt.name, got, tt.want)
+		}
+	}
+}
@@ -2,7 +2,7 @@ package recovery
 
 import (
 	crand "crypto/rand"
-	"math/big"
+	"strings"
 )
 
 // GenerateRecoveryCodes generates recovery codes
@@ -16,30 +16,60 @@ func GenerateRecoveryCodesWithOption(opt *Option) ([]string, error) {
 	if opt == nil {
 		return nil, ErrRecoveryCodeOptionIsNil
 	}
+	if err := opt.validate(); err != nil {
+		return nil, err
+	}
 
+	src := newRandSource(crand.Reader)
+	letters := []rune(opt.letters)
 	var codes []string
 	for i := uint(0); i < opt.count; i++ {
-		code, err := cryptoRandString(opt.letters, opt.length)
+		if opt.passphrase {
+			code, err := cryptoRandPassphrase(src, effShortWordList, opt.words(), opt.wordSeparator)
+			if err != nil {
+				return nil, err
+			}
+			codes = append(codes, opt.apply(code))
+			continue
+		}
+
+		code, err := cryptoRandString(src, letters, opt.length)
 		if err != nil {
 			return nil, err
 		}
-		codes = append(codes, opt.format.apply(code))
+		if opt.checksum {
+			code += string(luhnModNCheckCharacter(letters, code))
+		}
+		codes = append(codes, opt.apply(code))
 	}
 
 	return codes, nil
 }
 
-func cryptoRandString(letters string, length uint) (string, error) {
-	b := make([]byte, length)
-	n := big.NewInt(int64(len(letters)))
-	for i := range b {
-		x, err := crand.Int(crand.Reader, n)
+func cryptoRandString(src *randSource, letters []rune, length uint) (string, error) {
+	rs := make([]rune, length)
+	for i := range rs {
+		x, err := src.intn(len(letters))
+		if err != nil {
+			return "", err
+		}
+
+		rs[i] = letters[x]
+	}
+
+	return string(rs), nil
+}
+
+func cryptoRandPassphrase(src *randSource, words []string, count uint, separator string) (string, error) {
+	ws := make([]string, count)
+	for i := range ws {
+		x, err := src.intn(len(words))
 		if err != nil {
 			return "", err
 		}
 
-		b[i] = letters[int(x.Int64())]
+		ws[i] = words[x]
 	}
 
-	return string(b), nil
+	return strings.Join(ws, separator), nil
 }
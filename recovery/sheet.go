@@ -0,0 +1,293 @@
+package recovery
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+)
+
+const (
+	sheetTitle          = "Recovery codes"
+	sheetDateLayout     = "2006-01-02"
+	defaultInstructions = "Each code can be used only once to sign in when you lose access to your authenticator app. " +
+		"Keep this sheet in a safe place and generate new codes when you run out of them."
+)
+
+// ErrSheetIsNil is an error when the sheet is nil
+var ErrSheetIsNil = errors.New("sheet is nil")
+
+// Sheet is a printable sheet of recovery codes
+// The sheet is rendered into plain text, Markdown, HTML and PDF without any external service
+type Sheet struct {
+	// issuer is the name of the service that issues the codes
+	issuer string
+	// accountName is the name of the account that the codes belong to
+	accountName string
+	// codes is the recovery codes as they are generated
+	codes []string
+	// generatedAt is the time when the codes are generated
+	generatedAt time.Time
+	// instructions is the usage instructions printed under the codes
+	instructions string
+}
+
+// NewSheet generates a sheet by passing issuer, account name, the codes returned by GenerateRecoveryCodesWithOption and the generation time
+func NewSheet(issuer, accountName string, codes []string, generatedAt time.Time) (*Sheet, error) {
+	if issuer == "" {
+		return nil, errors.New("issuer is empty")
+	}
+	if accountName == "" {
+		return nil, errors.New("accountName is empty")
+	}
+	if len(codes) == 0 {
+		return nil, errors.New("codes is empty")
+	}
+
+	cs := make([]string, len(codes))
+	copy(cs, codes)
+
+	return &Sheet{
+		issuer:       issuer,
+		accountName:  accountName,
+		codes:        cs,
+		generatedAt:  generatedAt,
+		instructions: defaultInstructions,
+	}, nil
+}
+
+// SetInstructions sets the usage instructions printed under the codes
+func (s *Sheet) SetInstructions(instructions string) error {
+	if s == nil {
+		return ErrSheetIsNil
+	}
+	if strings.TrimSpace(instructions) == "" {
+		return errors.New("instructions is empty")
+	}
+
+	s.instructions = instructions
+	return nil
+}
+
+// Text renders the sheet into plain text
+func (s *Sheet) Text() string {
+	if s == nil {
+		return ""
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n\n", sheetTitle)
+	for _, l := range s.header() {
+		fmt.Fprintf(&b, "%s: %s\n", l[0], l[1])
+	}
+	b.WriteString("\n")
+	for i, code := range s.codes {
+		fmt.Fprintf(&b, "%s %s\n", s.number(i), code)
+	}
+	fmt.Fprintf(&b, "\n%s\n", s.instructions)
+
+	return b.String()
+}
+
+// Markdown renders the sheet into Markdown
+// The codes are rendered as inline code, so they are copied as they are
+func (s *Sheet) Markdown() string {
+	if s == nil {
+		return ""
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "## %s\n\n", sheetTitle)
+	for _, l := range s.header() {
+		fmt.Fprintf(&b, "- **%s:** %s\n", l[0], escapeMarkdown(l[1]))
+	}
+	b.WriteString("\n")
+	for i, code := range s.codes {
+		fmt.Fprintf(&b, "%d. `%s`\n", i+1, code)
+	}
+	fmt.Fprintf(&b, "\n%s\n", escapeMarkdown(s.instructions))
+
+	return b.String()
+}
+
+// HTML renders the sheet into a HTML snippet
+// Every value is escaped, so the snippet can be embedded in a page
+func (s *Sheet) HTML() string {
+	if s == nil {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("<section class=\"recovery-codes\">\n")
+	fmt.Fprintf(&b, "<h2>%s</h2>\n<dl>\n", sheetTitle)
+	for _, l := range s.header() {
+		fmt.Fprintf(&b, "<dt>%s</dt><dd>%s</dd>\n", l[0], html.EscapeString(l[1]))
+	}
+	b.WriteString("</dl>\n<ol>\n")
+	for _, code := range s.codes {
+		fmt.Fprintf(&b, "<li><code>%s</code></li>\n", html.EscapeString(code))
+	}
+	fmt.Fprintf(&b, "</ol>\n<p>%s</p>\n</section>\n", html.EscapeString(s.instructions))
+
+	return b.String()
+}
+
+// header returns the labels and values printed above the codes
+func (s *Sheet) header() [][2]string {
+	return [][2]string{
+		{"Issuer", s.issuer},
+		{"Account", s.accountName},
+		{"Generated", s.generatedAt.Format(sheetDateLayout)},
+	}
+}
+
+// number returns the right-aligned number of the code like ` 1.`
+func (s *Sheet) number(i int) string {
+	width := len(fmt.Sprint(len(s.codes)))
+	return fmt.Sprintf("%*d.", width, i+1)
+}
+
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", "*", `\*`, "_", `\_`, "[", `\[`, "]", `\]`, "<", `\<`, ">", `\>`, "#", `\#`,
+)
+
+func escapeMarkdown(s string) string {
+	return markdownEscaper.Replace(s)
+}
+
+// A4 page in PDF points
+const (
+	pdfPageWidth   = 595
+	pdfPageHeight  = 842
+	pdfMargin      = 72
+	pdfLineHeight  = 18
+	pdfFontSize    = 12
+	pdfTitleSize   = 20
+	pdfColumnWidth = (pdfPageWidth - pdfMargin*2) / 2
+	// pdfWrapWidth is the count of characters in a line of the instructions
+	pdfWrapWidth = 80
+)
+
+// PDF renders the sheet into a one-page PDF document
+// This uses the standard fonts of PDF, so this returns an error when the sheet has characters out of Latin-1
+func (s *Sheet) PDF() ([]byte, error) {
+	if s == nil {
+		return nil, ErrSheetIsNil
+	}
+
+	instructions := wrap(s.instructions, pdfWrapWidth)
+	rows := (len(s.codes) + 1) / 2
+	// the rows of codes fit between the title with the header and the instructions with blank lines between them
+	maxRows := (pdfPageHeight-pdfMargin*2-pdfTitleSize)/pdfLineHeight - (len(s.header()) + 2 + len(instructions))
+	if maxRows <= 0 {
+		return nil, errors.New("the sheet doesn't fit on a page. please pass shorter instructions")
+	}
+	if rows > maxRows {
+		return nil, fmt.Errorf("the sheet doesn't fit on a page. please pass %d or less codes", maxRows*2)
+	}
+
+	var c bytes.Buffer
+	var err error
+	y := pdfPageHeight - pdfMargin - pdfTitleSize
+	text := func(font string, size, x, y int, s string) {
+		escaped, e := escapePDF(s)
+		if e != nil {
+			if err == nil {
+				err = e
+			}
+			return
+		}
+		fmt.Fprintf(&c, "BT /%s %d Tf %d %d Td (%s) Tj ET\n", font, size, x, y, escaped)
+	}
+
+	text("F2", pdfTitleSize, pdfMargin, y, sheetTitle)
+	y -= pdfLineHeight
+	for _, l := range s.header() {
+		y -= pdfLineHeight
+		text("F1", pdfFontSize, pdfMargin, y, fmt.Sprintf("%s: %s", l[0], l[1]))
+	}
+	y -= pdfLineHeight
+	// the codes are listed from top to bottom, then from left to right
+	for i, code := range s.codes {
+		x := pdfMargin + (i/rows)*pdfColumnWidth
+		text("F3", pdfFontSize, x, y-(i%rows+1)*pdfLineHeight, fmt.Sprintf("%s %s", s.number(i), code))
+	}
+	y -= (rows + 1) * pdfLineHeight
+	for _, l := range instructions {
+		y -= pdfLineHeight
+		text("F1", pdfFontSize, pdfMargin, y, l)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 5 0 R /F2 6 0 R /F3 7 0 R >> >> /Contents 4 0 R >>", pdfPageWidth, pdfPageHeight),
+		fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", c.Len(), c.String()),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>",
+	}
+
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, o := range objects {
+		offsets[i] = b.Len()
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", i+1, o)
+	}
+	xref := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&b, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	return b.Bytes(), nil
+}
+
+// escapePDF escapes a string of PDF
+// The standard fonts can't print control characters and characters out of Latin-1, so this returns an error for them
+func escapePDF(s string) (string, error) {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < 0x20 || (r >= 0x7f && r < 0xa0) || r > 0xff:
+			return "", fmt.Errorf("character %q of %q can't be printed in PDF. please use characters of Latin-1", r, s)
+		case r >= 0x80:
+			fmt.Fprintf(&b, "\\%03o", r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+
+	return b.String(), nil
+}
+
+// wrap splits the text into lines of the width at spaces
+func wrap(text string, width int) []string {
+	var lines []string
+	var line string
+	for _, w := range strings.Fields(text) {
+		if line != "" && len([]rune(line))+1+len([]rune(w)) > width {
+			lines = append(lines, line)
+			line = ""
+		}
+		if line != "" {
+			line += " "
+		}
+		line += w
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+
+	return lines
+}
@@ -0,0 +1,177 @@
+package recovery_test
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/butterv/one-time-password/recovery"
+)
+
+var sheetCodes = []string{"abcd-1234", "efgh-5678", "ijkl-9012"}
+
+func newSheet(t *testing.T) *recovery.Sheet {
+	t.Helper()
+
+	generatedAt := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	s, err := recovery.NewSheet("<butter>", "butter@example.com", sheetCodes, generatedAt)
+	if err != nil {
+		t.Fatalf("NewSheet()=_, %#v; want nil", err)
+	}
+
+	return s
+}
+
+func TestNewSheet_Invalid(t *testing.T) {
+	tests := []struct {
+		issuer      string
+		accountName string
+		codes       []string
+	}{
+		{issuer: "", accountName: "butter@example.com", codes: sheetCodes},
+		{issuer: "butter", accountName: "", codes: sheetCodes},
+		{issuer: "butter", accountName: "butter@example.com", codes: nil},
+	}
+
+	for _, tt := range tests {
+		if _, err := recovery.NewSheet(tt.issuer, tt.accountName, tt.codes, time.Now()); err == nil {
+			t.Errorf("NewSheet(%q, %q, %v)=_, nil; want error", tt.issuer, tt.accountName, tt.codes)
+		}
+	}
+}
+
+func TestSheet_Text(t *testing.T) {
+	want := `Recovery codes
+
+Issuer: <butter>
+Account: butter@example.com
+Generated: 2020-01-02
+
+1. abcd-1234
+2. efgh-5678
+3. ijkl-9012
+
+Use each code once.
+`
+
+	s := newSheet(t)
+	_ = s.SetInstructions("Use each code once.")
+	if got := s.Text(); got != want {
+		t.Errorf("Text()=%s; want %s", got, want)
+	}
+}
+
+func TestSheet_Markdown(t *testing.T) {
+	s := newSheet(t)
+	got := s.Markdown()
+
+	for _, want := range []string{"- **Issuer:** \\<butter\\>\n", "- **Generated:** 2020-01-02\n", "2. `efgh-5678`\n"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Markdown()=%s; want to contain %q", got, want)
+		}
+	}
+}
+
+func TestSheet_HTML(t *testing.T) {
+	s := newSheet(t)
+	got := s.HTML()
+
+	for _, want := range []string{"<dd>&lt;butter&gt;</dd>", "<li><code>ijkl-9012</code></li>"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("HTML()=%s; want to contain %q", got, want)
+		}
+	}
+	if strings.Contains(got, "<butter>") {
+		t.Errorf("HTML()=%s; want issuer escaped", got)
+	}
+}
+
+func TestSheet_PDF(t *testing.T) {
+	s := newSheet(t)
+	_ = s.SetInstructions("Keep (this) sheet safe")
+
+	got, err := s.PDF()
+	if err != nil {
+		t.Fatalf("PDF()=_, %#v; want nil", err)
+	}
+	if !bytes.HasPrefix(got, []byte("%PDF-1.4\n")) || !bytes.HasSuffix(got, []byte("%%EOF\n")) {
+		t.Errorf("PDF()=%s; want PDF document", got)
+	}
+	for _, want := range []string{"(Issuer: <butter>)", "(1. abcd-1234)", "(Keep \\(this\\) sheet safe)"} {
+		if !bytes.Contains(got, []byte(want)) {
+			t.Errorf("PDF()=%s; want to contain %q", got, want)
+		}
+	}
+
+	// every offset of the cross-reference table points to the object
+	xref := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(got, -1)
+	if len(xref) != 7 {
+		t.Fatalf("PDF() has %d objects in xref; want 7", len(xref))
+	}
+	for i, m := range xref {
+		offset, _ := strconv.Atoi(string(m[1]))
+		if want := fmt.Sprintf("%d 0 obj\n", i+1); !bytes.HasPrefix(got[offset:], []byte(want)) {
+			t.Errorf("offset %d of object %d points to %q", offset, i+1, got[offset:offset+len(want)])
+		}
+	}
+}
+
+func TestSheet_PDF_TooManyCodes(t *testing.T) {
+	codes := make([]string, 200)
+	for i := range codes {
+		codes[i] = "abcd-1234"
+	}
+	s, _ := recovery.NewSheet("butter", "butter@example.com", codes, time.Now())
+
+	if _, err := s.PDF(); err == nil {
+		t.Errorf("PDF()=_, nil; want error, %d codes", len(codes))
+	}
+}
+
+func TestSheet_PDF_LongInstructions(t *testing.T) {
+	s := newSheet(t)
+	_ = s.SetInstructions(strings.Repeat("keep this sheet in a safe place ", 200))
+
+	_, err := s.PDF()
+	if want := "the sheet doesn't fit on a page. please pass shorter instructions"; err == nil || err.Error() != want {
+		t.Errorf("PDF()=_, %#v; want %s", err, want)
+	}
+}
+
+func TestSheet_PDF_Encoding(t *testing.T) {
+	tests := []struct {
+		issuer  string
+		code    string
+		wantErr bool
+	}{
+		{issuer: "Grüße", code: "abcd-1234", wantErr: false},
+		{issuer: "butter", code: "αβγδ-εζηθ", wantErr: true},
+		{issuer: "バター", code: "abcd-1234", wantErr: true},
+		{issuer: "butter", code: "abcd\t1234", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		s, _ := recovery.NewSheet(tt.issuer, "butter@example.com", []string{tt.code}, time.Now())
+		got, err := s.PDF()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("PDF() of %s, %s=_, %#v; want error %t", tt.issuer, tt.code, err, tt.wantErr)
+		}
+		if err == nil && !bytes.Contains(got, []byte("(Issuer: Gr\\374\\337e)")) {
+			t.Errorf("PDF() of %s=%s; want issuer encoded in Latin-1", tt.issuer, got)
+		}
+	}
+}
+
+func TestSheet_SheetIsNil(t *testing.T) {
+	var s *recovery.Sheet
+	if err := s.SetInstructions("instructions"); err != recovery.ErrSheetIsNil {
+		t.Errorf("SetInstructions()=%#v; want %v", err, recovery.ErrSheetIsNil)
+	}
+	if _, err := s.PDF(); err != recovery.ErrSheetIsNil {
+		t.Errorf("PDF()=_, %#v; want %v", err, recovery.ErrSheetIsNil)
+	}
+}
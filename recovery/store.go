@@ -0,0 +1,199 @@
+package recovery
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"sync"
+	"time"
+)
+
+var tableNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// StoredCode is a hashed recovery code in the store
+type StoredCode struct {
+	// Hash is the hashed recovery code
+	Hash string
+	// Consumed reports whether the code has already been consumed
+	Consumed bool
+}
+
+// Store persists hashed recovery codes of each user
+type Store interface {
+	// Save replaces the recovery codes of the user with the hashes
+	Save(ctx context.Context, userID string, hashes []string) error
+	// List returns the recovery codes of the user in the saved order
+	List(ctx context.Context, userID string) ([]StoredCode, error)
+	// Consume marks the recovery code of the user consumed atomically
+	// This returns ErrRecoveryCodeConsumed when the code has already been consumed
+	Consume(ctx context.Context, userID, hash string) error
+}
+
+// MemoryStore is a Store that holds recovery codes in memory
+type MemoryStore struct {
+	mu    sync.Mutex
+	codes map[string][]StoredCode
+}
+
+// NewMemoryStore generates an empty store in memory
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		codes: map[string][]StoredCode{},
+	}
+}
+
+// Save replaces the recovery codes of the user with the hashes
+func (s *MemoryStore) Save(_ context.Context, userID string, hashes []string) error {
+	codes := make([]StoredCode, 0, len(hashes))
+	for _, h := range hashes {
+		codes = append(codes, StoredCode{Hash: h})
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.codes[userID] = codes
+	return nil
+}
+
+// List returns the recovery codes of the user in the saved order
+func (s *MemoryStore) List(_ context.Context, userID string) ([]StoredCode, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	codes := make([]StoredCode, len(s.codes[userID]))
+	copy(codes, s.codes[userID])
+	return codes, nil
+}
+
+// Consume marks the recovery code of the user consumed atomically
+func (s *MemoryStore) Consume(_ context.Context, userID, hash string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, c := range s.codes[userID] {
+		if c.Hash != hash {
+			continue
+		}
+		if c.Consumed {
+			return ErrRecoveryCodeConsumed
+		}
+		s.codes[userID][i].Consumed = true
+		return nil
+	}
+
+	return ErrInvalidRecoveryCode
+}
+
+// SQLStore is a Store backed by database/sql
+// The queries use `?` placeholders, so the driver must accept them like SQLite and MySQL
+type SQLStore struct {
+	db    *sql.DB
+	table string
+	// now returns the current time that is stored as the consumed time
+	now func() time.Time
+}
+
+// NewSQLStore generates a store that uses the table of the database
+func NewSQLStore(db *sql.DB, table string) (*SQLStore, error) {
+	if db == nil {
+		return nil, errors.New("db is nil")
+	}
+	if !tableNamePattern.MatchString(table) {
+		return nil, fmt.Errorf("invalid table name %q", table)
+	}
+
+	return &SQLStore{
+		db:    db,
+		table: table,
+		now:   time.Now,
+	}, nil
+}
+
+// CreateTable creates the table of the store if it doesn't exist
+func (s *SQLStore) CreateTable(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	user_id     VARCHAR(255) NOT NULL,
+	position    INTEGER      NOT NULL,
+	hash        VARCHAR(255) NOT NULL,
+	consumed_at BIGINT       NULL,
+	PRIMARY KEY (user_id, position)
+)`, s.table))
+	return err
+}
+
+// Save replaces the recovery codes of the user with the hashes
+func (s *SQLStore) Save(ctx context.Context, userID string, hashes []string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	_, err = tx.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s WHERE user_id = ?`, s.table), userID)
+	if err != nil {
+		return err
+	}
+	for i, h := range hashes {
+		_, err = tx.ExecContext(ctx, fmt.Sprintf(`INSERT INTO %s (user_id, position, hash) VALUES (?, ?, ?)`, s.table), userID, i, h)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// List returns the recovery codes of the user in the saved order
+func (s *SQLStore) List(ctx context.Context, userID string) ([]StoredCode, error) {
+	rows, err := s.db.QueryContext(ctx, fmt.Sprintf(`SELECT hash, consumed_at FROM %s WHERE user_id = ? ORDER BY position`, s.table), userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var codes []StoredCode
+	for rows.Next() {
+		var hash string
+		var consumedAt sql.NullInt64
+		err = rows.Scan(&hash, &consumedAt)
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, StoredCode{Hash: hash, Consumed: consumedAt.Valid})
+	}
+
+	return codes, rows.Err()
+}
+
+// Consume marks the recovery code of the user consumed atomically
+// The update succeeds only while the code isn't consumed, so concurrent requests never double spend
+func (s *SQLStore) Consume(ctx context.Context, userID, hash string) error {
+	res, err := s.db.ExecContext(ctx,
+		fmt.Sprintf(`UPDATE %s SET consumed_at = ? WHERE user_id = ? AND hash = ? AND consumed_at IS NULL`, s.table),
+		s.now().Unix(), userID, hash)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		var count int
+		err = s.db.QueryRowContext(ctx, fmt.Sprintf(`SELECT COUNT(*) FROM %s WHERE user_id = ? AND hash = ?`, s.table), userID, hash).Scan(&count)
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			return ErrInvalidRecoveryCode
+		}
+		return ErrRecoveryCodeConsumed
+	}
+
+	return nil
+}
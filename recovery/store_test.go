@@ -0,0 +1,22 @@
+package recovery_test
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/butterv/one-time-password/internal/recoverytest"
+	"github.com/butterv/one-time-password/recovery"
+)
+
+func TestMemoryStore(t *testing.T) {
+	recoverytest.Run(t, func(t *testing.T) recovery.Store {
+		return recovery.NewMemoryStore()
+	})
+}
+
+func TestNewSQLStore_InvalidTable(t *testing.T) {
+	_, err := recovery.NewSQLStore(&sql.DB{}, "codes; DROP TABLE users")
+	if err == nil {
+		t.Errorf("NewSQLStore(_, codes; DROP TABLE users)=_, nil; want error")
+	}
+}
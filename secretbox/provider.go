@@ -0,0 +1,119 @@
+package secretbox
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"sync"
+)
+
+// ErrKeyNotFound is an error when the key provider doesn't have the key
+var ErrKeyNotFound = errors.New("key not found")
+
+// KeyProvider provides key-encryption keys identified by ID
+type KeyProvider interface {
+	// Key returns the key-encryption key of the ID
+	// This returns ErrKeyNotFound when the key doesn't exist
+	Key(id string) ([]byte, error)
+}
+
+// MemoryKeyProvider is a KeyProvider that holds keys in memory
+type MemoryKeyProvider struct {
+	mu   sync.RWMutex
+	keys map[string][]byte
+}
+
+// NewMemoryKeyProvider generates an empty key provider in memory
+func NewMemoryKeyProvider() *MemoryKeyProvider {
+	return &MemoryKeyProvider{
+		keys: map[string][]byte{},
+	}
+}
+
+// Add adds a key-encryption key of the ID
+// The key must be 16, 24 or 32 bytes to select AES-128, AES-192 or AES-256
+func (p *MemoryKeyProvider) Add(id string, key []byte) error {
+	if err := validateKeyID(id); err != nil {
+		return err
+	}
+	if err := validateKey(key); err != nil {
+		return err
+	}
+
+	k := make([]byte, len(key))
+	copy(k, key)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.keys[id] = k
+	return nil
+}
+
+// Key returns a copy of the key-encryption key of the ID, so the caller can't change the stored key
+func (p *MemoryKeyProvider) Key(id string) ([]byte, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	key, ok := p.keys[id]
+	if !ok {
+		return nil, ErrKeyNotFound
+	}
+
+	k := make([]byte, len(key))
+	copy(k, key)
+	return k, nil
+}
+
+// LoadKeyFile loads key-encryption keys from a local JSON file
+// The file is an object that maps each key ID to the base64 encoded key like below
+//
+//	{"2020-10": "base64 encoded key", "2020-11": "base64 encoded key"}
+func LoadKeyFile(path string) (*MemoryKeyProvider, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var encoded map[string]string
+	err = json.Unmarshal(b, &encoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key file: %w", err)
+	}
+
+	p := NewMemoryKeyProvider()
+	for id, s := range encoded {
+		key, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %q: %w", id, err)
+		}
+		err = p.Add(id, key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %q: %w", id, err)
+		}
+	}
+
+	return p, nil
+}
+
+func validateKeyID(id string) error {
+	if id == "" {
+		return errors.New("key id is empty")
+	}
+	if strings.Contains(id, envelopeSeparator) {
+		return fmt.Errorf("invalid key id. please pass key id without %q", envelopeSeparator)
+	}
+
+	return nil
+}
+
+func validateKey(key []byte) error {
+	switch len(key) {
+	case 16, 24, 32:
+		return nil
+	}
+
+	return errors.New("invalid key. please pass 16, 24 or 32 bytes")
+}
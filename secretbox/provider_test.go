@@ -0,0 +1,81 @@
+package secretbox_test
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/butterv/one-time-password/secretbox"
+)
+
+func TestMemoryKeyProvider_Add_InvalidKey(t *testing.T) {
+	wantErr := errors.New("invalid key. please pass 16, 24 or 32 bytes")
+
+	p := secretbox.NewMemoryKeyProvider()
+	err := p.Add("key", []byte("short"))
+	if err == nil {
+		t.Fatalf("Add(key, short)=nil; want %v", wantErr)
+	}
+	if err.Error() != wantErr.Error() {
+		t.Errorf("Add(key, short)=%#v; want %v", err, wantErr)
+	}
+}
+
+func TestMemoryKeyProvider_Add_InvalidKeyID(t *testing.T) {
+	wantErr := errors.New(`invalid key id. please pass key id without ":"`)
+
+	p := secretbox.NewMemoryKeyProvider()
+	err := p.Add("a:b", bytes.Repeat([]byte{0x01}, 16))
+	if err == nil {
+		t.Fatalf("Add(a:b, _)=nil; want %v", wantErr)
+	}
+	if err.Error() != wantErr.Error() {
+		t.Errorf("Add(a:b, _)=%#v; want %v", err, wantErr)
+	}
+}
+
+func TestMemoryKeyProvider_Key_Copy(t *testing.T) {
+	want := bytes.Repeat([]byte{1}, 32)
+
+	p := secretbox.NewMemoryKeyProvider()
+	_ = p.Add("2020-10", want)
+	key, _ := p.Key("2020-10")
+	for i := range key {
+		key[i] = 0
+	}
+	if got, _ := p.Key("2020-10"); !bytes.Equal(got, want) {
+		t.Errorf("Key(2020-10)=%x, _; want %x", got, want)
+	}
+}
+
+func TestMemoryKeyProvider_Key_NotFound(t *testing.T) {
+	p := secretbox.NewMemoryKeyProvider()
+	_, err := p.Key("unknown")
+	if err != secretbox.ErrKeyNotFound {
+		t.Errorf("Key(unknown)=_, %#v; want %v", err, secretbox.ErrKeyNotFound)
+	}
+}
+
+func TestLoadKeyFile(t *testing.T) {
+	want := bytes.Repeat([]byte{0x01}, 16)
+
+	path := filepath.Join(t.TempDir(), "keys.json")
+	err := ioutil.WriteFile(path, []byte(`{"2020-10": "AQEBAQEBAQEBAQEBAQEBAQ=="}`), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := secretbox.LoadKeyFile(path)
+	if err != nil {
+		t.Fatalf("LoadKeyFile(%s)=_, %#v; want nil", path, err)
+	}
+	got, err := p.Key("2020-10")
+	if err != nil {
+		t.Fatalf("Key(2020-10)=_, %#v; want nil", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("Key(2020-10)=%v, _; want %v", got, want)
+	}
+}
@@ -0,0 +1,214 @@
+package secretbox
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	crand "crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	// envelopeVersion is the current version of the envelope format
+	envelopeVersion = "v1"
+
+	envelopeSeparator = ":"
+)
+
+// ErrBoxIsNil is an error when the box is nil
+var ErrBoxIsNil = errors.New("secretbox is nil")
+
+// ErrInvalidEnvelope is an error when the envelope is malformed
+var ErrInvalidEnvelope = errors.New("invalid envelope")
+
+// ErrUnsupportedVersion is an error when the version of the envelope isn't supported
+var ErrUnsupportedVersion = errors.New("unsupported envelope version")
+
+// Envelope is an encrypted secret with the key ID and the nonce
+type Envelope struct {
+	// version is the version of the envelope format
+	version string
+	// keyID is the ID of the key-encryption key
+	keyID string
+	// nonce is the nonce of AES-GCM
+	nonce []byte
+	// ciphertext is the encrypted secret with the authentication tag
+	ciphertext []byte
+}
+
+// KeyID returns the ID of the key-encryption key that encrypted the secret
+func (e *Envelope) KeyID() string {
+	if e == nil {
+		return ""
+	}
+
+	return e.keyID
+}
+
+// String returns the storable form of the envelope
+// The form is `version:keyID:nonce:ciphertext`, and nonce and ciphertext are base64url encoded
+func (e *Envelope) String() string {
+	if e == nil {
+		return ""
+	}
+
+	return strings.Join([]string{
+		e.version,
+		e.keyID,
+		base64.RawURLEncoding.EncodeToString(e.nonce),
+		base64.RawURLEncoding.EncodeToString(e.ciphertext),
+	}, envelopeSeparator)
+}
+
+// ParseEnvelope parses the storable form of the envelope
+func ParseEnvelope(s string) (*Envelope, error) {
+	parts := strings.Split(s, envelopeSeparator)
+	if len(parts) != 4 {
+		return nil, ErrInvalidEnvelope
+	}
+	if parts[0] != envelopeVersion {
+		return nil, ErrUnsupportedVersion
+	}
+	if parts[1] == "" {
+		return nil, ErrInvalidEnvelope
+	}
+
+	nonce, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrInvalidEnvelope
+	}
+	ciphertext, err := base64.RawURLEncoding.DecodeString(parts[3])
+	if err != nil {
+		return nil, ErrInvalidEnvelope
+	}
+
+	return &Envelope{
+		version:    parts[0],
+		keyID:      parts[1],
+		nonce:      nonce,
+		ciphertext: ciphertext,
+	}, nil
+}
+
+// Box encrypts secrets under the current key-encryption key
+// It decrypts secrets under any key-encryption key that the key provider has
+type Box struct {
+	// provider provides key-encryption keys
+	provider KeyProvider
+	// keyID is the ID of the current key-encryption key
+	keyID string
+	// rand is the reader to use for generating nonce
+	rand io.Reader
+}
+
+// New generates a box that encrypts secrets under the key of the ID
+func New(provider KeyProvider, keyID string) (*Box, error) {
+	if provider == nil {
+		return nil, errors.New("key provider is nil")
+	}
+	if err := validateKeyID(keyID); err != nil {
+		return nil, err
+	}
+	if _, err := provider.Key(keyID); err != nil {
+		return nil, fmt.Errorf("key %q: %w", keyID, err)
+	}
+
+	return &Box{
+		provider: provider,
+		keyID:    keyID,
+		rand:     crand.Reader,
+	}, nil
+}
+
+// Seal encrypts the secret like `OtpAuth.Secret()` under the current key
+func (b *Box) Seal(secret string) (*Envelope, error) {
+	if b == nil {
+		return nil, ErrBoxIsNil
+	}
+
+	aead, err := b.aead(b.keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	_, err = io.ReadFull(b.rand, nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Envelope{
+		version:    envelopeVersion,
+		keyID:      b.keyID,
+		nonce:      nonce,
+		ciphertext: aead.Seal(nil, nonce, []byte(secret), additionalData(envelopeVersion, b.keyID)),
+	}, nil
+}
+
+// Open decrypts the secret of the envelope under the key of the envelope
+func (b *Box) Open(e *Envelope) (string, error) {
+	if b == nil {
+		return "", ErrBoxIsNil
+	}
+	if e == nil {
+		return "", ErrInvalidEnvelope
+	}
+	if e.version != envelopeVersion {
+		return "", ErrUnsupportedVersion
+	}
+
+	aead, err := b.aead(e.keyID)
+	if err != nil {
+		return "", err
+	}
+	if len(e.nonce) != aead.NonceSize() {
+		return "", ErrInvalidEnvelope
+	}
+
+	plaintext, err := aead.Open(nil, e.nonce, e.ciphertext, additionalData(e.version, e.keyID))
+	if err != nil {
+		return "", err
+	}
+
+	return string(plaintext), nil
+}
+
+// Reencrypt decrypts the envelope and encrypts the secret under the current key
+// This returns the envelope as it is when it is already encrypted under the current key
+func (b *Box) Reencrypt(e *Envelope) (*Envelope, error) {
+	if b == nil {
+		return nil, ErrBoxIsNil
+	}
+
+	secret, err := b.Open(e)
+	if err != nil {
+		return nil, err
+	}
+	if e.keyID == b.keyID {
+		return e, nil
+	}
+
+	return b.Seal(secret)
+}
+
+func (b *Box) aead(keyID string) (cipher.AEAD, error) {
+	key, err := b.provider.Key(keyID)
+	if err != nil {
+		return nil, fmt.Errorf("key %q: %w", keyID, err)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// additionalData binds the version and the key ID to the ciphertext
+func additionalData(version, keyID string) []byte {
+	return []byte(version + envelopeSeparator + keyID)
+}
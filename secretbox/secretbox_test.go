@@ -0,0 +1,125 @@
+package secretbox_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/butterv/one-time-password/secretbox"
+)
+
+const secret = "JXVF3ZJE2U52WP3B77D77VQJ3J3VYDUZ"
+
+func newProvider(t *testing.T) *secretbox.MemoryKeyProvider {
+	t.Helper()
+
+	p := secretbox.NewMemoryKeyProvider()
+	if err := p.Add("old", bytes.Repeat([]byte{0x01}, 32)); err != nil {
+		t.Fatalf("Add(old, _)=%#v; want nil", err)
+	}
+	if err := p.Add("new", bytes.Repeat([]byte{0x02}, 32)); err != nil {
+		t.Fatalf("Add(new, _)=%#v; want nil", err)
+	}
+
+	return p
+}
+
+func TestNew_KeyNotFound(t *testing.T) {
+	_, err := secretbox.New(newProvider(t), "unknown")
+	if !errors.Is(err, secretbox.ErrKeyNotFound) {
+		t.Errorf("New(_, unknown)=_, %#v; want %v", err, secretbox.ErrKeyNotFound)
+	}
+}
+
+func TestBox_SealOpen(t *testing.T) {
+	b, err := secretbox.New(newProvider(t), "old")
+	if err != nil {
+		t.Fatalf("New(_, old)=_, %#v; want nil", err)
+	}
+
+	e, err := b.Seal(secret)
+	if err != nil {
+		t.Fatalf("Seal(%s)=_, %#v; want nil", secret, err)
+	}
+	if got := e.KeyID(); got != "old" {
+		t.Errorf("KeyID()=%s; want old", got)
+	}
+
+	parsed, err := secretbox.ParseEnvelope(e.String())
+	if err != nil {
+		t.Fatalf("ParseEnvelope(%s)=_, %#v; want nil", e, err)
+	}
+	got, err := b.Open(parsed)
+	if err != nil {
+		t.Fatalf("Open(%s)=_, %#v; want nil", parsed, err)
+	}
+	if got != secret {
+		t.Errorf("Open(%s)=%s, _; want %s", parsed, got, secret)
+	}
+}
+
+func TestBox_Open_Tampered(t *testing.T) {
+	b, _ := secretbox.New(newProvider(t), "old")
+	e, _ := b.Seal(secret)
+
+	// the key ID is bound to the ciphertext, so replacing it must fail
+	s := e.String()
+	tampered, err := secretbox.ParseEnvelope("v1:new" + s[len("v1:old"):])
+	if err != nil {
+		t.Fatalf("ParseEnvelope()=_, %#v; want nil", err)
+	}
+	if _, err := b.Open(tampered); err == nil {
+		t.Errorf("Open(%s)=_, nil; want error", tampered)
+	}
+}
+
+func TestBox_Reencrypt(t *testing.T) {
+	p := newProvider(t)
+	oldBox, _ := secretbox.New(p, "old")
+	newBox, _ := secretbox.New(p, "new")
+
+	e, _ := oldBox.Seal(secret)
+	re, err := newBox.Reencrypt(e)
+	if err != nil {
+		t.Fatalf("Reencrypt(%s)=_, %#v; want nil", e, err)
+	}
+	if got := re.KeyID(); got != "new" {
+		t.Errorf("KeyID()=%s; want new", got)
+	}
+	if got, _ := newBox.Open(re); got != secret {
+		t.Errorf("Open(%s)=%s, _; want %s", re, got, secret)
+	}
+}
+
+func TestBox_IsNil(t *testing.T) {
+	var b *secretbox.Box
+	if _, err := b.Seal("secret"); err != secretbox.ErrBoxIsNil {
+		t.Errorf("Seal(secret)=_, %#v; want %v", err, secretbox.ErrBoxIsNil)
+	}
+	if _, err := b.Open(&secretbox.Envelope{}); err != secretbox.ErrBoxIsNil {
+		t.Errorf("Open(_)=_, %#v; want %v", err, secretbox.ErrBoxIsNil)
+	}
+	if _, err := b.Reencrypt(&secretbox.Envelope{}); err != secretbox.ErrBoxIsNil {
+		t.Errorf("Reencrypt(_)=_, %#v; want %v", err, secretbox.ErrBoxIsNil)
+	}
+}
+
+func TestParseEnvelope_Invalid(t *testing.T) {
+	tests := []struct {
+		in      string
+		wantErr error
+	}{
+		{in: "", wantErr: secretbox.ErrInvalidEnvelope},
+		{in: "v1:key:nonce", wantErr: secretbox.ErrInvalidEnvelope},
+		{in: "v1::AAAA:AAAA", wantErr: secretbox.ErrInvalidEnvelope},
+		{in: "v1:key:!!!!:AAAA", wantErr: secretbox.ErrInvalidEnvelope},
+		{in: "v2:key:AAAA:AAAA", wantErr: secretbox.ErrUnsupportedVersion},
+	}
+
+	for _, tt := range tests {
+		_, err := secretbox.ParseEnvelope(tt.in)
+		if err != tt.wantErr {
+			t.Errorf("ParseEnvelope(%s)=_, %#v; want %v", tt.in, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,16 @@
+module github.com/butterv/one-time-password/sqltest
+
+go 1.19
+
+require (
+	github.com/butterv/one-time-password v0.0.0-00010101000000-000000000000
+	github.com/mattn/go-sqlite3 v1.14.22
+)
+
+require (
+	github.com/skip2/go-qrcode v0.0.0-20200617195104-da1b6568686e // indirect
+	golang.org/x/crypto v0.0.0-20220525230936-793ad666bf5e // indirect
+	golang.org/x/sys v0.0.0-20210615035016-665e8c7367d1 // indirect
+)
+
+replace github.com/butterv/one-time-password => ../
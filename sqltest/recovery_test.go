@@ -0,0 +1,22 @@
+package sqltest_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/butterv/one-time-password/internal/recoverytest"
+	"github.com/butterv/one-time-password/recovery"
+)
+
+func TestSQLStore(t *testing.T) {
+	recoverytest.Run(t, func(t *testing.T) recovery.Store {
+		s, err := recovery.NewSQLStore(openDB(t), "recovery_codes")
+		if err != nil {
+			t.Fatalf("NewSQLStore(_, recovery_codes)=_, %#v; want nil", err)
+		}
+		if err := s.CreateTable(context.Background()); err != nil {
+			t.Fatalf("CreateTable()=%#v; want nil", err)
+		}
+		return s
+	})
+}
@@ -0,0 +1,4 @@
+// Package sqltest tests the SQL stores of the module with SQLite
+// This is a separate module, so the module doesn't depend on the SQLite driver, which requires cgo
+// Run `go test ./...` in this directory
+package sqltest
@@ -0,0 +1,129 @@
+package sqltest_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/butterv/one-time-password/internal/storetest"
+	"github.com/butterv/one-time-password/store"
+)
+
+func newSQLRepository(t *testing.T, now func() time.Time) store.Repository {
+	t.Helper()
+
+	r, err := store.NewSQLRepository(openDB(t), "credentials")
+	if err != nil {
+		t.Fatalf("NewSQLRepository(_, credentials)=_, %#v; want nil", err)
+	}
+	if err := r.SetClock(now); err != nil {
+		t.Fatalf("SetClock()=%#v; want nil", err)
+	}
+	if err := r.Migrate(context.Background()); err != nil {
+		t.Fatalf("Migrate()=%#v; want nil", err)
+	}
+
+	return r
+}
+
+func TestSQLRepository(t *testing.T) {
+	storetest.Run(t, newSQLRepository)
+}
+
+func TestSQLRepository_Migrate(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t)
+	r, _ := store.NewSQLRepository(db, "credentials")
+
+	for i := 0; i < 2; i++ {
+		if err := r.Migrate(ctx); err != nil {
+			t.Fatalf("Migrate()=%#v; want nil", err)
+		}
+		if v, err := r.Version(ctx); err != nil || v != 2 {
+			t.Errorf("Version()=%d, %#v; want 2, nil", v, err)
+		}
+	}
+
+	var count int
+	_ = db.QueryRowContext(ctx, `SELECT COUNT(*) FROM credentials_migrations`).Scan(&count)
+	if count != 2 {
+		t.Errorf("credentials_migrations has %d rows; want 2", count)
+	}
+
+	if err := r.Create(ctx, storetest.Credential("c1", "user")); err != nil {
+		t.Fatalf("Create(c1)=%#v; want nil", err)
+	}
+	other, _ := store.NewSQLRepository(db, "credentials")
+	if _, err := other.Get(ctx, "c1"); err != nil {
+		t.Errorf("Get(c1)=_, %#v; want nil", err)
+	}
+}
+
+func TestSQLRepository_Migrate_InProgress(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t)
+	r, _ := store.NewSQLRepository(db, "credentials")
+	_ = r.Migrate(ctx)
+	// another process has claimed the next version
+	_, _ = db.ExecContext(ctx, `DELETE FROM credentials_migrations WHERE version = 2`)
+	_, _ = db.ExecContext(ctx, `DROP INDEX credentials_user_id`)
+	_, _ = db.ExecContext(ctx, `INSERT INTO credentials_migrations (version, applied_at) VALUES (2, 0)`)
+
+	if err := r.Migrate(ctx); err != store.ErrMigrationInProgress {
+		t.Errorf("Migrate()=%#v; want %v", err, store.ErrMigrationInProgress)
+	}
+	if v, _ := r.Version(ctx); v != 1 {
+		t.Errorf("Version()=%d, _; want 1", v)
+	}
+
+	_, _ = db.ExecContext(ctx, `DELETE FROM credentials_migrations WHERE version = 2`)
+	if err := r.Migrate(ctx); err != nil {
+		t.Errorf("Migrate()=%#v; want nil", err)
+	}
+	if v, _ := r.Version(ctx); v != 2 {
+		t.Errorf("Version()=%d, _; want 2", v)
+	}
+}
+
+func TestSQLRepository_Migrate_Concurrent(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t)
+
+	var wg sync.WaitGroup
+	errs := make([]error, 5)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			r, _ := store.NewSQLRepository(db, "credentials")
+			errs[i] = r.Migrate(ctx)
+		}(i)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil && err != store.ErrMigrationInProgress {
+			t.Errorf("Migrate()=%#v; want nil or %v", err, store.ErrMigrationInProgress)
+		}
+	}
+	r, _ := store.NewSQLRepository(db, "credentials")
+	if err := r.Migrate(ctx); err != nil {
+		t.Fatalf("Migrate()=%#v; want nil", err)
+	}
+	if v, _ := r.Version(ctx); v != 2 {
+		t.Errorf("Version()=%d, _; want 2", v)
+	}
+}
+
+func TestSQLRepository_Migrate_NewerVersion(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t)
+	r, _ := store.NewSQLRepository(db, "credentials")
+	_ = r.Migrate(ctx)
+	_, _ = db.ExecContext(ctx, `INSERT INTO credentials_migrations (version, applied_at) VALUES (3, 1)`)
+
+	if err := r.Migrate(ctx); err == nil {
+		t.Error("Migrate()=nil; want error")
+	}
+}
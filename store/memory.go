@@ -0,0 +1,169 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryRepository is a Repository that holds credentials in memory
+type MemoryRepository struct {
+	mu          sync.Mutex
+	credentials map[string]Credential
+	// now returns the current time that is stored as the created and the updated time
+	now func() time.Time
+}
+
+// NewMemoryRepository generates an empty repository in memory
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{
+		credentials: map[string]Credential{},
+		now:         time.Now,
+	}
+}
+
+// SetClock sets a function that returns the current time
+func (r *MemoryRepository) SetClock(now func() time.Time) error {
+	if r == nil {
+		return ErrRepositoryIsNil
+	}
+	if now == nil {
+		return errors.New("now is nil")
+	}
+
+	r.now = now
+	return nil
+}
+
+// Create saves the new credential
+func (r *MemoryRepository) Create(_ context.Context, c *Credential) error {
+	if err := validate(c); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.credentials[c.ID]; ok {
+		return ErrCredentialExists
+	}
+	c.CreatedAt = now(r.now)
+	c.UpdatedAt = c.CreatedAt
+	r.credentials[c.ID] = *c
+	return nil
+}
+
+// Get returns the credential of the identifier
+func (r *MemoryRepository) Get(_ context.Context, id string) (*Credential, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c, ok := r.credentials[id]
+	if !ok {
+		return nil, ErrCredentialNotFound
+	}
+	return &c, nil
+}
+
+// ListByUser returns the credentials of the user in the created order
+func (r *MemoryRepository) ListByUser(_ context.Context, userID string) ([]*Credential, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var cs []*Credential
+	for _, c := range r.credentials {
+		if c.UserID != userID {
+			continue
+		}
+		c := c
+		cs = append(cs, &c)
+	}
+	sort.Slice(cs, func(i, j int) bool {
+		if !cs[i].CreatedAt.Equal(cs[j].CreatedAt) {
+			return cs[i].CreatedAt.Before(cs[j].CreatedAt)
+		}
+		return cs[i].ID < cs[j].ID
+	})
+
+	return cs, nil
+}
+
+// Update replaces the credential including the counter and the last step
+func (r *MemoryRepository) Update(_ context.Context, c *Credential) error {
+	if err := validate(c); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.credentials[c.ID]
+	if !ok {
+		return ErrCredentialNotFound
+	}
+	c.CreatedAt = stored.CreatedAt
+	c.UpdatedAt = now(r.now)
+	r.credentials[c.ID] = *c
+	return nil
+}
+
+// Delete removes the credential of the identifier
+func (r *MemoryRepository) Delete(_ context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.credentials[id]; !ok {
+		return ErrCredentialNotFound
+	}
+	delete(r.credentials, id)
+	return nil
+}
+
+// CompareAndSwapCounter replaces the counter with next only when the stored counter equals old
+func (r *MemoryRepository) CompareAndSwapCounter(_ context.Context, id string, old, next uint64) error {
+	if err := validateValue("counter", old); err != nil {
+		return err
+	}
+	if err := validateValue("counter", next); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c, ok := r.credentials[id]
+	if !ok {
+		return ErrCredentialNotFound
+	}
+	if c.Counter != old {
+		return ErrConflict
+	}
+	c.Counter = next
+	c.UpdatedAt = now(r.now)
+	r.credentials[id] = c
+	return nil
+}
+
+// UpdateLastStep replaces the last step only when step is newer than the stored last step
+func (r *MemoryRepository) UpdateLastStep(_ context.Context, id string, step uint64) error {
+	if err := validateValue("last step", step); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c, ok := r.credentials[id]
+	if !ok {
+		return ErrCredentialNotFound
+	}
+	if step <= c.LastStep {
+		return ErrStepUsed
+	}
+	c.LastStep = step
+	c.UpdatedAt = now(r.now)
+	r.credentials[id] = c
+	return nil
+}
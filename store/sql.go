@@ -0,0 +1,360 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/butterv/one-time-password/otpauth"
+)
+
+var tableNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// migrations is the schema of each version, which is applied in order
+// A released migration must not be changed, so a change of the schema is appended as a new version
+var migrations = []string{
+	// 1: creates the table of credentials
+	`CREATE TABLE %[1]s (
+	id         VARCHAR(255) NOT NULL,
+	user_id    VARCHAR(255) NOT NULL,
+	host       VARCHAR(16)  NOT NULL,
+	secret     TEXT         NOT NULL,
+	algorithm  VARCHAR(16)  NOT NULL,
+	digits     INTEGER      NOT NULL,
+	period     INTEGER      NOT NULL,
+	counter    BIGINT       NOT NULL,
+	last_step  BIGINT       NOT NULL,
+	created_at BIGINT       NOT NULL,
+	updated_at BIGINT       NOT NULL,
+	PRIMARY KEY (id)
+)`,
+	// 2: indexes the credentials by user
+	`CREATE INDEX %[1]s_user_id ON %[1]s (user_id)`,
+}
+
+const columns = `id, user_id, host, secret, algorithm, digits, period, counter, last_step, created_at, updated_at`
+
+// SQLRepository is a Repository backed by database/sql
+// The queries use `?` placeholders, so the driver must accept them like SQLite and MySQL
+type SQLRepository struct {
+	db    *sql.DB
+	table string
+	// now returns the current time that is stored as the created and the updated time
+	now func() time.Time
+}
+
+// NewSQLRepository generates a repository that uses the table of the database
+// The schema is created and upgraded by Migrate
+func NewSQLRepository(db *sql.DB, table string) (*SQLRepository, error) {
+	if db == nil {
+		return nil, errors.New("db is nil")
+	}
+	if !tableNamePattern.MatchString(table) {
+		return nil, fmt.Errorf("invalid table name %q", table)
+	}
+
+	return &SQLRepository{
+		db:    db,
+		table: table,
+		now:   time.Now,
+	}, nil
+}
+
+// SetClock sets a function that returns the current time
+func (r *SQLRepository) SetClock(now func() time.Time) error {
+	if r == nil {
+		return ErrRepositoryIsNil
+	}
+	if now == nil {
+		return errors.New("now is nil")
+	}
+
+	r.now = now
+	return nil
+}
+
+// Migrate applies the migrations that haven't been applied yet
+// The applied versions are recorded in the table whose name is the table of the repository with the suffix `_migrations`
+// This returns ErrMigrationInProgress when another process is applying a migration, so the caller can retry later
+func (r *SQLRepository) Migrate(ctx context.Context) error {
+	_, err := r.db.ExecContext(ctx, fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s_migrations (
+	version    INTEGER NOT NULL,
+	applied_at BIGINT  NOT NULL,
+	PRIMARY KEY (version)
+)`, r.table))
+	if err != nil {
+		return err
+	}
+
+	v, err := r.Version(ctx)
+	if err != nil {
+		return err
+	}
+	if v > len(migrations) {
+		return fmt.Errorf("schema version %d is newer than the latest version %d", v, len(migrations))
+	}
+
+	for i := v; i < len(migrations); i++ {
+		if err := r.migrate(ctx, i+1); err != nil {
+			if err == ErrMigrationInProgress {
+				return err
+			}
+			return fmt.Errorf("migration %d: %w", i+1, err)
+		}
+	}
+
+	return nil
+}
+
+// migrate claims the version and applies its migration
+// The claim is a row of the version whose applied time is 0, and the primary key lets only one process insert it,
+// so concurrent processes never apply the same migration twice
+// The migration isn't applied in a transaction, because databases like MySQL commit DDL implicitly
+// When the process stops while applying the migration, the claim remains and has to be deleted by hand
+func (r *SQLRepository) migrate(ctx context.Context, version int) error {
+	_, err := r.db.ExecContext(ctx, fmt.Sprintf(`INSERT INTO %s_migrations (version, applied_at) VALUES (?, 0)`, r.table), version)
+	if err != nil {
+		var count int
+		if qerr := r.db.QueryRowContext(ctx, fmt.Sprintf(`SELECT COUNT(*) FROM %s_migrations WHERE version = ?`, r.table), version).Scan(&count); qerr == nil && count > 0 {
+			return ErrMigrationInProgress
+		}
+		return err
+	}
+
+	_, err = r.db.ExecContext(ctx, fmt.Sprintf(migrations[version-1], r.table))
+	if err != nil {
+		// the claim is released, so the migration can be retried
+		_, _ = r.db.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s_migrations WHERE version = ? AND applied_at = 0`, r.table), version)
+		return err
+	}
+
+	appliedAt := r.now().Unix()
+	if appliedAt == 0 {
+		appliedAt = 1
+	}
+	_, err = r.db.ExecContext(ctx, fmt.Sprintf(`UPDATE %s_migrations SET applied_at = ? WHERE version = ?`, r.table), appliedAt, version)
+	return err
+}
+
+// Version returns the latest version of the applied migrations, which is 0 before the first migration
+// A version that is claimed but not applied yet isn't counted
+func (r *SQLRepository) Version(ctx context.Context) (int, error) {
+	var v sql.NullInt64
+	err := r.db.QueryRowContext(ctx, fmt.Sprintf(`SELECT MAX(version) FROM %s_migrations WHERE applied_at <> 0`, r.table)).Scan(&v)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(v.Int64), nil
+}
+
+// Create saves the new credential
+// This returns ErrCredentialExists when the identifier is used, even if another request creates it concurrently
+func (r *SQLRepository) Create(ctx context.Context, c *Credential) error {
+	if err := validate(c); err != nil {
+		return err
+	}
+
+	t := now(r.now)
+	_, err := r.db.ExecContext(ctx,
+		fmt.Sprintf(`INSERT INTO %s (%s) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`, r.table, columns),
+		c.ID, c.UserID, c.Host.String(), c.Secret, c.Algorithm.String(), int(c.Digits), c.Period, c.Counter, c.LastStep, t.Unix(), t.Unix())
+	if err != nil {
+		// the error of the primary key differs among the drivers, so the credential is looked up instead
+		if _, gerr := r.Get(ctx, c.ID); gerr == nil {
+			return ErrCredentialExists
+		}
+		return err
+	}
+
+	c.CreatedAt, c.UpdatedAt = t, t
+	return nil
+}
+
+// Get returns the credential of the identifier
+func (r *SQLRepository) Get(ctx context.Context, id string) (*Credential, error) {
+	row := r.db.QueryRowContext(ctx, fmt.Sprintf(`SELECT %s FROM %s WHERE id = ?`, columns, r.table), id)
+	c, err := scan(row)
+	if err == sql.ErrNoRows {
+		return nil, ErrCredentialNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// ListByUser returns the credentials of the user in the created order
+func (r *SQLRepository) ListByUser(ctx context.Context, userID string) ([]*Credential, error) {
+	rows, err := r.db.QueryContext(ctx, fmt.Sprintf(`SELECT %s FROM %s WHERE user_id = ? ORDER BY created_at, id`, columns, r.table), userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var cs []*Credential
+	for rows.Next() {
+		c, err := scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		cs = append(cs, c)
+	}
+
+	return cs, rows.Err()
+}
+
+// Update replaces the credential including the counter and the last step
+func (r *SQLRepository) Update(ctx context.Context, c *Credential) error {
+	if err := validate(c); err != nil {
+		return err
+	}
+
+	t := now(r.now)
+	res, err := r.db.ExecContext(ctx,
+		fmt.Sprintf(`UPDATE %s SET user_id = ?, host = ?, secret = ?, algorithm = ?, digits = ?, period = ?, counter = ?, last_step = ?, updated_at = ? WHERE id = ?`, r.table),
+		c.UserID, c.Host.String(), c.Secret, c.Algorithm.String(), int(c.Digits), c.Period, c.Counter, c.LastStep, t.Unix(), c.ID)
+	if err != nil {
+		return err
+	}
+	if err := affected(res, ErrCredentialNotFound); err != nil {
+		return err
+	}
+
+	stored, err := r.Get(ctx, c.ID)
+	if err != nil {
+		return err
+	}
+	c.CreatedAt, c.UpdatedAt = stored.CreatedAt, stored.UpdatedAt
+	return nil
+}
+
+// Delete removes the credential of the identifier
+func (r *SQLRepository) Delete(ctx context.Context, id string) error {
+	res, err := r.db.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s WHERE id = ?`, r.table), id)
+	if err != nil {
+		return err
+	}
+
+	return affected(res, ErrCredentialNotFound)
+}
+
+// CompareAndSwapCounter replaces the counter with next only when the stored counter equals old
+// The condition is checked in the update, so concurrent requests never swap the same counter twice
+func (r *SQLRepository) CompareAndSwapCounter(ctx context.Context, id string, old, next uint64) error {
+	if err := validateValue("counter", old); err != nil {
+		return err
+	}
+	if err := validateValue("counter", next); err != nil {
+		return err
+	}
+
+	res, err := r.db.ExecContext(ctx,
+		fmt.Sprintf(`UPDATE %s SET counter = ?, updated_at = ? WHERE id = ? AND counter = ?`, r.table),
+		next, now(r.now).Unix(), id, old)
+	if err != nil {
+		return err
+	}
+
+	return r.notUpdated(ctx, res, id, ErrConflict)
+}
+
+// UpdateLastStep replaces the last step only when step is newer than the stored last step
+// The condition is checked in the update, so concurrent requests never accept the same step twice
+func (r *SQLRepository) UpdateLastStep(ctx context.Context, id string, step uint64) error {
+	if err := validateValue("last step", step); err != nil {
+		return err
+	}
+
+	res, err := r.db.ExecContext(ctx,
+		fmt.Sprintf(`UPDATE %s SET last_step = ?, updated_at = ? WHERE id = ? AND last_step < ?`, r.table),
+		step, now(r.now).Unix(), id, step)
+	if err != nil {
+		return err
+	}
+
+	return r.notUpdated(ctx, res, id, ErrStepUsed)
+}
+
+// notUpdated returns the error when the conditional update didn't update the credential that exists
+func (r *SQLRepository) notUpdated(ctx context.Context, res sql.Result, id string, conflict error) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		return nil
+	}
+
+	var count int
+	err = r.db.QueryRowContext(ctx, fmt.Sprintf(`SELECT COUNT(*) FROM %s WHERE id = ?`, r.table), id).Scan(&count)
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrCredentialNotFound
+	}
+
+	return conflict
+}
+
+func affected(res sql.Result, notFound error) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return notFound
+	}
+
+	return nil
+}
+
+// scanner is a row of the credentials
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scan(s scanner) (*Credential, error) {
+	var c Credential
+	var host, algorithm string
+	var digits int
+	var createdAt, updatedAt int64
+	err := s.Scan(&c.ID, &c.UserID, &host, &c.Secret, &algorithm, &digits, &c.Period, &c.Counter, &c.LastStep, &createdAt, &updatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	switch host {
+	case otpauth.HostHOTP.String():
+		c.Host = otpauth.HostHOTP
+	case otpauth.HostTOTP.String():
+		c.Host = otpauth.HostTOTP
+	default:
+		return nil, fmt.Errorf("credential %q has invalid host %q", c.ID, host)
+	}
+	a, err := parseAlgorithm(algorithm)
+	if err != nil {
+		return nil, fmt.Errorf("credential %q: %w", c.ID, err)
+	}
+	c.Algorithm = a
+	c.Digits = otpauth.Digits(digits)
+	c.CreatedAt = time.Unix(createdAt, 0)
+	c.UpdatedAt = time.Unix(updatedAt, 0)
+
+	return &c, nil
+}
+
+func parseAlgorithm(s string) (otpauth.Algorithm, error) {
+	for a := otpauth.AlgorithmSHA1; a.Enabled(); a++ {
+		if a.String() == s {
+			return a, nil
+		}
+	}
+
+	return 0, fmt.Errorf("invalid algorithm %q", s)
+}
@@ -0,0 +1,17 @@
+package store_test
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/butterv/one-time-password/store"
+)
+
+func TestNewSQLRepository_Invalid(t *testing.T) {
+	if _, err := store.NewSQLRepository(nil, "credentials"); err == nil {
+		t.Error("NewSQLRepository(nil, credentials)=_, nil; want error")
+	}
+	if _, err := store.NewSQLRepository(&sql.DB{}, "credentials; DROP TABLE users"); err == nil {
+		t.Error("NewSQLRepository(_, credentials; DROP TABLE users)=_, nil; want error")
+	}
+}
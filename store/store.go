@@ -0,0 +1,133 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/butterv/one-time-password/otpauth"
+)
+
+// ErrRepositoryIsNil is an error when the repository is nil
+var ErrRepositoryIsNil = errors.New("repository is nil")
+
+// ErrCredentialIsNil is an error when the credential is nil
+var ErrCredentialIsNil = errors.New("credential is nil")
+
+// ErrCredentialNotFound is an error when the credential of the identifier doesn't exist
+var ErrCredentialNotFound = errors.New("credential is not found")
+
+// ErrCredentialExists is an error when a credential of the same identifier already exists
+var ErrCredentialExists = errors.New("credential already exists")
+
+// ErrConflict is an error when the counter has been updated by another request
+var ErrConflict = errors.New("counter has been updated by another request")
+
+// ErrStepUsed is an error when the time step is the same as or older than the last used step
+var ErrStepUsed = errors.New("time step has already been used")
+
+// ErrMigrationInProgress is an error when another process is applying a migration of the schema
+var ErrMigrationInProgress = errors.New("migration is in progress by another process")
+
+// Credential is a key of one time password registered to a user
+type Credential struct {
+	// ID is the identifier of the credential
+	ID string
+	// UserID is the user that the credential belongs to
+	UserID string
+	// Host is the type of the one time password
+	Host otpauth.Host
+	// Secret is the base32 encoded secret, which may be sealed with secretbox
+	Secret string
+	// Algorithm is the hash algorithm
+	Algorithm otpauth.Algorithm
+	// Digits is the number of digits
+	Digits otpauth.Digits
+	// Period is the seconds that a TOTP passcode is valid
+	Period uint
+	// Counter is the next counter of HOTP
+	// The databases store it in a signed 64 bit integer, so it must be math.MaxInt64 or less
+	Counter uint64
+	// LastStep is the time step of the last valid TOTP passcode
+	// A passcode of the same or older step must be rejected, so a passcode can be used only once
+	// The databases store it in a signed 64 bit integer, so it must be math.MaxInt64 or less
+	LastStep uint64
+	// CreatedAt is the time when the credential is created, which is set by the repository
+	CreatedAt time.Time
+	// UpdatedAt is the time when the credential is updated last, which is set by the repository
+	UpdatedAt time.Time
+}
+
+// Repository persists credentials of one time password
+// The counter and the last step are updated atomically, so concurrent verifications never accept the same passcode twice
+type Repository interface {
+	// Create saves the new credential
+	// This returns ErrCredentialExists when a credential of the same identifier already exists
+	Create(ctx context.Context, c *Credential) error
+	// Get returns the credential of the identifier
+	// This returns ErrCredentialNotFound when the credential doesn't exist
+	Get(ctx context.Context, id string) (*Credential, error)
+	// ListByUser returns the credentials of the user in the created order
+	ListByUser(ctx context.Context, userID string) ([]*Credential, error)
+	// Update replaces the credential including the counter and the last step, like after resynchronization
+	// This returns ErrCredentialNotFound when the credential doesn't exist
+	Update(ctx context.Context, c *Credential) error
+	// Delete removes the credential of the identifier
+	// This returns ErrCredentialNotFound when the credential doesn't exist
+	Delete(ctx context.Context, id string) error
+	// CompareAndSwapCounter replaces the counter with next only when the stored counter equals old
+	// The counters must be math.MaxInt64 or less
+	// This returns ErrConflict when the counter has been updated by another request
+	CompareAndSwapCounter(ctx context.Context, id string, old, next uint64) error
+	// UpdateLastStep replaces the last step only when step is newer than the stored last step
+	// The step must be math.MaxInt64 or less
+	// This returns ErrStepUsed when the step is the same as or older than the stored last step
+	UpdateLastStep(ctx context.Context, id string, step uint64) error
+}
+
+// validate validates the credential before it is saved
+func validate(c *Credential) error {
+	if c == nil {
+		return ErrCredentialIsNil
+	}
+	if c.ID == "" {
+		return errors.New("id is empty")
+	}
+	if c.Host != otpauth.HostHOTP && c.Host != otpauth.HostTOTP {
+		return fmt.Errorf("invalid host. please pass %d or %d", otpauth.HostHOTP, otpauth.HostTOTP)
+	}
+	if c.Secret == "" {
+		return errors.New("secret is empty")
+	}
+	if !c.Algorithm.Enabled() {
+		return fmt.Errorf("invalid algorithm. please pass any of %d to %d", otpauth.AlgorithmSHA1, otpauth.AlgorithmMD5)
+	}
+	if !c.Digits.Enabled() {
+		return fmt.Errorf("invalid digits. please pass %d or %d", otpauth.DigitsSix, otpauth.DigitsEight)
+	}
+	if c.Host == otpauth.HostTOTP && c.Period == 0 {
+		return errors.New("invalid period. please pass greater than 0")
+	}
+	if err := validateValue("counter", c.Counter); err != nil {
+		return err
+	}
+
+	return validateValue("last step", c.LastStep)
+}
+
+// validateValue validates that the counter or the step fits in the signed 64 bit integer of the databases
+// database/sql rejects uint64 values with the high bit set, so every repository rejects them alike
+func validateValue(name string, v uint64) error {
+	if v > math.MaxInt64 {
+		return fmt.Errorf("invalid %s. please pass %d or less", name, uint64(math.MaxInt64))
+	}
+
+	return nil
+}
+
+// now returns the current time in seconds, which is the precision of the timestamps in the repositories
+func now(f func() time.Time) time.Time {
+	return time.Unix(f().Unix(), 0)
+}
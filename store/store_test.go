@@ -0,0 +1,30 @@
+package store_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/butterv/one-time-password/internal/storetest"
+	"github.com/butterv/one-time-password/store"
+)
+
+func TestMemoryRepository(t *testing.T) {
+	storetest.Run(t, func(t *testing.T, now func() time.Time) store.Repository {
+		r := store.NewMemoryRepository()
+		if err := r.SetClock(now); err != nil {
+			t.Fatalf("SetClock()=%#v; want nil", err)
+		}
+		return r
+	})
+}
+
+func TestRepository_RepositoryIsNil(t *testing.T) {
+	var m *store.MemoryRepository
+	if err := m.SetClock(time.Now); err != store.ErrRepositoryIsNil {
+		t.Errorf("SetClock()=%#v; want %v", err, store.ErrRepositoryIsNil)
+	}
+	var s *store.SQLRepository
+	if err := s.SetClock(time.Now); err != store.ErrRepositoryIsNil {
+		t.Errorf("SetClock()=%#v; want %v", err, store.ErrRepositoryIsNil)
+	}
+}
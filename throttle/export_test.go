@@ -0,0 +1,9 @@
+package throttle
+
+// Len returns the count of the buckets held in memory
+func (l *MemoryLimiter) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return len(l.buckets)
+}
@@ -0,0 +1,245 @@
+package throttle
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	defaultBurst           = uint(5)
+	defaultRefillInterval  = 30 * time.Second
+	defaultBackoffAfter    = uint(3)
+	defaultBackoffBase     = time.Second
+	defaultBackoffMax      = 5 * time.Minute
+	defaultLockoutFailures = uint(10)
+	defaultLockoutDuration = 15 * time.Minute
+)
+
+// ErrLimiterIsNil is an error when the limiter is nil
+var ErrLimiterIsNil = errors.New("limiter is nil")
+
+// Limiter limits attempts of verification of each account
+type Limiter interface {
+	// Allow reserves an attempt of the key at the time
+	// When the attempt isn't allowed, this returns false and the duration until the next attempt is allowed
+	Allow(ctx context.Context, key string, t time.Time) (bool, time.Duration, error)
+	// Fail records a failed attempt of the key at the time
+	Fail(ctx context.Context, key string, t time.Time) error
+	// Reset clears the failures of the key after a successful attempt
+	Reset(ctx context.Context, key string) error
+}
+
+// bucket is the state of a key
+type bucket struct {
+	// tokens is the count of attempts available
+	tokens float64
+	// updated is the time when tokens is refilled
+	updated time.Time
+	// failures is the count of consecutive failures
+	failures uint
+	// blockedUntil is the time until attempts are blocked by backoff or lockout
+	blockedUntil time.Time
+}
+
+// MemoryLimiter is a Limiter that holds a token bucket of each key in memory
+// Each attempt takes a token and tokens are refilled at a constant interval up to the burst,
+// and consecutive failures block attempts with exponential backoff and then lock the key out temporarily
+// The state of a key is dropped when its tokens are refilled up to the burst and it isn't blocked,
+// so the failures of a key are forgotten after the key is idle for the time to refill all tokens
+type MemoryLimiter struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	// swept is the time when the idle buckets are dropped
+	swept time.Time
+
+	// burst is the maximum count of tokens
+	// The default value is 5
+	burst uint
+	// refillInterval is the interval that a token is refilled
+	// The default value is 30 seconds
+	refillInterval time.Duration
+	// backoffAfter is the count of consecutive failures that are allowed without backoff
+	// The default value is 3
+	backoffAfter uint
+	// backoffBase is the first delay of backoff, which is doubled for each failure
+	// The default value is 1 second
+	backoffBase time.Duration
+	// backoffMax is the maximum delay of backoff
+	// The default value is 5 minutes
+	backoffMax time.Duration
+	// lockoutFailures is the count of consecutive failures that lock the key out
+	// The default value is 10
+	lockoutFailures uint
+	// lockoutDuration is the duration of lockout
+	// The default value is 15 minutes
+	lockoutDuration time.Duration
+}
+
+// NewMemoryLimiter generates a limiter in memory with default values
+func NewMemoryLimiter() *MemoryLimiter {
+	return &MemoryLimiter{
+		buckets:         map[string]*bucket{},
+		burst:           defaultBurst,
+		refillInterval:  defaultRefillInterval,
+		backoffAfter:    defaultBackoffAfter,
+		backoffBase:     defaultBackoffBase,
+		backoffMax:      defaultBackoffMax,
+		lockoutFailures: defaultLockoutFailures,
+		lockoutDuration: defaultLockoutDuration,
+	}
+}
+
+// SetBucket sets the maximum count of tokens and the interval that a token is refilled
+func (l *MemoryLimiter) SetBucket(burst uint, refillInterval time.Duration) error {
+	if l == nil {
+		return ErrLimiterIsNil
+	}
+	if burst == 0 {
+		return errors.New("invalid burst. please pass greater than 0")
+	}
+	if refillInterval <= 0 {
+		return errors.New("invalid refillInterval. please pass greater than 0")
+	}
+
+	l.burst = burst
+	l.refillInterval = refillInterval
+	return nil
+}
+
+// SetBackoff sets the count of failures allowed without backoff, and the first and the maximum delay of backoff
+func (l *MemoryLimiter) SetBackoff(after uint, base, max time.Duration) error {
+	if l == nil {
+		return ErrLimiterIsNil
+	}
+	if base <= 0 || max < base {
+		return errors.New("invalid backoff. please pass base greater than 0 and max greater than or equal to base")
+	}
+
+	l.backoffAfter = after
+	l.backoffBase = base
+	l.backoffMax = max
+	return nil
+}
+
+// SetLockout sets the count of consecutive failures that lock the key out and the duration of lockout
+func (l *MemoryLimiter) SetLockout(failures uint, duration time.Duration) error {
+	if l == nil {
+		return ErrLimiterIsNil
+	}
+	if failures == 0 {
+		return errors.New("invalid failures. please pass greater than 0")
+	}
+	if duration <= 0 {
+		return errors.New("invalid duration. please pass greater than 0")
+	}
+
+	l.lockoutFailures = failures
+	l.lockoutDuration = duration
+	return nil
+}
+
+// Allow reserves an attempt of the key at the time
+func (l *MemoryLimiter) Allow(_ context.Context, key string, t time.Time) (bool, time.Duration, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b := l.bucket(key, t)
+	if t.Before(b.blockedUntil) {
+		return false, b.blockedUntil.Sub(t), nil
+	}
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) * float64(l.refillInterval)), nil
+	}
+
+	b.tokens--
+	return true, 0, nil
+}
+
+// Fail records a failed attempt of the key at the time
+// The failures are cleared when the key is locked out, so backoff starts over after lockout
+func (l *MemoryLimiter) Fail(_ context.Context, key string, t time.Time) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b := l.bucket(key, t)
+	b.failures++
+	if b.failures >= l.lockoutFailures {
+		b.failures = 0
+		b.blockedUntil = t.Add(l.lockoutDuration)
+		return nil
+	}
+	if b.failures > l.backoffAfter {
+		b.blockedUntil = t.Add(l.backoff(b.failures - l.backoffAfter))
+	}
+
+	return nil
+}
+
+// Reset clears the failures and the tokens of the key
+func (l *MemoryLimiter) Reset(_ context.Context, key string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.buckets, key)
+	return nil
+}
+
+// bucket returns the bucket of the key refilled at the time
+func (l *MemoryLimiter) bucket(key string, t time.Time) *bucket {
+	l.sweep(t)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(l.burst), updated: t}
+		l.buckets[key] = b
+		return b
+	}
+
+	l.refill(b, t)
+	return b
+}
+
+// refill adds the tokens refilled until the time up to the burst
+func (l *MemoryLimiter) refill(b *bucket, t time.Time) {
+	if elapsed := t.Sub(b.updated); elapsed > 0 {
+		b.tokens += float64(elapsed) / float64(l.refillInterval)
+		if b.tokens > float64(l.burst) {
+			b.tokens = float64(l.burst)
+		}
+		b.updated = t
+	}
+}
+
+// sweep drops the buckets that are full and not blocked at the time
+// The buckets are swept at most once in the time to refill all tokens, so an access doesn't always scan all buckets
+func (l *MemoryLimiter) sweep(t time.Time) {
+	if t.Sub(l.swept) < time.Duration(l.burst)*l.refillInterval {
+		return
+	}
+	l.swept = t
+
+	for key, b := range l.buckets {
+		l.refill(b, t)
+		if b.tokens >= float64(l.burst) && !t.Before(b.blockedUntil) {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// backoff returns the delay of the nth failure over the allowed count
+func (l *MemoryLimiter) backoff(n uint) time.Duration {
+	d := l.backoffBase
+	for i := uint(1); i < n; i++ {
+		d *= 2
+		if d >= l.backoffMax {
+			return l.backoffMax
+		}
+	}
+	if d > l.backoffMax {
+		return l.backoffMax
+	}
+
+	return d
+}
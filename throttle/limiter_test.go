@@ -0,0 +1,172 @@
+package throttle_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/butterv/one-time-password/throttle"
+)
+
+var now = time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC)
+
+func TestMemoryLimiter_TokenBucket(t *testing.T) {
+	ctx := context.Background()
+	l := throttle.NewMemoryLimiter()
+	_ = l.SetBucket(3, 10*time.Second)
+
+	for i := 0; i < 3; i++ {
+		if ok, _, _ := l.Allow(ctx, "user", now); !ok {
+			t.Fatalf("Allow() #%d=false; want true", i)
+		}
+	}
+	ok, retryAfter, err := l.Allow(ctx, "user", now)
+	if err != nil || ok || retryAfter != 10*time.Second {
+		t.Errorf("Allow()=%t, %v, %#v; want false, 10s, nil", ok, retryAfter, err)
+	}
+
+	// another key has its own bucket
+	if ok, _, _ := l.Allow(ctx, "other", now); !ok {
+		t.Errorf("Allow(other)=false; want true")
+	}
+
+	// a token is refilled after the interval
+	if ok, _, _ := l.Allow(ctx, "user", now.Add(10*time.Second)); !ok {
+		t.Errorf("Allow() after refill=false; want true")
+	}
+}
+
+func TestMemoryLimiter_Backoff(t *testing.T) {
+	ctx := context.Background()
+	l := throttle.NewMemoryLimiter()
+	_ = l.SetBucket(100, time.Second)
+	_ = l.SetBackoff(2, time.Second, 3*time.Second)
+
+	tests := []struct {
+		name string
+		want time.Duration
+	}{
+		{name: "1st failure", want: 0},
+		{name: "2nd failure", want: 0},
+		{name: "3rd failure", want: time.Second},
+		{name: "4th failure", want: 2 * time.Second},
+		{name: "5th failure", want: 3 * time.Second},
+		{name: "6th failure", want: 3 * time.Second},
+	}
+
+	ti := now
+	for _, tt := range tests {
+		if ok, _, _ := l.Allow(ctx, "user", ti); !ok {
+			t.Fatalf("%s: Allow()=false; want true", tt.name)
+		}
+		_ = l.Fail(ctx, "user", ti)
+
+		ok, retryAfter, _ := l.Allow(ctx, "user", ti)
+		if tt.want == 0 {
+			if !ok {
+				t.Errorf("%s: Allow()=false, %v; want true", tt.name, retryAfter)
+			}
+			continue
+		}
+		if ok || retryAfter != tt.want {
+			t.Errorf("%s: Allow()=%t, %v; want false, %v", tt.name, ok, retryAfter, tt.want)
+		}
+		ti = ti.Add(tt.want)
+	}
+}
+
+func TestMemoryLimiter_Lockout(t *testing.T) {
+	ctx := context.Background()
+	l := throttle.NewMemoryLimiter()
+	_ = l.SetBucket(100, time.Second)
+	_ = l.SetBackoff(100, time.Second, time.Second)
+	_ = l.SetLockout(3, time.Hour)
+
+	for i := 0; i < 3; i++ {
+		_ = l.Fail(ctx, "user", now)
+	}
+	ok, retryAfter, _ := l.Allow(ctx, "user", now.Add(time.Minute))
+	if ok || retryAfter != 59*time.Minute {
+		t.Errorf("Allow()=%t, %v; want false, 59m", ok, retryAfter)
+	}
+	if ok, _, _ := l.Allow(ctx, "user", now.Add(time.Hour)); !ok {
+		t.Errorf("Allow() after lockout=false; want true")
+	}
+}
+
+func TestMemoryLimiter_Reset(t *testing.T) {
+	ctx := context.Background()
+	l := throttle.NewMemoryLimiter()
+	_ = l.SetBucket(1, time.Hour)
+	_ = l.SetLockout(1, time.Hour)
+
+	_, _, _ = l.Allow(ctx, "user", now)
+	_ = l.Fail(ctx, "user", now)
+	if ok, _, _ := l.Allow(ctx, "user", now); ok {
+		t.Fatalf("Allow()=true; want false")
+	}
+
+	_ = l.Reset(ctx, "user")
+	if ok, _, _ := l.Allow(ctx, "user", now); !ok {
+		t.Errorf("Allow() after Reset=false; want true")
+	}
+}
+
+func TestMemoryLimiter_Sweep(t *testing.T) {
+	ctx := context.Background()
+	l := throttle.NewMemoryLimiter()
+	_ = l.SetBucket(2, 10*time.Second)
+	_ = l.SetLockout(2, time.Hour)
+
+	_, _, _ = l.Allow(ctx, "idle", now)
+	_, _, _ = l.Allow(ctx, "failed", now)
+	_ = l.Fail(ctx, "failed", now)
+	_, _, _ = l.Allow(ctx, "locked", now)
+	_ = l.Fail(ctx, "locked", now)
+	_ = l.Fail(ctx, "locked", now)
+	if n := l.Len(); n != 3 {
+		t.Fatalf("Len()=%d; want 3", n)
+	}
+
+	// the buckets aren't swept until all tokens can be refilled
+	_, _, _ = l.Allow(ctx, "other", now.Add(19*time.Second))
+	if n := l.Len(); n != 4 {
+		t.Errorf("Len()=%d; want 4", n)
+	}
+
+	// the full buckets are dropped, but the locked out key is kept
+	_, _, _ = l.Allow(ctx, "other", now.Add(40*time.Second))
+	if n := l.Len(); n != 2 {
+		t.Errorf("Len()=%d; want 2", n)
+	}
+	if ok, _, _ := l.Allow(ctx, "locked", now.Add(40*time.Second)); ok {
+		t.Errorf("Allow(locked)=true; want false")
+	}
+
+	// the key is dropped after the lockout
+	_, _, _ = l.Allow(ctx, "other", now.Add(2*time.Hour))
+	if n := l.Len(); n != 1 {
+		t.Errorf("Len()=%d; want 1", n)
+	}
+}
+
+func TestMemoryLimiter_InvalidSettings(t *testing.T) {
+	l := throttle.NewMemoryLimiter()
+	if err := l.SetBucket(0, time.Second); err == nil {
+		t.Errorf("SetBucket(0, 1s)=nil; want error")
+	}
+	if err := l.SetBucket(1, 0); err == nil {
+		t.Errorf("SetBucket(1, 0)=nil; want error")
+	}
+	if err := l.SetBackoff(1, 2*time.Second, time.Second); err == nil {
+		t.Errorf("SetBackoff(1, 2s, 1s)=nil; want error")
+	}
+	if err := l.SetLockout(0, time.Second); err == nil {
+		t.Errorf("SetLockout(0, 1s)=nil; want error")
+	}
+
+	var nilLimiter *throttle.MemoryLimiter
+	if err := nilLimiter.SetBucket(1, time.Second); err != throttle.ErrLimiterIsNil {
+		t.Errorf("SetBucket()=%#v; want %v", err, throttle.ErrLimiterIsNil)
+	}
+}
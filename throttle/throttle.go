@@ -0,0 +1,128 @@
+package throttle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/butterv/one-time-password/hotp"
+	"github.com/butterv/one-time-password/otpauth"
+	"github.com/butterv/one-time-password/recovery"
+	"github.com/butterv/one-time-password/totp"
+)
+
+var (
+	// ErrThrottleIsNil is an error when the throttle is nil
+	ErrThrottleIsNil = errors.New("throttle is nil")
+	// ErrThrottled matches every ThrottledError with errors.Is
+	ErrThrottled = errors.New("throttled")
+)
+
+// ThrottledError is an error when the attempt of verification is throttled
+type ThrottledError struct {
+	// RetryAfter is the duration until the next attempt is allowed
+	RetryAfter time.Duration
+}
+
+// Error returns the duration until the next attempt is allowed
+func (e *ThrottledError) Error() string {
+	return fmt.Sprintf("throttled. please retry after %s", e.RetryAfter)
+}
+
+// Is reports whether the target is ErrThrottled
+func (e *ThrottledError) Is(target error) bool {
+	return target == ErrThrottled
+}
+
+// Throttle limits attempts of hotp, totp and recovery code verification of each account
+type Throttle struct {
+	// limiter limits attempts of each account
+	limiter Limiter
+}
+
+// NewThrottle generates a throttle by passing limiter
+func NewThrottle(l Limiter) (*Throttle, error) {
+	if l == nil {
+		return nil, ErrLimiterIsNil
+	}
+
+	return &Throttle{
+		limiter: l,
+	}, nil
+}
+
+// Verify calls verify when the attempt of the key is allowed, and records the result
+// This returns ThrottledError without calling verify when the attempt isn't allowed
+// An error returned by verify isn't recorded as a failure
+func (th *Throttle) Verify(ctx context.Context, key string, t time.Time, verify func() (bool, error)) (bool, error) {
+	if th == nil {
+		return false, ErrThrottleIsNil
+	}
+
+	allowed, retryAfter, err := th.limiter.Allow(ctx, key, t)
+	if err != nil {
+		return false, err
+	}
+	if !allowed {
+		return false, &ThrottledError{RetryAfter: retryAfter}
+	}
+
+	ok, err := verify()
+	if err != nil {
+		return false, err
+	}
+	if !ok {
+		return false, th.limiter.Fail(ctx, key, t)
+	}
+
+	return true, th.limiter.Reset(ctx, key)
+}
+
+// ValidateTOTP validates a Time-based One Time Password of the account with throttling
+// A passcode that has invalid length is recorded as a failure
+func (th *Throttle) ValidateTOTP(ctx context.Context, key, passcode, secret string, t time.Time, opt *totp.Option) (bool, error) {
+	return th.Verify(ctx, key, t, func() (bool, error) {
+		return ignoreDigitsLength(totp.ValidateWithOption(passcode, secret, t, opt))
+	})
+}
+
+// ValidateHOTP validates a HMAC-based One Time Password of the account with throttling
+// A passcode that has invalid length is recorded as a failure
+func (th *Throttle) ValidateHOTP(ctx context.Context, key, passcode, secret string, counter uint64, t time.Time, opt *hotp.Option) (bool, error) {
+	return th.Verify(ctx, key, t, func() (bool, error) {
+		return ignoreDigitsLength(hotp.ValidateWithOption(passcode, secret, counter, opt))
+	})
+}
+
+// RedeemRecoveryCode redeems the recovery code of the user with throttling
+// A mistyped, consumed or invalid code is recorded as a failure
+func (th *Throttle) RedeemRecoveryCode(ctx context.Context, key string, m *recovery.Manager, userID, code string, t time.Time) error {
+	var redeemErr error
+	ok, err := th.Verify(ctx, key, t, func() (bool, error) {
+		redeemErr = m.Redeem(ctx, userID, code)
+		switch redeemErr {
+		case nil:
+			return true, nil
+		case recovery.ErrInvalidFormat, recovery.ErrChecksumMismatch, recovery.ErrInvalidRecoveryCode, recovery.ErrRecoveryCodeConsumed:
+			return false, nil
+		}
+		return false, redeemErr
+	})
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return redeemErr
+	}
+
+	return nil
+}
+
+func ignoreDigitsLength(ok bool, err error) (bool, error) {
+	if err == otpauth.ErrInvalidDigitsLength {
+		return false, nil
+	}
+
+	return ok, err
+}
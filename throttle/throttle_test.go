@@ -0,0 +1,106 @@
+package throttle_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/butterv/one-time-password/hotp"
+	"github.com/butterv/one-time-password/recovery"
+	"github.com/butterv/one-time-password/throttle"
+	"github.com/butterv/one-time-password/totp"
+)
+
+const secret = "3EOJMVMDTXHMHFQ3CK45R6NWIG4VWAQA"
+
+func newThrottle(t *testing.T, burst uint) *throttle.Throttle {
+	t.Helper()
+
+	l := throttle.NewMemoryLimiter()
+	_ = l.SetBucket(burst, time.Minute)
+	th, err := throttle.NewThrottle(l)
+	if err != nil {
+		t.Fatalf("NewThrottle()=_, %#v; want nil", err)
+	}
+
+	return th
+}
+
+func TestThrottle_ValidateTOTP(t *testing.T) {
+	ctx := context.Background()
+	th := newThrottle(t, 2)
+	passcode, _ := hotp.GeneratePasscode(secret, uint64(now.Unix()/30))
+
+	for _, p := range []string{"000000", "00000"} {
+		ok, err := th.ValidateTOTP(ctx, "user", p, secret, now, totp.NewOption())
+		if ok || err != nil {
+			t.Errorf("ValidateTOTP(%s)=%t, %#v; want false, nil", p, ok, err)
+		}
+	}
+
+	_, err := th.ValidateTOTP(ctx, "user", passcode, secret, now, totp.NewOption())
+	var te *throttle.ThrottledError
+	if !errors.As(err, &te) || te.RetryAfter != time.Minute {
+		t.Fatalf("ValidateTOTP()=_, %#v; want ThrottledError retry after 1m", err)
+	}
+	if !errors.Is(err, throttle.ErrThrottled) {
+		t.Errorf("errors.Is(%#v, ErrThrottled)=false; want true", err)
+	}
+
+	later := now.Add(time.Minute)
+	passcode, _ = hotp.GeneratePasscode(secret, uint64(later.Unix()/30))
+	ok, err := th.ValidateTOTP(ctx, "user", passcode, secret, later, totp.NewOption())
+	if !ok || err != nil {
+		t.Errorf("ValidateTOTP(%s)=%t, %#v; want true, nil", passcode, ok, err)
+	}
+}
+
+func TestThrottle_ValidateHOTP(t *testing.T) {
+	ctx := context.Background()
+	th := newThrottle(t, 1)
+	passcode, _ := hotp.GeneratePasscode(secret, 7)
+
+	ok, err := th.ValidateHOTP(ctx, "user", passcode, secret, 7, now, hotp.NewOption())
+	if !ok || err != nil {
+		t.Fatalf("ValidateHOTP(%s)=%t, %#v; want true, nil", passcode, ok, err)
+	}
+	// the success resets the bucket
+	ok, err = th.ValidateHOTP(ctx, "user", passcode, secret, 7, now, hotp.NewOption())
+	if !ok || err != nil {
+		t.Errorf("ValidateHOTP(%s)=%t, %#v; want true, nil", passcode, ok, err)
+	}
+}
+
+func TestThrottle_RedeemRecoveryCode(t *testing.T) {
+	ctx := context.Background()
+	th := newThrottle(t, 2)
+
+	hashOpt := recovery.NewHashOption()
+	_ = hashOpt.SetKDF(recovery.KDFPBKDF2)
+	_ = hashOpt.SetPBKDF2Iterations(1000)
+	m, _ := recovery.NewManager(recovery.NewMemoryStore(), recovery.NewOption(), hashOpt)
+	codes, _ := m.Issue(ctx, "user")
+
+	if err := th.RedeemRecoveryCode(ctx, "user", m, "user", "invalid0", now); err != recovery.ErrInvalidRecoveryCode {
+		t.Errorf("RedeemRecoveryCode(invalid0)=%#v; want %v", err, recovery.ErrInvalidRecoveryCode)
+	}
+	if err := th.RedeemRecoveryCode(ctx, "user", m, "user", codes[0], now); err != nil {
+		t.Errorf("RedeemRecoveryCode(%s)=%#v; want nil", codes[0], err)
+	}
+}
+
+func TestThrottle_Verify_Error(t *testing.T) {
+	ctx := context.Background()
+	th := newThrottle(t, 1)
+	want := errors.New("store is unavailable")
+
+	if _, err := th.Verify(ctx, "user", now, func() (bool, error) { return false, want }); err != want {
+		t.Errorf("Verify()=_, %#v; want %v", err, want)
+	}
+
+	var nilThrottle *throttle.Throttle
+	if _, err := nilThrottle.Verify(ctx, "user", now, nil); err != throttle.ErrThrottleIsNil {
+		t.Errorf("Verify()=_, %#v; want %v", err, throttle.ErrThrottleIsNil)
+	}
+}
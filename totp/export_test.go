@@ -40,5 +40,6 @@ func DefaultOption() *Option {
 		skew:      1,
 		digits:    6,
 		algorithm: 0,
+		policy:    otpauth.NewPolicy(),
 	}
 }
@@ -29,6 +29,9 @@ type Option struct {
 	// algorithm is the hash function to use in the HMAC operation
 	// The default value is SHA1
 	algorithm otpauth.Algorithm
+	// policy validates the strength of the option
+	// The default value flags weak configurations
+	policy *otpauth.Policy
 }
 
 // SetPeriod sets a period that Time-based One Time Password hash is valid
@@ -39,6 +42,9 @@ func (opt *Option) SetPeriod(period uint) error {
 	if period == 0 {
 		return errors.New("invalid period. please pass greater than 0")
 	}
+	if err := opt.policy.Enforce(opt.policy.CheckPeriod(period)); err != nil {
+		return err
+	}
 
 	opt.period = period
 	return nil
@@ -78,11 +84,44 @@ func (opt *Option) SetAlgorithm(a otpauth.Algorithm) error {
 	if !a.Enabled() {
 		return fmt.Errorf("invalid algorithm. please pass any of %d to %d", otpauth.AlgorithmSHA1, otpauth.AlgorithmMD5)
 	}
+	if err := opt.policy.Enforce(opt.policy.CheckAlgorithm(a)); err != nil {
+		return err
+	}
 
 	opt.algorithm = a
 	return nil
 }
 
+// SetPolicy sets a policy that validates the strength of the option
+// Passing nil disables the validation
+func (opt *Option) SetPolicy(p *otpauth.Policy) error {
+	if opt == nil {
+		return ErrTOTPOptionIsNil
+	}
+	if err := p.Enforce(opt.warnings(p)); err != nil {
+		return err
+	}
+
+	opt.policy = p
+	return nil
+}
+
+// Warnings returns weak configurations of the option flagged by the policy
+func (opt *Option) Warnings() []otpauth.Warning {
+	if opt == nil {
+		return nil
+	}
+
+	return opt.warnings(opt.policy)
+}
+
+func (opt *Option) warnings(p *otpauth.Policy) []otpauth.Warning {
+	var warnings []otpauth.Warning
+	warnings = append(warnings, p.CheckAlgorithm(opt.algorithm)...)
+	warnings = append(warnings, p.CheckPeriod(opt.period)...)
+	return warnings
+}
+
 // NewOption generates an option with default values
 func NewOption() *Option {
 	return &Option{
@@ -90,5 +129,6 @@ func NewOption() *Option {
 		skew:      defaultSkew,
 		digits:    otpauth.DigitsSix,
 		algorithm: otpauth.AlgorithmSHA1,
+		policy:    otpauth.NewPolicy(),
 	}
 }
@@ -7,6 +7,23 @@ import (
 	"github.com/butterv/one-time-password/hotp"
 )
 
+// GeneratePasscode generates a passcode of the time with using default value of option
+func GeneratePasscode(secret string, t time.Time) (string, error) {
+	opt := NewOption()
+	return GeneratePasscodeWithOption(secret, t, opt)
+}
+
+// GeneratePasscodeWithOption generates a passcode of the time
+// This function can pass custom value of option
+// See: https://tools.ietf.org/html/rfc6238#section-4.2
+func GeneratePasscodeWithOption(secret string, t time.Time, opt *Option) (string, error) {
+	if opt == nil {
+		return "", ErrTOTPOptionIsNil
+	}
+
+	return hotp.GeneratePasscodeWithOption(secret, opt.counter(t), opt.hotpOption())
+}
+
 // Validate validates a Time-based One Time Password with using default value of option
 func Validate(passcode, secret string, t time.Time) (bool, error) {
 	opt := NewOption()
@@ -17,11 +34,15 @@ func Validate(passcode, secret string, t time.Time) (bool, error) {
 // This function can pass custom value of option
 // See: https://tools.ietf.org/html/rfc6238#section-4.2
 func ValidateWithOption(passcode, secret string, t time.Time, opt *Option) (bool, error) {
-	hotpOpt := hotp.NewOption()
-	_ = hotpOpt.SetDigits(opt.digits)
-	_ = hotpOpt.SetAlgorithm(opt.algorithm)
+	_, ok, err := ValidateStepWithOption(passcode, secret, t, opt)
+	return ok, err
+}
 
-	c := uint64(math.Floor(float64(t.Unix()) / float64(opt.period)))
+// ValidateStepWithOption validates a Time-based One Time Password and returns the time step that the passcode matches
+// The time step lets the caller reject a passcode of the same or older step that has already been used
+func ValidateStepWithOption(passcode, secret string, t time.Time, opt *Option) (uint64, bool, error) {
+	hotpOpt := opt.hotpOption()
+	c := opt.counter(t)
 
 	var cs []uint64
 	cs = append(cs, c)
@@ -34,12 +55,25 @@ func ValidateWithOption(passcode, secret string, t time.Time, opt *Option) (bool
 	for _, c := range cs {
 		ok, err := hotp.ValidateWithOption(passcode, secret, c, hotpOpt)
 		if err != nil {
-			return false, err
+			return 0, false, err
 		}
 		if ok {
-			return true, nil
+			return c, true, nil
 		}
 	}
 
-	return false, nil
+	return 0, false, nil
+}
+
+// counter returns the time step of the time
+func (opt *Option) counter(t time.Time) uint64 {
+	return uint64(math.Floor(float64(t.Unix()) / float64(opt.period)))
+}
+
+// hotpOption returns the option of HMAC-based One Time Password that has the same digits and algorithm
+func (opt *Option) hotpOption() *hotp.Option {
+	hotpOpt := hotp.NewOption()
+	_ = hotpOpt.SetDigits(opt.digits)
+	_ = hotpOpt.SetAlgorithm(opt.algorithm)
+	return hotpOpt
 }
@@ -67,3 +67,74 @@ func TestValidateWithOption_False(t *testing.T) {
 		t.Errorf("ValidateWithOption(%s, %s, %v, %v)=%v, _; want true", passcode, secret, ti, o, got)
 	}
 }
+
+func TestValidateStepWithOption(t *testing.T) {
+	ti := time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC)
+	want := uint64(ti.Unix() / 30)
+
+	got, ok, err := totp.ValidateStepWithOption("662024", secret, ti, totp.NewOption())
+	if err != nil {
+		t.Fatalf("ValidateStepWithOption(662024, %s, %v)=_, _, %#v; want nil", secret, ti, err)
+	}
+	if !ok || got != want {
+		t.Errorf("ValidateStepWithOption(662024, %s, %v)=%d, %t, _; want %d, true", secret, ti, got, ok, want)
+	}
+
+	// the passcode of the previous step is accepted by skew
+	next := ti.Add(30 * time.Second)
+	got, ok, _ = totp.ValidateStepWithOption("662024", secret, next, totp.NewOption())
+	if !ok || got != want {
+		t.Errorf("ValidateStepWithOption(662024, %s, %v)=%d, %t, _; want %d, true", secret, next, got, ok, want)
+	}
+}
+
+func TestGeneratePasscode(t *testing.T) {
+	want := "662024"
+	ti := time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC)
+	got, err := totp.GeneratePasscode(secret, ti)
+	if err != nil {
+		t.Fatalf("GeneratePasscode(%s, %v)=_, %#v; want nil", secret, ti, err)
+	}
+	if got != want {
+		t.Errorf("GeneratePasscode(%s, %v)=%s, _; want %s", secret, ti, got, want)
+	}
+}
+
+func TestGeneratePasscodeWithOption(t *testing.T) {
+	// See: https://tools.ietf.org/html/rfc6238#appendix-B
+	rfcSecret := "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{unix: 59, want: "94287082"},
+		{unix: 1111111109, want: "07081804"},
+		{unix: 1234567890, want: "89005924"},
+		{unix: 20000000000, want: "65353130"},
+	}
+
+	o := totp.NewOption()
+	_ = o.SetDigits(otpauth.DigitsEight)
+
+	for _, tt := range tests {
+		ti := time.Unix(tt.unix, 0)
+		got, err := totp.GeneratePasscodeWithOption(rfcSecret, ti, o)
+		if err != nil {
+			t.Fatalf("GeneratePasscodeWithOption(%s, %v, %v)=_, %#v; want nil", rfcSecret, ti, o, err)
+		}
+		if got != tt.want {
+			t.Errorf("GeneratePasscodeWithOption(%s, %v, %v)=%s, _; want %s", rfcSecret, ti, o, got, tt.want)
+		}
+		ok, _ := totp.ValidateWithOption(got, rfcSecret, ti, o)
+		if !ok {
+			t.Errorf("ValidateWithOption(%s, %s, %v, %v)=false; want true", got, rfcSecret, ti, o)
+		}
+	}
+}
+
+func TestGeneratePasscodeWithOption_OptionIsNil(t *testing.T) {
+	_, err := totp.GeneratePasscodeWithOption(secret, time.Now(), nil)
+	if err != totp.ErrTOTPOptionIsNil {
+		t.Errorf("GeneratePasscodeWithOption(%s, _, nil)=_, %#v; want %v", secret, err, totp.ErrTOTPOptionIsNil)
+	}
+}
@@ -0,0 +1,13 @@
+package vault
+
+func (opt *Option) KDF() KDF {
+	return opt.kdf
+}
+
+func (opt *Option) ScryptParams() (int, int, int) {
+	return opt.scryptN, opt.scryptR, opt.scryptP
+}
+
+func (opt *Option) Argon2Params() (uint32, uint32, uint8) {
+	return opt.argon2Time, opt.argon2Memory, opt.argon2Threads
+}
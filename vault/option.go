@@ -0,0 +1,121 @@
+package vault
+
+import (
+	crand "crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const (
+	saltSize = 16
+	keySize  = 32
+
+	defaultScryptN       = 1 << 15
+	defaultScryptR       = 8
+	defaultScryptP       = 1
+	defaultArgon2Time    = 3
+	defaultArgon2Memory  = 64 * 1024
+	defaultArgon2Threads = 4
+)
+
+// ErrVaultOptionIsNil is an error when the vault option is nil
+var ErrVaultOptionIsNil = errors.New("vault option is nil")
+
+// KDF is the key derivation function that derives the encryption key from the passphrase
+type KDF int
+
+const (
+	// KDFArgon2id is the key derivation function of Argon2id
+	// See: https://tools.ietf.org/html/rfc9106
+	KDFArgon2id KDF = iota
+	// KDFScrypt is the key derivation function of scrypt
+	// See: https://tools.ietf.org/html/rfc7914
+	KDFScrypt
+)
+
+// String returns the name of the key derivation function in the vault file
+func (k KDF) String() string {
+	switch k {
+	case KDFArgon2id:
+		return "argon2id"
+	case KDFScrypt:
+		return "scrypt"
+	}
+
+	return "unknown"
+}
+
+func (k KDF) enabled() bool {
+	return k >= KDFArgon2id && k <= KDFScrypt
+}
+
+// Option is used when creates a vault
+type Option struct {
+	// kdf is the key derivation function
+	// The default value is Argon2id
+	kdf KDF
+	// scryptN, scryptR and scryptP are the parameters of scrypt
+	scryptN, scryptR, scryptP int
+	// argon2Time, argon2Memory and argon2Threads are the parameters of Argon2id
+	argon2Time, argon2Memory uint32
+	argon2Threads            uint8
+	// rand is the reader to use for generating salt and nonce
+	rand io.Reader
+}
+
+// SetKDF sets the key derivation function
+func (opt *Option) SetKDF(k KDF) error {
+	if opt == nil {
+		return ErrVaultOptionIsNil
+	}
+	if !k.enabled() {
+		return fmt.Errorf("invalid kdf. please pass any of %d to %d", KDFArgon2id, KDFScrypt)
+	}
+
+	opt.kdf = k
+	return nil
+}
+
+// SetScryptParams sets the CPU/memory cost N, the block size r and the parallelization p of scrypt
+func (opt *Option) SetScryptParams(n, r, p int) error {
+	if opt == nil {
+		return ErrVaultOptionIsNil
+	}
+	if n <= 1 || n&(n-1) != 0 {
+		return errors.New("invalid scrypt N. please pass a power of 2 greater than 1")
+	}
+	if r <= 0 || p <= 0 {
+		return errors.New("invalid scrypt r or p. please pass greater than 0")
+	}
+
+	opt.scryptN, opt.scryptR, opt.scryptP = n, r, p
+	return nil
+}
+
+// SetArgon2Params sets the time, the memory in KiB and the threads of Argon2id
+func (opt *Option) SetArgon2Params(time, memory uint32, threads uint8) error {
+	if opt == nil {
+		return ErrVaultOptionIsNil
+	}
+	if time == 0 || memory == 0 || threads == 0 {
+		return errors.New("invalid argon2 params. please pass greater than 0")
+	}
+
+	opt.argon2Time, opt.argon2Memory, opt.argon2Threads = time, memory, threads
+	return nil
+}
+
+// NewOption generates an option with default values
+func NewOption() *Option {
+	return &Option{
+		kdf:           KDFArgon2id,
+		scryptN:       defaultScryptN,
+		scryptR:       defaultScryptR,
+		scryptP:       defaultScryptP,
+		argon2Time:    defaultArgon2Time,
+		argon2Memory:  defaultArgon2Memory,
+		argon2Threads: defaultArgon2Threads,
+		rand:          crand.Reader,
+	}
+}
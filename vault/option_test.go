@@ -0,0 +1,77 @@
+package vault_test
+
+import (
+	"testing"
+
+	"github.com/butterv/one-time-password/vault"
+)
+
+func TestNewOption(t *testing.T) {
+	o := vault.NewOption()
+	if o.KDF() != vault.KDFArgon2id {
+		t.Errorf("NewOption() has kdf %v; want %v", o.KDF(), vault.KDFArgon2id)
+	}
+	if ti, m, p := o.Argon2Params(); ti != 3 || m != 64*1024 || p != 4 {
+		t.Errorf("NewOption() has argon2 params %d, %d, %d; want 3, 65536, 4", ti, m, p)
+	}
+	if n, r, p := o.ScryptParams(); n != 1<<15 || r != 8 || p != 1 {
+		t.Errorf("NewOption() has scrypt params %d, %d, %d; want 32768, 8, 1", n, r, p)
+	}
+}
+
+func TestOption_SetKDF(t *testing.T) {
+	o := vault.NewOption()
+	if err := o.SetKDF(vault.KDFScrypt); err != nil || o.KDF() != vault.KDFScrypt {
+		t.Errorf("SetKDF(%v)=%#v; want nil", vault.KDFScrypt, err)
+	}
+	if err := o.SetKDF(vault.KDF(2)); err == nil {
+		t.Error("SetKDF(2)=nil; want error")
+	}
+
+	var nilOpt *vault.Option
+	if err := nilOpt.SetKDF(vault.KDFScrypt); err != vault.ErrVaultOptionIsNil {
+		t.Errorf("SetKDF(%v)=%#v; want %v", vault.KDFScrypt, err, vault.ErrVaultOptionIsNil)
+	}
+}
+
+func TestOption_SetScryptParams(t *testing.T) {
+	tests := []struct {
+		n, r, p int
+		wantErr bool
+	}{
+		{n: 1 << 10, r: 8, p: 1, wantErr: false},
+		{n: 1000, r: 8, p: 1, wantErr: true},
+		{n: 1, r: 8, p: 1, wantErr: true},
+		{n: 1 << 10, r: 0, p: 1, wantErr: true},
+		{n: 1 << 10, r: 8, p: 0, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		o := vault.NewOption()
+		if err := o.SetScryptParams(tt.n, tt.r, tt.p); (err != nil) != tt.wantErr {
+			t.Errorf("SetScryptParams(%d, %d, %d)=%#v; want error %t", tt.n, tt.r, tt.p, err, tt.wantErr)
+		}
+	}
+}
+
+func TestOption_SetArgon2Params(t *testing.T) {
+	o := vault.NewOption()
+	if err := o.SetArgon2Params(1, 1024, 1); err != nil {
+		t.Errorf("SetArgon2Params(1, 1024, 1)=%#v; want nil", err)
+	}
+	if ti, m, p := o.Argon2Params(); ti != 1 || m != 1024 || p != 1 {
+		t.Errorf("SetArgon2Params(1, 1024, 1) sets %d, %d, %d", ti, m, p)
+	}
+	if err := o.SetArgon2Params(1, 0, 1); err == nil {
+		t.Error("SetArgon2Params(1, 0, 1)=nil; want error")
+	}
+}
+
+func TestKDF_String(t *testing.T) {
+	if got := vault.KDFArgon2id.String(); got != "argon2id" {
+		t.Errorf("KDFArgon2id.String()=%s; want argon2id", got)
+	}
+	if got := vault.KDFScrypt.String(); got != "scrypt" {
+		t.Errorf("KDFScrypt.String()=%s; want scrypt", got)
+	}
+}
@@ -0,0 +1,452 @@
+package vault
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	crand "crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/scrypt"
+
+	"github.com/butterv/one-time-password/hotp"
+	"github.com/butterv/one-time-password/otpauth"
+	"github.com/butterv/one-time-password/totp"
+)
+
+// fileVersion is the current version of the vault file format
+const fileVersion = 1
+
+// ErrVaultIsNil is an error when the vault is nil
+var ErrVaultIsNil = errors.New("vault is nil")
+
+// ErrVaultExists is an error when the vault file already exists
+var ErrVaultExists = errors.New("vault already exists")
+
+// ErrInvalidVault is an error when the vault file is malformed
+var ErrInvalidVault = errors.New("invalid vault")
+
+// ErrUnsupportedVersion is an error when the version of the vault file isn't supported
+var ErrUnsupportedVersion = errors.New("unsupported vault version")
+
+// ErrInvalidPassphrase is an error when the passphrase doesn't decrypt the vault
+var ErrInvalidPassphrase = errors.New("invalid passphrase")
+
+// ErrEntryNotFound is an error when the vault doesn't have the entry
+var ErrEntryNotFound = errors.New("entry not found")
+
+// ErrEntryExists is an error when the vault already has an entry of the name
+var ErrEntryExists = errors.New("entry already exists")
+
+// Entry is a key in the vault
+type Entry struct {
+	// Name identifies the entry in the vault
+	Name string
+	// Key is the parameters of the one time password
+	Key otpauth.Key
+	// AddedAt is the time when the entry is added
+	AddedAt time.Time
+}
+
+// Vault is a local file of keys encrypted with a passphrase
+// The encryption key is derived from the passphrase with Argon2id or scrypt, and the keys are encrypted with AES-256-GCM
+type Vault struct {
+	// path is the path of the vault file
+	path string
+	// kdf is the parameters of the key derivation function with the salt
+	kdf kdfParams
+	// key is the encryption key derived from the passphrase
+	key []byte
+	// rand is the reader to use for generating nonce
+	rand io.Reader
+	// entries is the entries sorted by name
+	entries []*Entry
+}
+
+// file is the vault file
+// The version and the parameters of the key derivation function are authenticated as the additional data
+type file struct {
+	Version    int       `json:"version"`
+	KDF        kdfParams `json:"kdf"`
+	Nonce      []byte    `json:"nonce"`
+	Ciphertext []byte    `json:"ciphertext"`
+}
+
+type kdfParams struct {
+	Name    string `json:"name"`
+	Salt    []byte `json:"salt"`
+	N       int    `json:"n,omitempty"`
+	R       int    `json:"r,omitempty"`
+	P       int    `json:"p,omitempty"`
+	Time    uint32 `json:"time,omitempty"`
+	Memory  uint32 `json:"memory,omitempty"`
+	Threads uint8  `json:"threads,omitempty"`
+}
+
+// payload is the plaintext of the vault
+type payload struct {
+	Entries []entryJSON `json:"entries"`
+}
+
+type entryJSON struct {
+	Name    string    `json:"name"`
+	URI     string    `json:"uri"`
+	AddedAt time.Time `json:"added_at"`
+}
+
+// Create creates an empty vault file encrypted with the passphrase
+// This returns ErrVaultExists when the file already exists
+func Create(path string, passphrase []byte, opt *Option) (*Vault, error) {
+	if opt == nil {
+		return nil, ErrVaultOptionIsNil
+	}
+	if len(passphrase) == 0 {
+		return nil, errors.New("passphrase is empty")
+	}
+
+	salt := make([]byte, saltSize)
+	if _, err := io.ReadFull(opt.rand, salt); err != nil {
+		return nil, err
+	}
+	params := kdfParams{Name: opt.kdf.String(), Salt: salt}
+	switch opt.kdf {
+	case KDFArgon2id:
+		params.Time, params.Memory, params.Threads = opt.argon2Time, opt.argon2Memory, opt.argon2Threads
+	case KDFScrypt:
+		params.N, params.R, params.P = opt.scryptN, opt.scryptR, opt.scryptP
+	}
+	key, err := params.derive(passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if os.IsExist(err) {
+		return nil, ErrVaultExists
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := f.Close(); err != nil {
+		return nil, err
+	}
+
+	v := &Vault{
+		path: path,
+		kdf:  params,
+		key:  key,
+		rand: opt.rand,
+	}
+	if err := v.Save(); err != nil {
+		_ = os.Remove(path)
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// Open opens the vault file with the passphrase
+// This returns ErrInvalidPassphrase when the passphrase doesn't decrypt the vault
+func Open(path string, passphrase []byte) (*Vault, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var f file
+	if err := json.Unmarshal(b, &f); err != nil {
+		return nil, ErrInvalidVault
+	}
+	if f.Version != fileVersion {
+		return nil, ErrUnsupportedVersion
+	}
+
+	key, err := f.KDF.derive(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(f.Nonce) != aead.NonceSize() {
+		return nil, ErrInvalidVault
+	}
+	plaintext, err := aead.Open(nil, f.Nonce, f.Ciphertext, f.additionalData())
+	if err != nil {
+		return nil, ErrInvalidPassphrase
+	}
+
+	var p payload
+	if err := json.Unmarshal(plaintext, &p); err != nil {
+		return nil, ErrInvalidVault
+	}
+
+	v := &Vault{
+		path: path,
+		kdf:  f.KDF,
+		key:  key,
+		rand: crand.Reader,
+	}
+	for _, e := range p.Entries {
+		k, err := otpauth.ParseURL(e.URI)
+		if err != nil {
+			return nil, fmt.Errorf("invalid entry %q: %w", e.Name, err)
+		}
+		v.entries = append(v.entries, &Entry{Name: e.Name, Key: *k, AddedAt: e.AddedAt})
+	}
+	v.sort()
+
+	return v, nil
+}
+
+// Save encrypts the entries and writes them to the vault file
+// The file is replaced atomically, so a failure never leaves a partial vault
+func (v *Vault) Save() error {
+	if v == nil {
+		return ErrVaultIsNil
+	}
+
+	p := payload{Entries: make([]entryJSON, 0, len(v.entries))}
+	for _, e := range v.entries {
+		uri, err := e.Key.URL()
+		if err != nil {
+			return fmt.Errorf("invalid entry %q: %w", e.Name, err)
+		}
+		p.Entries = append(p.Entries, entryJSON{Name: e.Name, URI: uri, AddedAt: e.AddedAt})
+	}
+	plaintext, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+
+	aead, err := newAEAD(v.key)
+	if err != nil {
+		return err
+	}
+	f := file{Version: fileVersion, KDF: v.kdf, Nonce: make([]byte, aead.NonceSize())}
+	if _, err := io.ReadFull(v.rand, f.Nonce); err != nil {
+		return err
+	}
+	f.Ciphertext = aead.Seal(nil, f.Nonce, plaintext, f.additionalData())
+
+	b, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return writeFile(v.path, b)
+}
+
+// Entries returns the entries sorted by name
+func (v *Vault) Entries() []Entry {
+	if v == nil {
+		return nil
+	}
+
+	es := make([]Entry, 0, len(v.entries))
+	for _, e := range v.entries {
+		es = append(es, *e)
+	}
+	return es
+}
+
+// Entry returns the entry of the name
+func (v *Vault) Entry(name string) (Entry, error) {
+	if v == nil {
+		return Entry{}, ErrVaultIsNil
+	}
+
+	e, err := v.find(name)
+	if err != nil {
+		return Entry{}, err
+	}
+	return *e, nil
+}
+
+// Add adds the key to the vault with the name
+// When the name is empty, the label of the key like `issuer:accountName` is used
+// Call Save to write the vault file
+func (v *Vault) Add(name string, key *otpauth.Key, t time.Time) (Entry, error) {
+	if v == nil {
+		return Entry{}, ErrVaultIsNil
+	}
+	if key == nil {
+		return Entry{}, otpauth.ErrKeyIsNil
+	}
+	if _, err := key.URL(); err != nil {
+		return Entry{}, err
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = key.AccountName
+		if key.Issuer != "" {
+			name = key.Issuer + ":" + name
+		}
+	}
+	if _, err := v.find(name); err == nil {
+		return Entry{}, ErrEntryExists
+	}
+
+	e := &Entry{Name: name, Key: *key, AddedAt: t}
+	v.entries = append(v.entries, e)
+	v.sort()
+
+	return *e, nil
+}
+
+// Remove removes the entry of the name
+// Call Save to write the vault file
+func (v *Vault) Remove(name string) error {
+	if v == nil {
+		return ErrVaultIsNil
+	}
+
+	for i, e := range v.entries {
+		if e.Name == name {
+			v.entries = append(v.entries[:i], v.entries[i+1:]...)
+			return nil
+		}
+	}
+
+	return ErrEntryNotFound
+}
+
+// Code generates the passcode of the entry at the time
+// The counter of a HMAC-based One Time Password is incremented, so call Save to write the vault file
+func (v *Vault) Code(name string, t time.Time) (string, error) {
+	if v == nil {
+		return "", ErrVaultIsNil
+	}
+
+	e, err := v.find(name)
+	if err != nil {
+		return "", err
+	}
+
+	// hotp and totp decode the secret with padding
+	secret := e.Key.Secret
+	if n := len(secret) % 8; n != 0 {
+		secret += strings.Repeat("=", 8-n)
+	}
+
+	switch e.Key.Host {
+	case otpauth.HostHOTP:
+		opt := hotp.NewOption()
+		if err := opt.SetDigits(e.Key.Digits); err != nil {
+			return "", err
+		}
+		if err := opt.SetAlgorithm(e.Key.Algorithm); err != nil {
+			return "", err
+		}
+		code, err := hotp.GeneratePasscodeWithOption(secret, e.Key.Counter, opt)
+		if err != nil {
+			return "", err
+		}
+		e.Key.Counter++
+		return code, nil
+	case otpauth.HostTOTP:
+		opt := totp.NewOption()
+		if err := opt.SetDigits(e.Key.Digits); err != nil {
+			return "", err
+		}
+		if err := opt.SetAlgorithm(e.Key.Algorithm); err != nil {
+			return "", err
+		}
+		if err := opt.SetPeriod(e.Key.Period); err != nil {
+			return "", err
+		}
+		return totp.GeneratePasscodeWithOption(secret, t, opt)
+	}
+
+	return "", fmt.Errorf("invalid host of entry %q", name)
+}
+
+func (v *Vault) find(name string) (*Entry, error) {
+	for _, e := range v.entries {
+		if e.Name == name {
+			return e, nil
+		}
+	}
+
+	return nil, ErrEntryNotFound
+}
+
+func (v *Vault) sort() {
+	sort.Slice(v.entries, func(i, j int) bool {
+		return v.entries[i].Name < v.entries[j].Name
+	})
+}
+
+// additionalData returns the authenticated header of the file
+func (f *file) additionalData() []byte {
+	b, _ := json.Marshal(struct {
+		Version int       `json:"version"`
+		KDF     kdfParams `json:"kdf"`
+	}{f.Version, f.KDF})
+	return b
+}
+
+// derive derives the encryption key from the passphrase
+func (p *kdfParams) derive(passphrase []byte) ([]byte, error) {
+	if len(p.Salt) == 0 {
+		return nil, ErrInvalidVault
+	}
+
+	switch p.Name {
+	case KDFArgon2id.String():
+		if p.Time == 0 || p.Memory == 0 || p.Threads == 0 {
+			return nil, ErrInvalidVault
+		}
+		return argon2.IDKey(passphrase, p.Salt, p.Time, p.Memory, p.Threads, keySize), nil
+	case KDFScrypt.String():
+		if p.N <= 1 || p.N&(p.N-1) != 0 || p.R <= 0 || p.P <= 0 || uint64(p.R)*uint64(p.P) >= 1<<30 {
+			return nil, ErrInvalidVault
+		}
+		return scrypt.Key(passphrase, p.Salt, p.N, p.R, p.P, keySize)
+	}
+
+	return nil, ErrInvalidVault
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// writeFile writes the data to a temporary file in the same directory and renames it to the path
+func writeFile(path string, b []byte) error {
+	f, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(b); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), path)
+}
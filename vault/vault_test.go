@@ -0,0 +1,242 @@
+package vault_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/butterv/one-time-password/otpauth"
+	"github.com/butterv/one-time-password/vault"
+)
+
+const (
+	rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+	totpURI   = "otpauth://totp/butter:butter@example.com?secret=" + rfcSecret + "&digits=8"
+	hotpURI   = "otpauth://hotp/butter:deploy?secret=" + rfcSecret + "&counter=0"
+)
+
+var passphrase = []byte("correct horse battery staple")
+
+// fastOption returns an option with cheap parameters of the key derivation function for tests
+func fastOption(t *testing.T, k vault.KDF) *vault.Option {
+	t.Helper()
+
+	o := vault.NewOption()
+	_ = o.SetKDF(k)
+	_ = o.SetArgon2Params(1, 1024, 1)
+	_ = o.SetScryptParams(1<<4, 8, 1)
+	return o
+}
+
+func mustParseURL(t *testing.T, rawURL string) *otpauth.Key {
+	t.Helper()
+
+	k, err := otpauth.ParseURL(rawURL)
+	if err != nil {
+		t.Fatalf("ParseURL(%s)=_, %#v; want nil", rawURL, err)
+	}
+	return k
+}
+
+func TestCreate_Open(t *testing.T) {
+	for _, k := range []vault.KDF{vault.KDFArgon2id, vault.KDFScrypt} {
+		path := filepath.Join(t.TempDir(), "vault.json")
+		addedAt := time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC)
+
+		v, err := vault.Create(path, passphrase, fastOption(t, k))
+		if err != nil {
+			t.Fatalf("Create(%s, _, %v)=_, %#v; want nil", path, k, err)
+		}
+		if _, err := v.Add("", mustParseURL(t, totpURI), addedAt); err != nil {
+			t.Fatalf("Add(_, %s, _)=_, %#v; want nil", totpURI, err)
+		}
+		if _, err := v.Add("deploy", mustParseURL(t, hotpURI), addedAt); err != nil {
+			t.Fatalf("Add(deploy, %s, _)=_, %#v; want nil", hotpURI, err)
+		}
+		if err := v.Save(); err != nil {
+			t.Fatalf("Save()=%#v; want nil", err)
+		}
+
+		b, _ := ioutil.ReadFile(path)
+		if bytes.Contains(b, []byte(rfcSecret)) || bytes.Contains(b, []byte("butter@example.com")) {
+			t.Errorf("vault file %s has the keys in plaintext", b)
+		}
+		if fi, _ := os.Stat(path); fi.Mode().Perm() != 0600 {
+			t.Errorf("vault file has mode %v; want 0600", fi.Mode().Perm())
+		}
+
+		got, err := vault.Open(path, passphrase)
+		if err != nil {
+			t.Fatalf("Open(%s, _)=_, %#v; want nil", path, err)
+		}
+		es := got.Entries()
+		if len(es) != 2 {
+			t.Fatalf("Entries() has %d entries; want 2", len(es))
+		}
+		if es[0].Name != "butter:butter@example.com" || es[0].Key.Digits != otpauth.DigitsEight || !es[0].AddedAt.Equal(addedAt) {
+			t.Errorf("Entries()[0]=%+v; want butter:butter@example.com of 8 digits", es[0])
+		}
+		if es[1].Name != "deploy" || es[1].Key.Host != otpauth.HostHOTP {
+			t.Errorf("Entries()[1]=%+v; want deploy of hotp", es[1])
+		}
+	}
+}
+
+func TestCreate_Exists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vault.json")
+	if _, err := vault.Create(path, passphrase, fastOption(t, vault.KDFScrypt)); err != nil {
+		t.Fatalf("Create(%s, _, _)=_, %#v; want nil", path, err)
+	}
+
+	_, err := vault.Create(path, passphrase, fastOption(t, vault.KDFScrypt))
+	if err != vault.ErrVaultExists {
+		t.Errorf("Create(%s, _, _)=_, %#v; want %v", path, err, vault.ErrVaultExists)
+	}
+}
+
+func TestCreate_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vault.json")
+	if _, err := vault.Create(path, passphrase, nil); err != vault.ErrVaultOptionIsNil {
+		t.Errorf("Create(%s, _, nil)=_, %#v; want %v", path, err, vault.ErrVaultOptionIsNil)
+	}
+	if _, err := vault.Create(path, nil, fastOption(t, vault.KDFScrypt)); err == nil {
+		t.Errorf("Create(%s, nil, _)=_, nil; want error", path)
+	}
+}
+
+func TestOpen_InvalidPassphrase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vault.json")
+	_, _ = vault.Create(path, passphrase, fastOption(t, vault.KDFArgon2id))
+
+	_, err := vault.Open(path, []byte("wrong"))
+	if err != vault.ErrInvalidPassphrase {
+		t.Errorf("Open(%s, wrong)=_, %#v; want %v", path, err, vault.ErrInvalidPassphrase)
+	}
+}
+
+func TestOpen_Tampered(t *testing.T) {
+	tests := []struct {
+		name    string
+		tamper  func(f map[string]interface{})
+		wantErr error
+	}{
+		{
+			name:    "version",
+			tamper:  func(f map[string]interface{}) { f["version"] = 2 },
+			wantErr: vault.ErrUnsupportedVersion,
+		},
+		{
+			name:    "kdf",
+			tamper:  func(f map[string]interface{}) { f["kdf"].(map[string]interface{})["name"] = "pbkdf2" },
+			wantErr: vault.ErrInvalidVault,
+		},
+		{
+			name:    "params",
+			tamper:  func(f map[string]interface{}) { f["kdf"].(map[string]interface{})["p"] = 2 },
+			wantErr: vault.ErrInvalidPassphrase,
+		},
+		{
+			name:    "nonce",
+			tamper:  func(f map[string]interface{}) { f["nonce"] = "AAAA" },
+			wantErr: vault.ErrInvalidVault,
+		},
+	}
+
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), "vault.json")
+		_, _ = vault.Create(path, passphrase, fastOption(t, vault.KDFScrypt))
+
+		b, _ := ioutil.ReadFile(path)
+		var f map[string]interface{}
+		_ = json.Unmarshal(b, &f)
+		tt.tamper(f)
+		b, _ = json.Marshal(f)
+		_ = ioutil.WriteFile(path, b, 0600)
+
+		_, err := vault.Open(path, passphrase)
+		if err != tt.wantErr {
+			t.Errorf("%s: Open(%s, _)=_, %#v; want %v", tt.name, path, err, tt.wantErr)
+		}
+	}
+}
+
+func TestVault_Add_Exists(t *testing.T) {
+	v, _ := vault.Create(filepath.Join(t.TempDir(), "vault.json"), passphrase, fastOption(t, vault.KDFScrypt))
+	_, _ = v.Add("", mustParseURL(t, totpURI), time.Now())
+
+	_, err := v.Add("butter:butter@example.com", mustParseURL(t, hotpURI), time.Now())
+	if err != vault.ErrEntryExists {
+		t.Errorf("Add(butter:butter@example.com, _, _)=_, %#v; want %v", err, vault.ErrEntryExists)
+	}
+	if _, err := v.Add("", nil, time.Now()); err != otpauth.ErrKeyIsNil {
+		t.Errorf("Add(_, nil, _)=_, %#v; want %v", err, otpauth.ErrKeyIsNil)
+	}
+}
+
+func TestVault_Remove(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vault.json")
+	v, _ := vault.Create(path, passphrase, fastOption(t, vault.KDFScrypt))
+	_, _ = v.Add("deploy", mustParseURL(t, hotpURI), time.Now())
+
+	if err := v.Remove("deploy"); err != nil {
+		t.Fatalf("Remove(deploy)=%#v; want nil", err)
+	}
+	if err := v.Remove("deploy"); err != vault.ErrEntryNotFound {
+		t.Errorf("Remove(deploy)=%#v; want %v", err, vault.ErrEntryNotFound)
+	}
+	if _, err := v.Entry("deploy"); err != vault.ErrEntryNotFound {
+		t.Errorf("Entry(deploy)=_, %#v; want %v", err, vault.ErrEntryNotFound)
+	}
+}
+
+func TestVault_Code(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vault.json")
+	v, _ := vault.Create(path, passphrase, fastOption(t, vault.KDFScrypt))
+	_, _ = v.Add("totp", mustParseURL(t, totpURI), time.Now())
+	_, _ = v.Add("hotp", mustParseURL(t, hotpURI), time.Now())
+
+	// See: https://tools.ietf.org/html/rfc6238#appendix-B
+	got, err := v.Code("totp", time.Unix(1111111109, 0))
+	if err != nil || got != "07081804" {
+		t.Errorf("Code(totp, _)=%s, %#v; want 07081804, nil", got, err)
+	}
+
+	// See: https://tools.ietf.org/html/rfc4226#page-32
+	for _, want := range []string{"755224", "287082"} {
+		got, err := v.Code("hotp", time.Now())
+		if err != nil || got != want {
+			t.Errorf("Code(hotp, _)=%s, %#v; want %s, nil", got, err, want)
+		}
+	}
+	_ = v.Save()
+
+	v, _ = vault.Open(path, passphrase)
+	e, _ := v.Entry("hotp")
+	if e.Key.Counter != 2 {
+		t.Errorf("Entry(hotp) has counter %d; want 2", e.Key.Counter)
+	}
+	if got, _ := v.Code("hotp", time.Now()); got != "359152" {
+		t.Errorf("Code(hotp, _)=%s; want 359152", got)
+	}
+
+	if _, err := v.Code("unknown", time.Now()); err != vault.ErrEntryNotFound {
+		t.Errorf("Code(unknown, _)=_, %#v; want %v", err, vault.ErrEntryNotFound)
+	}
+}
+
+func TestVault_IsNil(t *testing.T) {
+	var v *vault.Vault
+	if err := v.Save(); err != vault.ErrVaultIsNil {
+		t.Errorf("Save()=%#v; want %v", err, vault.ErrVaultIsNil)
+	}
+	if err := v.Remove("deploy"); err != vault.ErrVaultIsNil {
+		t.Errorf("Remove(deploy)=%#v; want %v", err, vault.ErrVaultIsNil)
+	}
+	if _, err := v.Code("deploy", time.Now()); err != vault.ErrVaultIsNil {
+		t.Errorf("Code(deploy, _)=_, %#v; want %v", err, vault.ErrVaultIsNil)
+	}
+}